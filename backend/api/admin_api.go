@@ -0,0 +1,295 @@
+package api
+
+import (
+	"backend/auth"
+	"backend/data"
+	"backend/database"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminListUsers lists user accounts, optionally filtered by role or ban status
+func AdminListUsers(c *gin.Context) {
+	query := database.DB.Model(&data.User{}).Order("id")
+
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+	switch c.Query("banned") {
+	case "true":
+		query = query.Where("banned = ?", true)
+	case "false":
+		query = query.Where("banned = ?", false)
+	}
+
+	var users []data.User
+	if err := query.Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+		return
+	}
+
+	result := make([]gin.H, 0, len(users))
+	for _, user := range users {
+		result = append(result, gin.H{
+			"id":         user.ID,
+			"name":       user.Name,
+			"email":      user.Email,
+			"role":       user.Role,
+			"banned":     user.Banned,
+			"banned_at":  user.BannedAt,
+			"ban_reason": user.BanReason,
+		})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// AdminBanUser bans a user and revokes all of their sessions
+func AdminBanUser(c *gin.Context) {
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	// The reason is optional, so an empty body is fine
+	_ = c.ShouldBindJSON(&input)
+
+	var user data.User
+	if err := database.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Moderators cannot ban staff; only admins can
+	principal, _ := CurrentPrincipal(c)
+	if (user.Role == data.RoleAdmin || user.Role == data.RoleModerator) && !principal.IsAdmin() {
+		forbid(c)
+		return
+	}
+	if principal.Kind == auth.KindUser && principal.ID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot ban yourself"})
+		return
+	}
+
+	now := time.Now()
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"banned":     true,
+			"banned_at":  now,
+			"ban_reason": input.Reason,
+			"logged_in":  false,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&data.Session{}).
+			Where("kind = ? AND subject_id = ? AND revoked_at IS NULL", auth.KindUser, user.ID).
+			Update("revoked_at", now).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to ban user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User banned successfully", "user_id": user.ID})
+}
+
+// AdminUnbanUser lifts a ban
+func AdminUnbanUser(c *gin.Context) {
+	var user data.User
+	if err := database.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// As with bans, only admins can lift a ban on staff
+	principal, _ := CurrentPrincipal(c)
+	if (user.Role == data.RoleAdmin || user.Role == data.RoleModerator) && !principal.IsAdmin() {
+		forbid(c)
+		return
+	}
+
+	if err := database.DB.Model(&user).Updates(map[string]interface{}{
+		"banned":     false,
+		"banned_at":  nil,
+		"ban_reason": "",
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unban user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unbanned successfully", "user_id": user.ID})
+}
+
+// AdminSetUserRole changes a user's role
+func AdminSetUserRole(c *gin.Context) {
+	var input struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || !data.ValidRole(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be one of user, organizer, moderator, admin"})
+		return
+	}
+
+	var user data.User
+	if err := database.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Someone must always be able to reach the admin API
+	if user.Role == data.RoleAdmin && input.Role != data.RoleAdmin {
+		var admins int64
+		if err := database.DB.Model(&data.User{}).Where("role = ? AND banned = ?", data.RoleAdmin, false).Count(&admins).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+			return
+		}
+		if admins <= 1 && !user.Banned {
+			c.JSON(http.StatusConflict, gin.H{"error": "The last admin cannot be demoted"})
+			return
+		}
+	}
+
+	if err := database.DB.Model(&user).Update("role", input.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role updated successfully", "user_id": user.ID, "role": input.Role})
+}
+
+// AdminSetEventActive returns a handler that publishes or unpublishes an event
+func AdminSetEventActive(active bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var event data.Event
+		if err := database.DB.First(&event, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+
+		// A merged duplicate would be listed next to the event it was merged into
+		if active && event.DuplicateOfID != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Event was merged into event %d and cannot be published", *event.DuplicateOfID)})
+			return
+		}
+
+		if err := database.DB.Model(&event).Update("active", active).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
+			return
+		}
+
+		message := "Event unpublished successfully"
+		if active {
+			message = "Event published successfully"
+		}
+		c.JSON(http.StatusOK, gin.H{"message": message, "event_id": event.ID, "active": active})
+	}
+}
+
+// AdminMergeOrganizers moves every event from the source organizer to the target and deletes the source
+func AdminMergeOrganizers(c *gin.Context) {
+	var input struct {
+		SourceID uint `json:"source_id" binding:"required"`
+		TargetID uint `json:"target_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if input.SourceID == input.TargetID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Source and target must be different organizers"})
+		return
+	}
+
+	var source, target data.Organizer
+	if err := database.DB.First(&source, input.SourceID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Source organizer not found"})
+		return
+	}
+	if err := database.DB.First(&target, input.TargetID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Target organizer not found"})
+		return
+	}
+
+	var moved int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&data.Event{}).Where("organizer_id = ?", source.ID).Update("organizer_id", target.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved = result.RowsAffected
+
+		// Keep the account link and contact details if only the source had them
+		updates := map[string]interface{}{}
+		if target.UserID == nil && source.UserID != nil {
+			updates["user_id"] = *source.UserID
+		}
+		if target.ContactDetails == "" && source.ContactDetails != "" {
+			updates["contact_details"] = source.ContactDetails
+		}
+		if target.Description == "" && source.Description != "" {
+			updates["description"] = source.Description
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&target).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		// Sessions of the merged-away organizer account must not outlive it
+		if err := tx.Model(&data.Session{}).
+			Where("kind = ? AND subject_id = ? AND revoked_at IS NULL", auth.KindOrganizer, source.ID).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&source).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organizer not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge organizers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Organizers merged successfully",
+		"organizer_id": target.ID,
+		"events_moved": moved,
+	})
+}
+
+// AdminLinkOrganizer links an organizer profile to the user account that owns it, or unlinks it when user_id is null.
+// Ownership is only ever granted this way or by the user creating the profile, never by a matching email.
+func AdminLinkOrganizer(c *gin.Context) {
+	var input struct {
+		UserID *uint `json:"user_id"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	var organizer data.Organizer
+	if err := database.DB.First(&organizer, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organizer not found"})
+		return
+	}
+	if input.UserID != nil {
+		if err := database.DB.First(&data.User{}, *input.UserID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+	}
+
+	if err := database.DB.Model(&organizer).Update("user_id", input.UserID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link organizer"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Organizer link updated successfully", "organizer_id": organizer.ID, "user_id": input.UserID})
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"backend/auth"
+	"backend/data"
+	"backend/database"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// principalKey is the gin context key holding the authenticated caller
+const principalKey = "principal"
+
+// Principal is the authenticated caller resolved from an access token
+type Principal struct {
+	Kind      string // auth.KindUser or auth.KindOrganizer
+	ID        uint   // ID of the user or organizer
+	Role      string // The user's role, or data.RoleOrganizer for organizer accounts
+	SessionID uint   // Session the access token belongs to
+}
+
+// SetPrincipal attaches an authenticated caller to the request context
+func SetPrincipal(c *gin.Context, principal *Principal) {
+	c.Set(principalKey, principal)
+}
+
+// CurrentPrincipal returns the caller attached by RequireAuth, if any
+func CurrentPrincipal(c *gin.Context) (*Principal, bool) {
+	value, exists := c.Get(principalKey)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := value.(*Principal)
+	return principal, ok
+}
+
+// issueSession creates a session for the principal and returns the token fields for a login response
+func issueSession(kind string, subjectID uint) (gin.H, error) {
+	refreshToken, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := data.Session{
+		Kind:             kind,
+		SubjectID:        subjectID,
+		RefreshTokenHash: auth.HashRefreshToken(refreshToken),
+		ExpiresAt:        time.Now().Add(auth.RefreshTokenTTL),
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresAt, err := auth.IssueAccessToken(kind, subjectID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"token":         accessToken,
+		"refresh_token": refreshToken,
+		"expires_at":    expiresAt.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// revokeSession marks the caller's session as revoked so its tokens stop working
+func revokeSession(c *gin.Context) error {
+	principal, ok := CurrentPrincipal(c)
+	if !ok {
+		return nil
+	}
+	return database.DB.Model(&data.Session{}).
+		Where("id = ? AND revoked_at IS NULL", principal.SessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// authenticate resolves the bearer token on the request into a principal. On
+// failure it returns the status and message to reject the request with.
+func authenticate(c *gin.Context) (*Principal, int, string) {
+	header := c.GetHeader("Authorization")
+	token, found := strings.CutPrefix(header, "Bearer ")
+	if !found || token == "" {
+		return nil, http.StatusUnauthorized, "Authentication required"
+	}
+
+	claims, err := auth.ParseAccessToken(token)
+	if err != nil {
+		message := "Invalid token"
+		if errors.Is(err, auth.ErrExpiredToken) {
+			message = "Token expired"
+		}
+		return nil, http.StatusUnauthorized, message
+	}
+
+	var session data.Session
+	if err := database.DB.First(&session, claims.SessionID).Error; err != nil {
+		return nil, http.StatusUnauthorized, "Invalid token"
+	}
+	if session.RevokedAt != nil || session.Kind != claims.Kind || session.SubjectID != claims.SubjectID {
+		return nil, http.StatusUnauthorized, "Session has been revoked"
+	}
+
+	principal := &Principal{
+		Kind:      claims.Kind,
+		ID:        claims.SubjectID,
+		SessionID: session.ID,
+	}
+
+	// Resolve the account behind the token so deleted accounts and role changes take effect immediately
+	switch claims.Kind {
+	case auth.KindUser:
+		var user data.User
+		if err := database.DB.First(&user, claims.SubjectID).Error; err != nil {
+			return nil, http.StatusUnauthorized, "Account no longer exists"
+		}
+		if user.Banned {
+			return nil, http.StatusForbidden, "Account is banned"
+		}
+		principal.Role = user.Role
+	case auth.KindOrganizer:
+		if err := database.DB.First(&data.Organizer{}, claims.SubjectID).Error; err != nil {
+			return nil, http.StatusUnauthorized, "Account no longer exists"
+		}
+		principal.Role = data.RoleOrganizer
+	}
+
+	return principal, 0, ""
+}
+
+// RequireAuth rejects requests without a valid, unrevoked bearer token and stores the caller in the context
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, status, message := authenticate(c)
+		if principal == nil {
+			c.AbortWithStatusJSON(status, gin.H{"error": message})
+			return
+		}
+
+		SetPrincipal(c, principal)
+		c.Next()
+	}
+}
+
+// OptionalAuth stores the caller in the context when the request carries a
+// valid token, and otherwise lets it through anonymously
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if principal, _, _ := authenticate(c); principal != nil {
+			SetPrincipal(c, principal)
+		}
+		c.Next()
+	}
+}
+
+// RefreshToken exchanges a refresh token for a new access token, rotating the refresh token
+func RefreshToken(c *gin.Context) {
+	var input struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	var session data.Session
+	err := database.DB.Where("refresh_token_hash = ?", auth.HashRefreshToken(input.RefreshToken)).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	newRefreshToken, err := auth.NewRefreshToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+	session.RefreshTokenHash = auth.HashRefreshToken(newRefreshToken)
+	if err := database.DB.Save(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	accessToken, expiresAt, err := auth.IssueAccessToken(session.Kind, session.SubjectID, session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         accessToken,
+		"refresh_token": newRefreshToken,
+		"expires_at":    expiresAt.UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"backend/auth"
+	"backend/data"
+	"backend/database"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// IsAdmin reports whether the caller may override ownership checks
+func (p *Principal) IsAdmin() bool {
+	return p.Kind == auth.KindUser && p.Role == data.RoleAdmin
+}
+
+// IsModerator reports whether the caller may moderate users and events
+func (p *Principal) IsModerator() bool {
+	return p.IsAdmin() || (p.Kind == auth.KindUser && p.Role == data.RoleModerator)
+}
+
+// CanActAsUser reports whether the caller may act on behalf of the given user
+func (p *Principal) CanActAsUser(userID uint) bool {
+	return p.IsAdmin() || (p.Kind == auth.KindUser && p.ID == userID)
+}
+
+// CanManageOrganizer reports whether the caller owns the organizer record.
+// A user owns only the organizer profiles linked to their account; a matching
+// email is not enough, as account emails are unverified and scraped organizers
+// share placeholder or public addresses. Admins link older profiles.
+func (p *Principal) CanManageOrganizer(organizer data.Organizer) bool {
+	if p.IsAdmin() {
+		return true
+	}
+
+	switch p.Kind {
+	case auth.KindOrganizer:
+		return p.ID == organizer.ID
+	case auth.KindUser:
+		return organizer.UserID != nil && *organizer.UserID == p.ID
+	}
+	return false
+}
+
+// CanManageEvent reports whether the caller owns the event through its organizer
+func (p *Principal) CanManageEvent(event data.Event) bool {
+	if p.IsAdmin() {
+		return true
+	}
+	if event.OrganizerID == 0 {
+		return false
+	}
+
+	var organizer data.Organizer
+	if err := database.DB.First(&organizer, event.OrganizerID).Error; err != nil {
+		return false
+	}
+	return p.CanManageOrganizer(organizer)
+}
+
+// forbid aborts the request with the standard 403 response
+func forbid(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You do not have permission to perform this action"})
+}
+
+// requirePrincipal returns the authenticated caller or aborts with 401
+func requirePrincipal(c *gin.Context) (*Principal, bool) {
+	principal, ok := CurrentPrincipal(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return nil, false
+	}
+	return principal, true
+}
+
+// AuthorizeUserParam only lets the user named by the route parameter (or an admin) through
+func AuthorizeUserParam(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := requirePrincipal(c)
+		if !ok {
+			return
+		}
+
+		var user data.User
+		if err := database.DB.First(&user, c.Param(param)).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		if !principal.CanActAsUser(user.ID) {
+			forbid(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// AuthorizeEventParam only lets the owner of the event named by the route parameter (or an admin) through
+func AuthorizeEventParam(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := requirePrincipal(c)
+		if !ok {
+			return
+		}
+
+		var event data.Event
+		if err := database.DB.First(&event, c.Param(param)).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event"})
+			return
+		}
+
+		if !principal.CanManageEvent(event) {
+			forbid(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// AuthorizeOrganizerParam only lets the owner of the organizer named by the route parameter (or an admin) through
+func AuthorizeOrganizerParam(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := requirePrincipal(c)
+		if !ok {
+			return
+		}
+
+		var organizer data.Organizer
+		if err := database.DB.First(&organizer, c.Param(param)).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Organizer not found"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check organizer"})
+			return
+		}
+
+		if !principal.CanManageOrganizer(organizer) {
+			forbid(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// AuthorizeCommentParam only lets the author of the comment named by the route parameter (or a moderator) through
+func AuthorizeCommentParam(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := requirePrincipal(c)
+		if !ok {
+			return
+		}
+
+		var comment data.Comment
+		if err := database.DB.First(&comment, c.Param(param)).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve comment"})
+			return
+		}
+
+		isAuthor := comment.UserID != nil && principal.CanActAsUser(*comment.UserID)
+		if !isAuthor && !principal.IsModerator() {
+			forbid(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRole only lets users holding one of the given roles through
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := requirePrincipal(c)
+		if !ok {
+			return
+		}
+
+		if principal.Kind == auth.KindUser {
+			for _, role := range roles {
+				if principal.Role == role {
+					c.Next()
+					return
+				}
+			}
+		}
+		forbid(c)
+	}
+}
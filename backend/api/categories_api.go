@@ -0,0 +1,81 @@
+package api
+
+import (
+	"backend/data"
+	"backend/database"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetCategories lists the category taxonomy as a tree. Each category counts the
+// published events in it and in its subcategories.
+func GetCategories(c *gin.Context) {
+	var categories []data.Category
+	if err := database.DB.Order("name").Find(&categories).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories"})
+		return
+	}
+
+	var counts []struct {
+		CategoryID uint
+		Count      int64
+	}
+	if err := database.DB.Model(&data.Event{}).
+		Select("category_id, COUNT(*) AS count").
+		Where("active = ? AND category_id IS NOT NULL", true).
+		Group("category_id").
+		Scan(&counts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories"})
+		return
+	}
+	direct := make(map[uint]int64, len(counts))
+	for _, row := range counts {
+		direct[row.CategoryID] = row.Count
+	}
+
+	children := make(map[uint][]data.Category)
+	var roots []data.Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	var build func(nodes []data.Category) []data.CategoryDTO
+	build = func(nodes []data.Category) []data.CategoryDTO {
+		dtos := make([]data.CategoryDTO, 0, len(nodes))
+		for _, node := range nodes {
+			dto := data.CategoryDTO{Slug: node.Slug, Name: node.Name, Count: direct[node.ID], Children: build(children[node.ID])}
+			for _, child := range dto.Children {
+				dto.Count += child.Count
+			}
+			dtos = append(dtos, dto)
+		}
+		return dtos
+	}
+	c.JSON(http.StatusOK, build(roots))
+}
+
+// GetCategoryEvents lists the published events in a category or its
+// subcategories. The filters, sorting and pagination of GetAllEvents apply.
+func GetCategoryEvents(c *gin.Context) {
+	var category data.Category
+	if err := database.DB.Where("slug = ?", c.Param("slug")).Take(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve category"})
+		return
+	}
+
+	listEvents(c, database.DB.Model(&data.Event{}).
+		Where("events.active = ?", true).
+		Where("(events.category_id = ? OR events.category_id IN (SELECT id FROM categories WHERE parent_id = ?))", category.ID, category.ID),
+		eventListOptions{})
+}
@@ -0,0 +1,341 @@
+package api
+
+import (
+	"backend/data"
+	"backend/database"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultCommentPageSize = 50
+	maxCommentPageSize     = 200
+)
+
+// toCommentDTO converts a comment with its User and Mentions.User preloaded into the response shape
+func toCommentDTO(comment data.Comment) data.CommentDTO {
+	dto := data.CommentDTO{
+		ID:        comment.ID,
+		EventID:   comment.EventID,
+		ParentID:  comment.ParentID,
+		Depth:     comment.Depth,
+		UserID:    comment.UserID,
+		UserName:  "Deleted User",
+		Content:   comment.Content,
+		Deleted:   comment.Deleted,
+		Mentions:  []data.MentionDTO{},
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+		Edited:    !comment.Deleted && comment.UpdatedAt.After(comment.CreatedAt),
+		Likes:     comment.Likes,
+		Replies:   []data.CommentDTO{},
+	}
+	if comment.User != nil {
+		dto.UserName = comment.User.Name
+	} else {
+		dto.UserID = nil
+	}
+	for _, mention := range comment.Mentions {
+		if mention.User != nil {
+			dto.Mentions = append(dto.Mentions, data.MentionDTO{UserID: mention.UserID, Name: mention.User.Name})
+		}
+	}
+	return dto
+}
+
+// buildCommentTree nests the loaded replies under their parents. Replies below
+// maxDepth are left out but still counted in reply_count.
+func buildCommentTree(comment data.Comment, children map[uint][]data.Comment, maxDepth uint) data.CommentDTO {
+	dto := toCommentDTO(comment)
+	for _, child := range children[comment.ID] {
+		reply := buildCommentTree(child, children, maxDepth)
+		dto.ReplyCount += 1 + reply.ReplyCount
+		if child.Depth <= maxDepth {
+			dto.Replies = append(dto.Replies, reply)
+		}
+	}
+	return dto
+}
+
+// loadCommentThreads loads every reply below the given top-level comments and
+// returns them as trees no deeper than maxDepth
+func loadCommentThreads(roots []data.Comment, maxDepth uint) ([]data.CommentDTO, error) {
+	children := make(map[uint][]data.Comment)
+	frontier := make([]uint, 0, len(roots))
+	for _, root := range roots {
+		frontier = append(frontier, root.ID)
+	}
+
+	// Depth is bounded, so this is at most MaxCommentDepth queries
+	for depth := 1; depth <= data.MaxCommentDepth && len(frontier) > 0; depth++ {
+		var replies []data.Comment
+		if err := database.DB.Preload("User").Preload("Mentions.User").
+			Where("parent_id IN ?", frontier).
+			Order("created_at, id").
+			Find(&replies).Error; err != nil {
+			return nil, err
+		}
+		frontier = frontier[:0]
+		for _, reply := range replies {
+			children[*reply.ParentID] = append(children[*reply.ParentID], reply)
+			frontier = append(frontier, reply.ID)
+		}
+	}
+
+	dtos := make([]data.CommentDTO, 0, len(roots))
+	for _, root := range roots {
+		dtos = append(dtos, buildCommentTree(root, children, maxDepth))
+	}
+	return dtos, nil
+}
+
+// deleteComments removes the matching comments together with their mentions and likes
+func deleteComments(db *gorm.DB, query interface{}, args ...interface{}) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		matching := tx.Model(&data.Comment{}).Select("id").Where(query, args...)
+		if err := tx.Where("comment_id IN (?)", matching).Delete(&data.CommentMention{}).Error; err != nil {
+			return err
+		}
+		if err := deleteLikes(tx, data.LikeTargetComment, matching); err != nil {
+			return err
+		}
+		return tx.Where(query, args...).Delete(&data.Comment{}).Error
+	})
+}
+
+// AddCommentToEvent adds a comment to an event, or a reply when parent_id is set
+func AddCommentToEvent(c *gin.Context) {
+	var input struct {
+		UserID   uint   `json:"user_id"`
+		ParentID *uint  `json:"parent_id"`
+		Content  string `json:"content"`
+	}
+	id := c.Param("id")
+
+	// Find the event by ID
+	var event data.Event
+	if err := database.DB.Where("id = ?", id).First(&event).Error; err != nil {
+		log.Printf("Error finding event: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	// Bind the comment from the request body
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("Error binding JSON: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+	if strings.TrimSpace(input.Content) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Comment cannot be empty"})
+		return
+	}
+
+	// Comments are posted as the caller unless an admin posts on someone's behalf
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+	if input.UserID == 0 {
+		input.UserID = principal.ID
+	}
+	if !principal.CanActAsUser(input.UserID) {
+		forbid(c)
+		return
+	}
+
+	var user data.User
+	if err := database.DB.First(&user, input.UserID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
+		return
+	}
+
+	comment := data.Comment{
+		EventID: event.ID,
+		UserID:  &user.ID,
+		Content: input.Content,
+	}
+
+	// Replies must stay on the same event and within the nesting limit
+	if input.ParentID != nil {
+		var parent data.Comment
+		if err := database.DB.Where("id = ? AND event_id = ?", *input.ParentID, event.ID).First(&parent).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parent comment not found"})
+			return
+		}
+		if parent.Deleted {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot reply to a deleted comment"})
+			return
+		}
+		if parent.Depth >= data.MaxCommentDepth {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Replies cannot be nested more than %d levels deep", data.MaxCommentDepth)})
+			return
+		}
+		comment.ParentID = &parent.ID
+		comment.Depth = parent.Depth + 1
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("User", "Event", "Mentions").Create(&comment).Error; err != nil {
+			return err
+		}
+		return saveMentions(tx, &comment)
+	})
+	if err != nil {
+		log.Printf("Error saving comment: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add comment"})
+		return
+	}
+	comment.User = &user
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment added successfully", "comment": toCommentDTO(comment)})
+}
+
+// GetAllComments returns a page of an event's top-level comments, oldest first,
+// each with its replies nested underneath
+func GetAllComments(c *gin.Context) {
+	eventID := c.Param("event_id")
+
+	// Fetch event from DB
+	var event data.Event
+	if err := database.DB.First(&event, eventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultCommentPageSize)))
+	if err != nil || limit < 1 || limit > maxCommentPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", strconv.Itoa(data.MaxCommentDepth)))
+	if err != nil || depth < 0 || depth > data.MaxCommentDepth {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid depth"})
+		return
+	}
+
+	// Pages are made of top-level comments so a thread is never split across pages
+	roots := database.DB.Model(&data.Comment{}).Where("event_id = ? AND parent_id IS NULL", event.ID)
+
+	var total int64
+	if err := roots.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve comments"})
+		return
+	}
+
+	var comments []data.Comment
+	if err := database.DB.Preload("User").Preload("Mentions.User").
+		Where("event_id = ? AND parent_id IS NULL", event.ID).
+		Order("created_at, id").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve comments"})
+		return
+	}
+
+	dtos, err := loadCommentThreads(comments, uint(depth))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve comments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comments": dtos,
+		"page":     page,
+		"limit":    limit,
+		"total":    total,
+	})
+}
+
+// EditComment replaces the text of a comment
+func EditComment(c *gin.Context) {
+	var input struct {
+		Content string `json:"content" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || strings.TrimSpace(input.Content) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Comment cannot be empty"})
+		return
+	}
+
+	var comment data.Comment
+	if err := database.DB.Preload("User").Where("deleted = ?", false).First(&comment, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	comment.Content = input.Content
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("User", "Event", "Mentions").Save(&comment).Error; err != nil {
+			return err
+		}
+		return saveMentions(tx, &comment)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment updated successfully", "comment": toCommentDTO(comment)})
+}
+
+// DeleteComment removes a comment. A comment that still has replies is blanked
+// instead so the thread below it stays readable.
+func DeleteComment(c *gin.Context) {
+	var comment data.Comment
+	if err := database.DB.Where("deleted = ?", false).First(&comment, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var replies int64
+		if err := tx.Model(&data.Comment{}).Where("parent_id = ?", comment.ID).Count(&replies).Error; err != nil {
+			return err
+		}
+		if replies > 0 {
+			if err := tx.Where("comment_id = ?", comment.ID).Delete(&data.CommentMention{}).Error; err != nil {
+				return err
+			}
+			return tx.Model(&comment).Updates(map[string]interface{}{"deleted": true, "content": ""}).Error
+		}
+
+		// Remove the comment, then any blanked ancestors it was the last reply of
+		for {
+			if err := deleteComments(tx, "id = ?", comment.ID); err != nil {
+				return err
+			}
+			if comment.ParentID == nil {
+				return nil
+			}
+			var parent data.Comment
+			if err := tx.First(&parent, *comment.ParentID).Error; err != nil {
+				return err
+			}
+			if !parent.Deleted {
+				return nil
+			}
+			if err := tx.Model(&data.Comment{}).Where("parent_id = ?", parent.ID).Count(&replies).Error; err != nil || replies > 0 {
+				return err
+			}
+			comment = parent
+		}
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
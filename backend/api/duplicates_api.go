@@ -0,0 +1,196 @@
+package api
+
+import (
+	"backend/data"
+	"backend/database"
+	"backend/dedup"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultDuplicatePageSize = 50
+	maxDuplicatePageSize     = 200
+)
+
+// errAlreadyMerged is returned when either event of a pair has been merged
+// into another since the pair was queued
+var errAlreadyMerged = errors.New("event already merged")
+
+// duplicateCandidateDTO is a pair of possibly duplicate events, for review
+type duplicateCandidateDTO struct {
+	data.DuplicateCandidate
+	Event     *data.EventDTO `json:"event"`     // Nil once the event is deleted
+	Duplicate *data.EventDTO `json:"duplicate"` // Nil once the event is deleted
+}
+
+// AdminListDuplicates lists the pairs of events queued as possible duplicates,
+// newest first, with both events. ?status= picks pending (the default), merged
+// or distinct pairs, ?before= takes the pairs older than the given one and
+// ?limit= caps the number returned (default 50, at most 200).
+func AdminListDuplicates(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultDuplicatePageSize)))
+	if err != nil || limit < 1 || limit > maxDuplicatePageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	status := c.DefaultQuery("status", data.CandidatePending)
+	if status != data.CandidatePending && status != data.CandidateMerged && status != data.CandidateDistinct {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status must be one of pending, merged, distinct"})
+		return
+	}
+
+	query := database.DB.Where("status = ?", status).Order("id DESC").Limit(limit)
+	if before := c.Query("before"); before != "" {
+		id, err := strconv.ParseUint(before, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before"})
+			return
+		}
+		query = query.Where("id < ?", id)
+	}
+
+	var candidates []data.DuplicateCandidate
+	if err := query.Preload("Event", withEventDetails).Preload("Duplicate", withEventDetails).Find(&candidates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve duplicates"})
+		return
+	}
+
+	result := make([]duplicateCandidateDTO, 0, len(candidates))
+	for _, candidate := range candidates {
+		dto := duplicateCandidateDTO{DuplicateCandidate: candidate}
+		if candidate.Event != nil {
+			event := toEventDTO(*candidate.Event)
+			dto.Event = &event
+		}
+		if candidate.Duplicate != nil {
+			duplicate := toEventDTO(*candidate.Duplicate)
+			dto.Duplicate = &duplicate
+		}
+		result = append(result, dto)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// loadPendingCandidate returns the pair named by the id parameter, or responds
+// with an error if it does not exist or was already reviewed
+func loadPendingCandidate(c *gin.Context) (data.DuplicateCandidate, bool) {
+	var candidates []data.DuplicateCandidate
+	if err := database.DB.Where("id = ?", c.Param("id")).Limit(1).Find(&candidates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve duplicate"})
+		return data.DuplicateCandidate{}, false
+	}
+	if len(candidates) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Duplicate not found"})
+		return data.DuplicateCandidate{}, false
+	}
+	if candidates[0].Status != data.CandidatePending {
+		c.JSON(http.StatusConflict, gin.H{"error": "This pair has already been reviewed"})
+		return data.DuplicateCandidate{}, false
+	}
+	return candidates[0], true
+}
+
+// resolveCandidate records the review of a pair
+func resolveCandidate(tx *gorm.DB, c *gin.Context, candidate *data.DuplicateCandidate, status string) error {
+	now := time.Now()
+	updates := map[string]interface{}{"status": status, "resolved_at": now}
+	if principal, ok := CurrentPrincipal(c); ok {
+		updates["resolved_by"] = principal.ID
+	}
+	return tx.Model(candidate).Updates(updates).Error
+}
+
+// AdminMergeDuplicate merges the later event of a pair into the earlier one,
+// which keeps the richest fields of both. The later event is unpublished and
+// its comments, likes and registrations move to the earlier one.
+func AdminMergeDuplicate(c *gin.Context) {
+	candidate, ok := loadPendingCandidate(c)
+	if !ok {
+		return
+	}
+
+	var event, duplicate data.Event
+	if err := database.DB.First(&event, candidate.EventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+	if err := database.DB.First(&duplicate, candidate.DuplicateID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if event.DuplicateOfID != nil || duplicate.DuplicateOfID != nil {
+			return errAlreadyMerged
+		}
+		if _, err := dedup.Absorb(tx, &event, duplicate); err != nil {
+			return err
+		}
+		if err := tx.Model(&duplicate).Updates(map[string]interface{}{"duplicate_of_id": event.ID, "active": false}).Error; err != nil {
+			return err
+		}
+		// Listings merged into the duplicate now describe the event
+		if err := tx.Model(&data.Event{}).Where("duplicate_of_id = ?", duplicate.ID).Update("duplicate_of_id", event.ID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&data.Comment{}).Where("event_id = ?", duplicate.ID).Update("event_id", event.ID).Error; err != nil {
+			return err
+		}
+		// Users who liked or registered for both keep a single like or registration
+		if err := tx.Exec("UPDATE likes SET target_id = ? WHERE target_type = ? AND target_id = ? AND user_id NOT IN (SELECT user_id FROM likes WHERE target_type = ? AND target_id = ?)",
+			event.ID, data.LikeTargetEvent, duplicate.ID, data.LikeTargetEvent, event.ID).Error; err != nil {
+			return err
+		}
+		if err := deleteLikes(tx, data.LikeTargetEvent, []uint{duplicate.ID}); err != nil {
+			return err
+		}
+		if err := refreshLikeCounts(tx, data.LikeTargetEvent, []uint{event.ID, duplicate.ID}); err != nil {
+			return err
+		}
+		if err := tx.Exec("INSERT OR IGNORE INTO event_users (event_id, user_id) SELECT ?, user_id FROM event_users WHERE event_id = ?", event.ID, duplicate.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM event_users WHERE event_id = ?", duplicate.ID).Error; err != nil {
+			return err
+		}
+
+		return resolveCandidate(tx, c, &candidate, data.CandidateMerged)
+	})
+	if err != nil {
+		if errors.Is(err, errAlreadyMerged) {
+			c.JSON(http.StatusConflict, gin.H{"error": "One of the events has already been merged into another"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Events merged successfully", "event_id": event.ID, "merged_id": duplicate.ID})
+}
+
+// AdminDismissDuplicate records that the events of a pair are different events
+func AdminDismissDuplicate(c *gin.Context) {
+	candidate, ok := loadPendingCandidate(c)
+	if !ok {
+		return
+	}
+
+	if err := resolveCandidate(database.DB, c, &candidate, data.CandidateDistinct); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update duplicate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Events marked as distinct", "candidate_id": candidate.ID})
+}
+
+// deleteDuplicateCandidates removes the pairs involving the given events
+func deleteDuplicateCandidates(tx *gorm.DB, eventIDs interface{}) error {
+	return tx.Where("event_id IN (?) OR duplicate_id IN (?)", eventIDs, eventIDs).Delete(&data.DuplicateCandidate{}).Error
+}
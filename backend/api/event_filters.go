@@ -0,0 +1,196 @@
+package api
+
+import (
+	"backend/data"
+	"backend/eventdate"
+	"backend/scraper"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// parseBound reads a from/to query value, either a Gainesville calendar day
+// (YYYY-MM-DD) or an RFC 3339 timestamp. A bare day used as an upper bound
+// covers that whole day.
+func parseBound(value string, upper bool) (time.Time, error) {
+	if day, err := time.ParseInLocation("2006-01-02", value, eventdate.Location); err == nil {
+		if upper {
+			return day.AddDate(0, 0, 1), nil
+		}
+		return day, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// endsAfter returns a condition matching events still running at t. All-day
+// events without an end run until the following midnight. Times are compared
+// in UTC, as stored.
+func endsAfter(t time.Time) (string, []interface{}) {
+	t = t.UTC()
+	return "((ends_at IS NOT NULL AND ends_at > ?) OR (ends_at IS NULL AND all_day = ? AND starts_at > ?) OR (ends_at IS NULL AND all_day = ? AND starts_at >= ?))",
+		[]interface{}{t, true, t.Add(-24 * time.Hour), false, t}
+}
+
+// filterEvents narrows an event query by the listing's source, cost and date
+// parameters:
+//
+//	source=a,b        events from any of the given sources (see filterSource)
+//	min_cost=N        events with a price of at least N (see filterCost)
+//	max_cost=N        events with a price of at most N
+//	free=true|false   events that can, or cannot, be attended for free
+//	from, to          events overlapping the range (YYYY-MM-DD or RFC 3339)
+//	upcoming=true     events that have not finished yet
+//	past=true         events that have finished
+//	when=today        events happening today in Gainesville
+//	when=this_weekend events happening between Friday 5 PM and Sunday midnight
+//
+// Events whose date could not be parsed are left out once any date parameter
+// is set.
+func filterEvents(c *gin.Context, query *gorm.DB, now time.Time) (*gorm.DB, error) {
+	query, err := filterSource(c, query)
+	if err != nil {
+		return nil, err
+	}
+	query, err = filterCost(c, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var from, to *time.Time
+	narrow := func(start, end time.Time) {
+		if from == nil || start.After(*from) {
+			from = &start
+		}
+		if to == nil || end.Before(*to) {
+			to = &end
+		}
+	}
+
+	if value := c.Query("from"); value != "" {
+		start, err := parseBound(value, false)
+		if err != nil {
+			return nil, errors.New("invalid from date")
+		}
+		from = &start
+	}
+	if value := c.Query("to"); value != "" {
+		end, err := parseBound(value, true)
+		if err != nil {
+			return nil, errors.New("invalid to date")
+		}
+		to = &end
+	}
+
+	switch c.Query("when") {
+	case "":
+	case "today":
+		narrow(eventdate.Today(now))
+	case "this_weekend":
+		narrow(eventdate.ThisWeekend(now))
+	default:
+		return nil, errors.New("invalid when, expected today or this_weekend")
+	}
+
+	upcoming, err := parseFlag(c, "upcoming")
+	if err != nil {
+		return nil, err
+	}
+	past, err := parseFlag(c, "past")
+	if err != nil {
+		return nil, err
+	}
+	if upcoming && past {
+		return nil, errors.New("upcoming and past cannot both be set")
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, errors.New("from must be before to")
+	}
+	if from == nil && to == nil && !upcoming && !past {
+		return query, nil
+	}
+
+	query = query.Where("starts_at IS NOT NULL")
+	if from != nil {
+		condition, args := endsAfter(*from)
+		query = query.Where(condition, args...)
+	}
+	if to != nil {
+		query = query.Where("starts_at < ?", to.UTC())
+	}
+	if upcoming {
+		condition, args := endsAfter(now)
+		query = query.Where(condition, args...)
+	}
+	if past {
+		condition, args := endsAfter(now)
+		query = query.Where("NOT "+condition, args...)
+	}
+	return query, nil
+}
+
+// filterSource keeps the events from the comma-separated sources in ?source=:
+// scraper source names, or "user" for events created through the API
+func filterSource(c *gin.Context, query *gorm.DB) (*gorm.DB, error) {
+	value := c.Query("source")
+	if value == "" {
+		return query, nil
+	}
+
+	var sources []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := scraper.Lookup(name); !ok && name != data.SourceUser {
+			return nil, fmt.Errorf("invalid source %q", name)
+		}
+		sources = append(sources, name)
+	}
+	return query.Where("events.source IN ?", sources), nil
+}
+
+// filterCost keeps the events whose price range, from cost to max_cost,
+// overlaps ?min_cost= to ?max_cost=, and with ?free= those that can or cannot
+// be attended for free. Events of unknown cost are stored as costing 0, so
+// they match max_cost but not min_cost.
+func filterCost(c *gin.Context, query *gorm.DB) (*gorm.DB, error) {
+	if value := c.Query("min_cost"); value != "" {
+		cost, err := strconv.ParseFloat(value, 64)
+		if err != nil || cost < 0 {
+			return nil, errors.New("invalid min_cost")
+		}
+		query = query.Where("MAX(events.cost, events.max_cost) >= ?", cost)
+	}
+	if value := c.Query("max_cost"); value != "" {
+		cost, err := strconv.ParseFloat(value, 64)
+		if err != nil || cost < 0 {
+			return nil, errors.New("invalid max_cost")
+		}
+		query = query.Where("events.cost <= ?", cost)
+	}
+	if c.Query("free") != "" {
+		free, err := parseFlag(c, "free")
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("events.free = ?", free)
+	}
+	return query, nil
+}
+
+// parseFlag reads an optional boolean query parameter
+func parseFlag(c *gin.Context, name string) (bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		return false, nil
+	}
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.New("invalid " + name + ", expected true or false")
+	}
+	return flag, nil
+}
@@ -0,0 +1,286 @@
+package api
+
+import (
+	"backend/data"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultEventPageSize = 100
+	maxEventPageSize     = 500
+
+	// sqliteTimeLayout is how the SQLite driver stores times, so stored
+	// timestamps can be compared with cursor values as text
+	sqliteTimeLayout = "2006-01-02 15:04:05.999999999-07:00"
+)
+
+// eventSortKey describes one way event listings can be ordered
+type eventSortKey struct {
+	column string                       // SQL expression sorted on, ties broken by ID
+	value  func(data.Event) interface{} // The same value read from a loaded event, for cursors
+}
+
+// eventSortKeys are the values accepted by ?sort=, ascending unless prefixed with "-"
+var eventSortKeys = map[string]eventSortKey{
+	"date": {
+		// Undated events sort as if they were in the far future
+		column: "COALESCE(events.starts_at, '9999')",
+		value: func(e data.Event) interface{} {
+			if e.StartsAt == nil {
+				return "9999"
+			}
+			return e.StartsAt.UTC().Format(sqliteTimeLayout)
+		},
+	},
+	"created": {
+		column: "events.created_at",
+		value:  func(e data.Event) interface{} { return e.CreatedAt.UTC().Format(sqliteTimeLayout) },
+	},
+	"likes": {
+		column: "events.likes",
+		value:  func(e data.Event) interface{} { return e.Likes },
+	},
+	"rating": {
+		column: "events.rating",
+		value:  func(e data.Event) interface{} { return e.Rating },
+	},
+	"cost": {
+		column: "events.cost",
+		value:  func(e data.Event) interface{} { return e.Cost },
+	},
+}
+
+// eventListOptions adapts listEvents to a particular listing
+type eventListOptions struct {
+	sortKeys    map[string]eventSortKey // Extra sort keys on top of eventSortKeys
+	defaultSort string                  // Used when ?sort= is absent; "date" if empty
+
+	// decorate runs on each page before the next cursor is built, to fill in
+	// data a sort key or the response needs
+	decorate func(events []data.Event, dtos []data.EventDTO) error
+}
+
+// sortKey looks up a sort key among the listing's own keys and the shared ones
+func (o eventListOptions) sortKey(name string) (eventSortKey, bool) {
+	if key, ok := o.sortKeys[name]; ok {
+		return key, true
+	}
+	key, ok := eventSortKeys[name]
+	return key, ok
+}
+
+// eventCursor marks where the previous page ended
+type eventCursor struct {
+	Sort  string      `json:"s"`
+	Value interface{} `json:"v"`
+	ID    uint        `json:"id"`
+}
+
+func encodeEventCursor(cursor eventCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeEventCursor(value string) (eventCursor, error) {
+	var cursor eventCursor
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(raw, &cursor)
+	}
+	return cursor, err
+}
+
+// eventFieldNames lists the JSON keys of EventDTO that ?fields= may select
+var eventFieldNames = func() map[string]bool {
+	dto := reflect.TypeOf(data.EventDTO{})
+	names := make(map[string]bool, dto.NumField())
+	for i := 0; i < dto.NumField(); i++ {
+		name, _, _ := strings.Cut(dto.Field(i).Tag.Get("json"), ",")
+		names[name] = true
+	}
+	return names
+}()
+
+// selectFields trims events down to the requested JSON keys, always keeping the ID
+func selectFields(events []data.EventDTO, fields []string) ([]map[string]json.RawMessage, error) {
+	result := make([]map[string]json.RawMessage, 0, len(events))
+	for _, event := range events {
+		raw, err := json.Marshal(event)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &all); err != nil {
+			return nil, err
+		}
+		trimmed := map[string]json.RawMessage{"id": all["id"]}
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				trimmed[field] = value
+			}
+		}
+		result = append(result, trimmed)
+	}
+	return result, nil
+}
+
+// listEvents responds with one page of the events matched by query. It applies
+// the source, cost and date filters of filterEvents, then:
+//
+//	sort=date|created|likes|rating|cost  order, "-" prefix for descending (default date,
+//	                                     unless the listing adds its own keys)
+//	limit=N                              page size (default 100, at most 500)
+//	cursor=...                           continue after a previous page
+//	fields=name,starts_at,...            only return these keys (plus id)
+//
+// The body stays a plain array. X-Total-Count carries the number of matching
+// events across all pages and X-Next-Cursor the cursor for the next page, if any.
+func listEvents(c *gin.Context, query *gorm.DB, options eventListOptions) {
+	query, err := filterEvents(c, query, time.Now())
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	if options.defaultSort == "" {
+		options.defaultSort = "date"
+	}
+	sortID := c.DefaultQuery("sort", options.defaultSort)
+	descending := strings.HasPrefix(sortID, "-")
+	sortKey, ok := options.sortKey(strings.TrimPrefix(sortID, "-"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort, expected date, created, likes, rating or cost"})
+		return
+	}
+
+	limit, fields, err := parseEventPage(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	// Let the filtered query be reused for both the count and the page
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Model(&data.Event{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+		return
+	}
+
+	direction, comparison := "ASC", ">"
+	if descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := decodeEventCursor(value)
+		if err != nil || cursor.Sort != sortID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		query = query.Where(
+			"("+sortKey.column+" "+comparison+" ?) OR ("+sortKey.column+" = ? AND events.id "+comparison+" ?)",
+			cursor.Value, cursor.Value, cursor.ID,
+		)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	var events []data.Event
+	if err := withEventDetails(query).
+		Order(sortKey.column + " " + direction).
+		Order("events.id " + direction).
+		Limit(limit + 1).
+		Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+		return
+	}
+
+	hasMore := len(events) > limit
+	if hasMore {
+		events = events[:limit]
+	}
+
+	eventDTOs := make([]data.EventDTO, 0, len(events))
+	for _, event := range events {
+		eventDTOs = append(eventDTOs, toEventDTO(event))
+	}
+	if options.decorate != nil {
+		if err := options.decorate(events, eventDTOs); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+			return
+		}
+	}
+
+	if hasMore {
+		last := events[len(events)-1]
+		c.Header("X-Next-Cursor", encodeEventCursor(eventCursor{Sort: sortID, Value: sortKey.value(last), ID: last.ID}))
+	}
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+
+	respondWithEvents(c, eventDTOs, fields)
+}
+
+// withEventDetails preloads what toEventDTO includes besides the event's own columns
+func withEventDetails(query *gorm.DB) *gorm.DB {
+	return query.Preload("Organizer").Preload("TagList").Preload("CategoryRef")
+}
+
+// badRequest responds with a 400 carrying err's message, capitalized as the
+// API's error messages are
+func badRequest(c *gin.Context, err error) {
+	message := err.Error()
+	if message != "" {
+		message = strings.ToUpper(message[:1]) + message[1:]
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": message})
+}
+
+// parseEventPage reads the page size and field selection shared by event listings
+func parseEventPage(c *gin.Context) (int, []string, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultEventPageSize)))
+	if err != nil || limit < 1 || limit > maxEventPageSize {
+		return 0, nil, errors.New("invalid limit")
+	}
+
+	var fields []string
+	if value := c.Query("fields"); value != "" {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if !eventFieldNames[field] {
+				return 0, nil, errors.New("unknown field: " + field)
+			}
+			fields = append(fields, field)
+		}
+	}
+	return limit, fields, nil
+}
+
+// respondWithEvents marks the caller's likes and writes one page of events,
+// trimmed to the selected fields if any
+func respondWithEvents(c *gin.Context, eventDTOs []data.EventDTO, fields []string) {
+	if err := markLikedEvents(c, eventDTOs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+		return
+	}
+	if fields == nil {
+		c.JSON(http.StatusOK, eventDTOs)
+		return
+	}
+	trimmed, err := selectFields(eventDTOs, fields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+		return
+	}
+	c.JSON(http.StatusOK, trimmed)
+}
@@ -1,23 +1,27 @@
 package api
 
 import (
+	"backend/auth"
 	"backend/data"
 	"backend/database"
+	"backend/eventcost"
+	"backend/eventdate"
+	"backend/eventtags"
 	"backend/scraper"
+	"backend/taxonomy"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"sort"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-
-	"gorm.io/gorm"
 	"github.com/jinzhu/copier"
-
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AddEvent handles adding a new event
@@ -28,47 +32,105 @@ func CreateEvent(c *gin.Context) {
 		return
 	}
 
-	// Treat event.OrganizerID as UserID
-	var user data.User
-	if err := database.DB.Where("id = ?", event.OrganizerID).First(&user).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
+	principal, ok := requirePrincipal(c)
+	if !ok {
 		return
 	}
 
-	// Check if an organizer with this user's email already exists
-	var existingOrganizer data.Organizer
-	if err := database.DB.Where("email = ?", user.Email).First(&existingOrganizer).Error; err == nil {
-		// Organizer with same email found, use it
-		event.OrganizerID = existingOrganizer.ID
+	if principal.Kind == auth.KindOrganizer {
+		// Organizer accounts always publish under their own profile
+		event.OrganizerID = principal.ID
 	} else {
-		// Create new organizer using user's details
-		newOrganizer := data.Organizer{
-			Name:           user.Name,
-			Email:          user.Email,
-			Password:       user.Password,
-			Description:    fmt.Sprintf("Organizer profile for user ID %d", user.ID),
-			ContactDetails: event.ContactDetails,
+		// Treat event.OrganizerID as UserID, defaulting to the caller
+		if event.OrganizerID == 0 {
+			event.OrganizerID = principal.ID
+		}
+		if !principal.CanActAsUser(event.OrganizerID) {
+			forbid(c)
+			return
 		}
 
-		if err := database.DB.Create(&newOrganizer).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organizer"})
+		var user data.User
+		if err := database.DB.Where("id = ?", event.OrganizerID).First(&user).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
 			return
 		}
 
-		event.OrganizerID = newOrganizer.ID
+		// Check if an organizer linked to this user already exists. One that merely
+		// shares the user's email is not theirs; an admin can link it.
+		var existingOrganizer data.Organizer
+		if err := database.DB.Where("user_id = ?", user.ID).First(&existingOrganizer).Error; err == nil {
+			event.OrganizerID = existingOrganizer.ID
+		} else {
+			// Create new organizer using user's details. It has no password of its
+			// own: the user manages it through their account, linked by UserID.
+			newOrganizer := data.Organizer{
+				Name:           user.Name,
+				Email:          user.Email,
+				Description:    fmt.Sprintf("Organizer profile for user ID %d", user.ID),
+				ContactDetails: event.ContactDetails,
+				UserID:         &user.ID,
+			}
+			// Organizer emails are unique, so leave it out if another profile has it
+			var taken int64
+			if err := database.DB.Model(&data.Organizer{}).Where("email = ?", user.Email).Count(&taken).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organizer"})
+				return
+			}
+			if taken > 0 {
+				newOrganizer.Email = ""
+			}
+
+			if err := database.DB.Create(&newOrganizer).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organizer"})
+				return
+			}
+
+			event.OrganizerID = newOrganizer.ID
+		}
+
+		// Publishing an event makes a plain user an organizer
+		if user.Role == data.RoleUser {
+			if err := database.DB.Model(&user).Update("role", data.RoleOrganizer).Error; err != nil {
+				log.Printf("Error promoting user %d to organizer: %v", user.ID, err)
+			}
+		}
 	}
 
-	// Format the date
-	parsedDate, err := time.Parse("2006-01-02", event.Date)
-	if err != nil {
+	if err := scheduleEnteredDate(&event); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
 		return
 	}
-	event.Date = fmt.Sprintf("%s, %s", parsedDate.Format("January 2"), event.Time)
 	event.Active = true
+	event.Cancelled, event.CancelledAt = false, nil
+	// Likes, ratings and bookkeeping are kept by the server, whatever the request claimed
+	event.ID, event.Likes, event.Rating, event.DuplicateOfID = 0, 0, 0, nil
+	event.CreatedAt = time.Time{}
+
+	// Record where the event came from, whatever the request claimed
+	now := time.Now()
+	event.Source, event.ExternalID, event.SourceURL = data.SourceUser, "", ""
+	// Only scraped descriptions have a sanitized HTML form
+	event.DescriptionHTML = ""
+	// Whether the event is free, and in what currency, follows from its cost
+	if strings.TrimSpace(event.CostText) != "" {
+		if err := eventcost.Price(&event); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cost_text"})
+			return
+		}
+	} else if err := eventcost.PriceAmounts(&event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cost"})
+		return
+	}
+	event.FirstSeenAt, event.LastSeenAt = &now, &now
 
-	// Save the event
-	if err := database.DB.Create(&event).Error; err != nil {
+	if err := taxonomy.Apply(database.DB, data.SourceUser, &event); err != nil {
+		log.Printf("Error categorizing event %q: %v", event.Name, err)
+	}
+
+	// Save the event alone: users, the organizer and other linked records in
+	// the body are not the client's to create
+	if err := database.DB.Omit(clause.Associations).Create(&event).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event"})
 		return
 	}
@@ -78,49 +140,53 @@ func CreateEvent(c *gin.Context) {
 		log.Printf("Error populating latitude/longitude: %v", err)
 	}
 
+	if err := eventtags.Apply(database.DB, &event); err != nil {
+		log.Printf("Error saving tags of event %d: %v", event.ID, err)
+	}
+
 	// Fetch event with Organizer details for response
 	var createdEvent data.Event
-	if err := database.DB.Preload("Organizer").First(&createdEvent, event.ID).Error; err != nil {
+	if err := withEventDetails(database.DB).First(&createdEvent, event.ID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event with organizer"})
 		return
 	}
 
 	BroadcastEventNotification(createdEvent.Name, createdEvent.ID)
 
-	// Return event with organizer details
-	c.JSON(http.StatusCreated, createdEvent)
+	// Return the event as GetEventByID would, with times in Gainesville's timezone
+	c.JSON(http.StatusCreated, toEventDTO(createdEvent))
 }
 
-// GetAllEvents retrieves all events
-func GetAllEvents(c *gin.Context) {
-
-	var events []data.Event
-	if err := database.DB.Find(&events).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
-		return
-	}
-
-	var eventDTOs []data.EventDTO
-
-	for _, event := range events {
-		var organizer data.Organizer
-		var organizerDTO data.OrganizerDTO
-
-		// Try to find the organizer, but don't fail if not found
-		if err := database.DB.First(&organizer, event.OrganizerID).Error; err == nil {
-			copier.Copy(&organizerDTO, &organizer)
+// toEventDTO copies an event, loaded withEventDetails, into its response
+// shape with times in Gainesville's timezone
+func toEventDTO(event data.Event) data.EventDTO {
+	var dto data.EventDTO
+	copier.Copy(&dto, &event)
+	dto.Organizer = data.OrganizerDTO{}
+	if event.Organizer.ID != 0 {
+		copier.Copy(&dto.Organizer, &event.Organizer)
+	}
+	dto.TagList = event.TagList
+	if dto.TagList == nil {
+		dto.TagList = []data.Tag{}
+	}
+
+	dto.DisplayDate = event.Date
+	if event.StartsAt != nil {
+		startsAt := event.StartsAt.In(eventdate.Location)
+		dto.StartsAt = &startsAt
+		if event.EndsAt != nil {
+			endsAt := event.EndsAt.In(eventdate.Location)
+			dto.EndsAt = &endsAt
 		}
-
-		var dto data.EventDTO
-		copier.Copy(&dto, &event)
-
-		// Keep the original OrganizerID from the event (even if it's 0)
-		dto.Organizer = organizerDTO
-
-		eventDTOs = append(eventDTOs, dto)
+		dto.DisplayDate = eventdate.Format(startsAt, dto.EndsAt, event.AllDay)
 	}
+	return dto
+}
 
-	c.JSON(http.StatusOK, eventDTOs)
+// GetAllEvents lists published events, filtered, sorted and paginated by listEvents
+func GetAllEvents(c *gin.Context) {
+	listEvents(c, database.DB.Model(&data.Event{}).Where("events.active = ?", true), eventListOptions{})
 }
 
 // GetEventByID retrieves a single event by its ID
@@ -128,7 +194,7 @@ func GetEventByID(c *gin.Context) {
 	id := c.Param("id")
 
 	var event data.Event
-	if err := database.DB.First(&event, id).Error; err != nil {
+	if err := withEventDetails(database.DB).First(&event, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 			return
@@ -137,18 +203,23 @@ func GetEventByID(c *gin.Context) {
 		return
 	}
 
-	var organizer data.Organizer
-	var organizerDTO data.OrganizerDTO
-
-	if err := database.DB.First(&organizer, event.OrganizerID).Error; err == nil {
-		copier.Copy(&organizerDTO, &organizer)
+	// Unpublished and merged events are only shown to those who manage them
+	if !event.Active {
+		if principal, ok := CurrentPrincipal(c); !ok || !principal.CanManageEvent(event) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
 	}
 
-	var dto data.EventDTO
-	copier.Copy(&dto, &event)
-	dto.Organizer = organizerDTO
+	dto := toEventDTO(event)
 
-	c.JSON(http.StatusOK, dto)
+	dtos := []data.EventDTO{dto}
+	if err := markLikedEvents(c, dtos); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos[0])
 }
 
 // UpdateEvent handles updating an existing event
@@ -168,10 +239,26 @@ func EditEvent(c *gin.Context) {
 
 	// Update the fields of the event
 	event.Name = updatedEvent.Name
+	if updatedEvent.Description != event.Description {
+		// The source's HTML no longer matches the description
+		event.DescriptionHTML = ""
+	}
 	event.Description = updatedEvent.Description
-	event.Date = updatedEvent.Date
 	event.Location = updatedEvent.Location
 
+	// A new date is entered and stored as on create, keeping the structured
+	// times in step; the stored date may be sent back unchanged
+	switch {
+	case strings.TrimSpace(updatedEvent.Date) == "":
+		event.Date, event.StartsAt, event.EndsAt, event.AllDay = "", nil, nil, false
+	case updatedEvent.Date != event.Date:
+		event.Date, event.Time = updatedEvent.Date, updatedEvent.Time
+		if err := scheduleEnteredDate(&event); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid date format"})
+			return
+		}
+	}
+
 	if err := database.DB.Save(&event).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update event"})
 		return
@@ -180,6 +267,24 @@ func EditEvent(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Event updated successfully"})
 }
 
+// scheduleEnteredDate parses the Date, given as 2006-01-02, and Time of an
+// event entered through the API into its structured times, then stores Date in
+// the readable form listings show, such as "June 15, 2025, 10:00 AM"
+func scheduleEnteredDate(event *data.Event) error {
+	parsedDate, err := time.ParseInLocation("2006-01-02", event.Date, eventdate.Location)
+	if err != nil {
+		return err
+	}
+	if err := eventdate.Schedule(event, time.Now()); err != nil {
+		return err
+	}
+	event.Date = parsedDate.Format("January 2, 2006")
+	if strings.TrimSpace(event.Time) != "" {
+		event.Date = fmt.Sprintf("%s, %s", event.Date, event.Time)
+	}
+	return nil
+}
+
 type DeleteEventRequest struct {
 	ID uint `uri:"id" binding:"required"`
 }
@@ -193,203 +298,29 @@ func DeleteEvent(c *gin.Context) {
 		return
 	}
 
-	// Delete event by ID
-	if err := database.DB.Delete(&data.Event{}, req.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
+	// Delete the event's comments, likes and tags, then the event itself
+	if err := deleteComments(database.DB, "event_id = ?", req.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event comments"})
 		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully"})
-}
-
-// Add comment to event
-func AddCommentToEvent(c *gin.Context) {
-	var newComment data.Comment
-	id := c.Param("id")
-
-	// Find the event by ID
-	var event data.Event
-	if err := database.DB.Where("id = ?", id).First(&event).Error; err != nil {
-		log.Printf("Error finding event: %v", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	if err := deleteLikes(database.DB, data.LikeTargetEvent, []uint{req.ID}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event likes"})
 		return
 	}
-
-	// Bind the comment from the request body
-	if err := c.ShouldBindJSON(&newComment); err != nil {
-		log.Printf("Error binding JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+	if err := eventtags.Detach(database.DB, []uint{req.ID}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event tags"})
 		return
 	}
-
-	// Set the EventID for the new comment
-	newComment.EventID = event.ID
-
-	// Set created_at to current timestamp
-	newComment.CreatedAt = time.Now().Format("January 2, 2006 3:04 PM") // Custom format
-
-	// Initialize comments slice
-	var comments []data.Comment
-
-	// If comments exist, unmarshal the existing JSON
-	if event.Comments != "" {
-		if err := json.Unmarshal([]byte(event.Comments), &comments); err != nil {
-			log.Printf("Error unmarshaling comments: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process comments"})
-			return
-		}
-	}
-
-	// Add the new comment
-	comments = append(comments, newComment)
-
-	// Marshal the updated comments back to JSON
-	commentsJSON, err := json.Marshal(comments)
-	if err != nil {
-		log.Printf("Error marshaling comments: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process comments"})
-		return
-	}
-
-	// Update the event with the new comments JSON
-	event.Comments = string(commentsJSON)
-
-	// Save the updated event back to the database
-	if err := database.DB.Save(&event).Error; err != nil {
-		log.Printf("Error saving event: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add comment"})
+	if err := deleteDuplicateCandidates(database.DB, []uint{req.ID}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event duplicates"})
 		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Comment added successfully"})
-}
-
-func GetAllComments(c *gin.Context) {
-	eventID := c.Param("event_id")
-
-	// Fetch event from DB
-	var event data.Event
-	if err := database.DB.First(&event, eventID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
-		return
-	}
-
-	// Parse comments JSON string into slice of maps
-	var comments []map[string]interface{}
-	if err := json.Unmarshal([]byte(event.Comments), &comments); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse comments"})
-		return
-	}
-
-	// Flag to track if any update is made
-	commentsModified := false
-
-	// Loop through comments and update deleted users
-	for _, comment := range comments {
-		userIDFloat, ok := comment["user_id"].(float64)
-		if !ok {
-			comment["user_id"] = 0
-			comment["user_name"] = "Deleted User"
-			commentsModified = true
-			continue
-		}
-
-		userID := uint(userIDFloat)
-		var user data.User
-		if err := database.DB.First(&user, userID).Error; err != nil {
-			// User not found → mark as deleted
-			comment["user_id"] = 0
-			comment["user_name"] = "Deleted User"
-			commentsModified = true
-		}
-	}
-
-	// If any comment was modified, update the Comments field in the event record
-	if commentsModified {
-		updatedCommentsJSON, err := json.Marshal(comments)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to serialize updated comments"})
-			return
-		}
-
-		event.Comments = string(updatedCommentsJSON)
-		if err := database.DB.Save(&event).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event comments"})
-			return
-		}
-	}
-
-	// Return updated comments
-	c.JSON(http.StatusOK, gin.H{"comments": comments})
-}
-
-func AddLikeToComment(c *gin.Context) {
-	// Get event ID from URL parameter
-	eventID := c.Param("event_id")
-	commentIndex := c.Param("comment_index") // Index of the comment in the array
-
-	// Fetch event from the database
-	var event data.Event
-	if err := database.DB.First(&event, eventID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
-		return
-	}
-
-	// Parse the JSON comments field
-	var comments []data.Comment
-	if err := json.Unmarshal([]byte(event.Comments), &comments); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse comments"})
-		return
-	}
-
-	// Convert commentIndex to int
-	index, err := strconv.Atoi(commentIndex)
-	if err != nil || index < 0 || index >= len(comments) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment index"})
-		return
-	}
-
-	// Increment the like count for the specific comment
-	comments[index].Likes++
-
-	// Marshal the updated comments back to JSON
-	commentsJSON, err := json.Marshal(comments)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process comments"})
-		return
-	}
-
-	event.Comments = string(commentsJSON)
-
-	if err := database.DB.Save(&event).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save event"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Like added successfully"})
-}
-
-func AddLikeToEvent(c *gin.Context) {
-	// Get event ID from URL parameter
-	id := c.Param("id")
-
-	// Find the event by ID
-	var event data.Event
-	if err := database.DB.Where("id = ?", id).First(&event).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
-		return
-	}
-
-	// Increment the like count
-	event.Likes++
-
-	// Save the updated event back to the database
-	if err := database.DB.Save(&event).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add like"})
+	if err := database.DB.Delete(&data.Event{}, req.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Like added successfully", "likes": event.Likes})
+	c.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully"})
 }
 
 func MapUserToEvent(c *gin.Context) {
@@ -404,6 +335,16 @@ func MapUserToEvent(c *gin.Context) {
 		return
 	}
 
+	// Users can only change their own registrations
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+	if !principal.CanActAsUser(input.UserID) {
+		forbid(c)
+		return
+	}
+
 	var user data.User
 	var event data.Event
 
@@ -440,6 +381,16 @@ func UnmapUserFromEvent(c *gin.Context) {
 		return
 	}
 
+	// Users can only change their own registrations
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+	if !principal.CanActAsUser(input.UserID) {
+		forbid(c)
+		return
+	}
+
 	var user data.User
 	var event data.Event
 
@@ -464,7 +415,7 @@ func UnmapUserFromEvent(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "User successfully unmapped from event"})
 }
 
-// GetRegisteredEvents retrieves all events a user is registered for
+// GetRegisteredEvents lists the events a user is registered for, paginated like GetAllEvents
 func GetRegisteredEvents(c *gin.Context) {
 	userID := c.Param("id")
 
@@ -474,30 +425,10 @@ func GetRegisteredEvents(c *gin.Context) {
 		return
 	}
 
-	var events []data.Event
-	if err := database.DB.Model(&user).Association("Events").Find(&events); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
-		return
-	}
-
-	eventDTOs := make([]data.EventDTO, 0)
-
-	for _, event := range events {
-		var organizer data.Organizer
-		var organizerDTO data.OrganizerDTO
-
-		if err := database.DB.First(&organizer, event.OrganizerID).Error; err == nil {
-			copier.Copy(&organizerDTO, &organizer)
-		}
-
-		var dto data.EventDTO
-		copier.Copy(&dto, &event)
-		dto.Organizer = organizerDTO
-
-		eventDTOs = append(eventDTOs, dto)
-	}
-
-	c.JSON(http.StatusOK, eventDTOs)
+	// Unpublished events and merged duplicates are hidden here as in every other listing
+	listEvents(c, database.DB.Model(&data.Event{}).
+		Joins("JOIN event_users ON event_users.event_id = events.id").
+		Where("event_users.user_id = ? AND events.active = ?", user.ID, true), eventListOptions{})
 }
 
 // GetUsersByEvent list using Event ID
@@ -536,78 +467,6 @@ func GetUsersByEvent(c *gin.Context) {
 	c.JSON(http.StatusOK, sanitizedUsers)
 }
 
-func SearchForEventById(c *gin.Context) {
-	// Get the event ID from URL parameter
-	eventID := c.Param("event_id")
-
-	var event data.EventDTO
-
-	// Find the event by ID
-	if err := database.DB.Preload("Organizer").First(&event, eventID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event"})
-		return
-	}
-
-	// Return the found event
-	c.JSON(http.StatusOK, event)
-}
-
-func SearchForEventsByName(c *gin.Context) {
-	// Get the event name from URL parameter
-	eventName := c.Query("name")
-
-	if eventName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Event name query parameter is required"})
-		return
-	}
-
-	var events []data.EventDTO
-
-	// Find events by name (case insensitive)
-	if err := database.DB.Where("LOWER(name) LIKE ?", fmt.Sprintf("%%%s%%", eventName)).Find(&events).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
-		return
-	}
-
-	if len(events) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"message": "No events found with the given name"})
-		return
-	}
-
-	// Return the found events
-	c.JSON(http.StatusOK, events)
-}
-
-func SearchForEventsByCategory(c *gin.Context) {
-	// Get the category from URL parameter
-	category := c.Query("category")
-
-	if category == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Category query parameter is required"})
-		return
-	}
-
-	var events []data.EventDTO
-
-	// Find events by category (case insensitive)
-	if err := database.DB.Where("LOWER(category) = ?", category).Find(&events).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
-		return
-	}
-
-	if len(events) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"message": "No events found for the given category"})
-		return
-	}
-
-	// Return the found events
-	c.JSON(http.StatusOK, events)
-}
-
 ///////////////////////////////////////////////////////////////
 
 func GetWeatherByEventID(c *gin.Context) {
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"backend/auth"
+	"backend/data"
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// likeTables maps each likeable target type to the table caching its count
+var likeTables = map[string]string{
+	data.LikeTargetEvent:   "events",
+	data.LikeTargetComment: "comments",
+}
+
+// refreshLikeCounts recomputes the cached like counts of the given targets from the likes table
+func refreshLikeCounts(tx *gorm.DB, targetType string, targetIDs []uint) error {
+	if len(targetIDs) == 0 {
+		return nil
+	}
+	table := likeTables[targetType]
+	count := gorm.Expr("(SELECT COUNT(*) FROM likes WHERE likes.target_type = ? AND likes.target_id = "+table+".id)", targetType)
+	return tx.Table(table).Where("id IN ?", targetIDs).UpdateColumn("likes", count).Error
+}
+
+// deleteLikes removes the likes on the given targets
+func deleteLikes(tx *gorm.DB, targetType string, targetIDs interface{}) error {
+	return tx.Where("target_type = ? AND target_id IN (?)", targetType, targetIDs).Delete(&data.Like{}).Error
+}
+
+// deleteUserLikes removes every like a user has made and updates the counts they contributed to
+func deleteUserLikes(db *gorm.DB, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var likes []data.Like
+		if err := tx.Where("user_id = ?", userID).Find(&likes).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&data.Like{}).Error; err != nil {
+			return err
+		}
+
+		targets := make(map[string][]uint)
+		for _, like := range likes {
+			targets[like.TargetType] = append(targets[like.TargetType], like.TargetID)
+		}
+		for targetType, ids := range targets {
+			if err := refreshLikeCounts(tx, targetType, ids); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// markLikedEvents sets LikedByMe on the events the authenticated user likes
+func markLikedEvents(c *gin.Context, events []data.EventDTO) error {
+	principal, ok := CurrentPrincipal(c)
+	if !ok || principal.Kind != auth.KindUser || len(events) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+
+	var liked []uint
+	if err := database.DB.Model(&data.Like{}).
+		Where("user_id = ? AND target_type = ? AND target_id IN ?", principal.ID, data.LikeTargetEvent, ids).
+		Pluck("target_id", &liked).Error; err != nil {
+		return err
+	}
+
+	likedSet := make(map[uint]bool, len(liked))
+	for _, id := range liked {
+		likedSet[id] = true
+	}
+	for i := range events {
+		events[i].LikedByMe = likedSet[events[i].ID]
+	}
+	return nil
+}
+
+// setLike records or removes the caller's like on a target. Both directions are
+// idempotent: liking twice or unliking something not liked is not an error.
+func setLike(c *gin.Context, targetType string, liked bool) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+	if principal.Kind != auth.KindUser {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only user accounts can like"})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	// Only published events and live comments can be liked
+	target := database.DB.Table(likeTables[targetType]).Where("id = ?", targetID)
+	if targetType == data.LikeTargetEvent {
+		target = target.Where("active = ?", true)
+	} else {
+		target = target.Where("deleted = ?", false)
+	}
+	var found int64
+	if err := target.Count(&found).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update like"})
+		return
+	}
+	if found == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	var likes uint
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		like := data.Like{UserID: principal.ID, TargetType: targetType, TargetID: uint(targetID)}
+		var err error
+		if liked {
+			err = tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("User").Create(&like).Error
+		} else {
+			err = tx.Where(&like).Delete(&data.Like{}).Error
+		}
+		if err != nil {
+			return err
+		}
+		if err := refreshLikeCounts(tx, targetType, []uint{uint(targetID)}); err != nil {
+			return err
+		}
+		return tx.Table(likeTables[targetType]).Select("likes").Where("id = ?", targetID).Scan(&likes).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update like"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"liked": liked, "likes": likes})
+}
+
+// LikeEvent adds the caller's like to an event
+func LikeEvent(c *gin.Context) {
+	setLike(c, data.LikeTargetEvent, true)
+}
+
+// UnlikeEvent removes the caller's like from an event
+func UnlikeEvent(c *gin.Context) {
+	setLike(c, data.LikeTargetEvent, false)
+}
+
+// LikeComment adds the caller's like to a comment
+func LikeComment(c *gin.Context) {
+	setLike(c, data.LikeTargetComment, true)
+}
+
+// UnlikeComment removes the caller's like from a comment
+func UnlikeComment(c *gin.Context) {
+	setLike(c, data.LikeTargetComment, false)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"backend/data"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// mentionPattern matches @handles that are not part of an email address or another word
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+// parseMentions returns the distinct, lower-cased handles mentioned in a comment
+func parseMentions(content string) []string {
+	seen := make(map[string]bool)
+	var handles []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		handle := strings.ToLower(strings.TrimRight(match[1], "._-"))
+		if handle == "" || seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		handles = append(handles, handle)
+	}
+	return handles
+}
+
+// resolveMentions maps the @handles in a comment to users. A handle matches a
+// user's name with the spaces removed, or the part of their email before the @,
+// ignoring case either way. A handle matching several users, such as @john for
+// john@ at two domains, is left unresolved rather than notifying the wrong one.
+func resolveMentions(db *gorm.DB, content string) ([]data.User, error) {
+	handles := parseMentions(content)
+	if len(handles) == 0 {
+		return nil, nil
+	}
+
+	var candidates []data.User
+	err := db.Where("LOWER(REPLACE(name, ' ', '')) IN ?", handles).
+		Or("INSTR(email, '@') > 1 AND LOWER(SUBSTR(email, 1, INSTR(email, '@') - 1)) IN ?", handles).
+		Order("id").
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make(map[string][]int)
+	for i, user := range candidates {
+		name := strings.ToLower(strings.ReplaceAll(user.Name, " ", ""))
+		matches[name] = append(matches[name], i)
+		if at := strings.Index(user.Email, "@"); at > 0 {
+			if local := strings.ToLower(user.Email[:at]); local != name {
+				matches[local] = append(matches[local], i)
+			}
+		}
+	}
+	mentioned := make([]bool, len(candidates))
+	for _, handle := range handles {
+		if len(matches[handle]) == 1 {
+			mentioned[matches[handle][0]] = true
+		}
+	}
+
+	var users []data.User
+	for i, user := range candidates {
+		if mentioned[i] {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// saveMentions replaces the recorded mentions of a comment with the ones in its current text
+func saveMentions(tx *gorm.DB, comment *data.Comment) error {
+	if err := tx.Where("comment_id = ?", comment.ID).Delete(&data.CommentMention{}).Error; err != nil {
+		return err
+	}
+	comment.Mentions = nil
+
+	users, err := resolveMentions(tx, comment.Content)
+	if err != nil || len(users) == 0 {
+		return err
+	}
+	for i := range users {
+		comment.Mentions = append(comment.Mentions, data.CommentMention{CommentID: comment.ID, UserID: users[i].ID, User: &users[i]})
+	}
+	return tx.Omit("User").Create(&comment.Mentions).Error
+}
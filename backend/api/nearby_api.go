@@ -0,0 +1,257 @@
+package api
+
+import (
+	"backend/data"
+	"backend/database"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	earthRadiusKm = 6371.0
+	kmPerDegree   = earthRadiusKm * math.Pi / 180
+
+	defaultNearbyRadiusKm = 10.0
+	maxNearbyRadiusKm     = 500.0
+)
+
+// haversineKm is the great-circle distance between two points, in kilometres
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// geoBounds is a latitude/longitude box. West may exceed East when the box
+// crosses the antimeridian.
+type geoBounds struct {
+	South, West, North, East float64
+}
+
+// boundsAround returns a box containing every point within radiusKm of (lat, lng)
+func boundsAround(lat, lng, radiusKm float64) geoBounds {
+	dLat := radiusKm / kmPerDegree
+	bounds := geoBounds{South: math.Max(lat-dLat, -90), North: math.Min(lat+dLat, 90), West: -180, East: 180}
+
+	// Near a pole the circle covers every longitude
+	if bounds.South > -90 && bounds.North < 90 {
+		dLng := math.Asin(math.Min(1, math.Sin(radiusKm/earthRadiusKm)/math.Cos(lat*math.Pi/180))) * 180 / math.Pi
+		if dLng < 180 {
+			bounds.West = wrapLongitude(lng - dLng)
+			bounds.East = wrapLongitude(lng + dLng)
+		}
+	}
+	return bounds
+}
+
+func wrapLongitude(lng float64) float64 {
+	if lng < -180 {
+		return lng + 360
+	}
+	if lng > 180 {
+		return lng - 360
+	}
+	return lng
+}
+
+// within restricts query to events inside the box, using idx_events_lat_lng.
+// Events whose coordinates were never resolved sit at (0, 0) and are left out.
+func (b geoBounds) within(query *gorm.DB) *gorm.DB {
+	query = query.Where("events.latitude BETWEEN ? AND ?", b.South, b.North).
+		Where("NOT (events.latitude = 0 AND events.longitude = 0)")
+	if b.West <= b.East {
+		return query.Where("events.longitude BETWEEN ? AND ?", b.West, b.East)
+	}
+	return query.Where("(events.longitude >= ? OR events.longitude <= ?)", b.West, b.East)
+}
+
+// parseCoordinate reads a required latitude or longitude query parameter
+func parseCoordinate(c *gin.Context, name string, limit float64) (float64, error) {
+	value, err := strconv.ParseFloat(c.Query(name), 64)
+	if err != nil || math.IsNaN(value) || value < -limit || value > limit {
+		return 0, errors.New("invalid " + name)
+	}
+	return value, nil
+}
+
+// nearKey is the squared distance of an event from (lat, lng), in degrees of
+// latitude, on an equirectangular projection centred there. SQLite has no
+// trigonometry, so events are ordered, paged and kept within a radius by this
+// key; within the 500 km of the widest search it ranks them as the
+// great-circle distance does but for near ties.
+func nearKey(lat, lng float64) clause.Expr {
+	scale := math.Cos(lat * math.Pi / 180)
+	dLng := "(MIN(ABS(events.longitude - ?), 360 - ABS(events.longitude - ?)) * ?)"
+	return clause.Expr{
+		SQL:  "((events.latitude - ?) * (events.latitude - ?) + " + dLng + " * " + dLng + ")",
+		Vars: []interface{}{lat, lat, lng, lng, scale, lng, lng, scale},
+	}
+}
+
+// listNearEvents responds with the published events inside bounds, nearest to
+// (lat, lng) first and within maxKm of it when maxKm is positive. Events are
+// matched, ordered and paged in SQL by nearKey, so only one page is loaded;
+// the distance_km each event carries is the great-circle distance. The
+// filters, limit, cursor and fields of GetAllEvents apply.
+func listNearEvents(c *gin.Context, bounds geoBounds, lat, lng, maxKm float64) {
+	query, err := filterEvents(c, database.DB.Model(&data.Event{}).Where("events.active = ?", true), time.Now())
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	limit, fields, err := parseEventPage(c)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	key := nearKey(lat, lng)
+	query = bounds.within(query)
+	if maxKm > 0 {
+		radius := maxKm / kmPerDegree
+		query = query.Where("? <= ?", key, radius*radius)
+	}
+	// Let the filtered query be reused for both the count and the page
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+		return
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := decodeEventCursor(value)
+		after, isNumber := cursor.Value.(float64)
+		if err != nil || cursor.Sort != "distance" || !isNumber {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		query = query.Where("(? > ?) OR (? = ? AND events.id > ?)", key, after, key, after, cursor.ID)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	var page []struct {
+		ID      uint
+		NearKey float64
+	}
+	if err := query.Select("events.id, ? AS near_key", key).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "? ASC, events.id ASC", Vars: []interface{}{key}}}).
+		Limit(limit + 1).
+		Scan(&page).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+		return
+	}
+	if len(page) > limit {
+		page = page[:limit]
+		last := page[len(page)-1]
+		c.Header("X-Next-Cursor", encodeEventCursor(eventCursor{Sort: "distance", Value: last.NearKey, ID: last.ID}))
+	}
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+
+	ids := make([]uint, 0, len(page))
+	for _, row := range page {
+		ids = append(ids, row.ID)
+	}
+	var events []data.Event
+	if err := withEventDetails(database.DB).Where("id IN ?", ids).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+		return
+	}
+	byID := make(map[uint]data.Event, len(events))
+	for _, event := range events {
+		byID[event.ID] = event
+	}
+
+	eventDTOs := make([]data.EventDTO, 0, len(page))
+	for _, row := range page {
+		event, ok := byID[row.ID]
+		if !ok {
+			continue
+		}
+		dto := toEventDTO(event)
+		distance := math.Round(haversineKm(lat, lng, event.Latitude, event.Longitude)*1000) / 1000
+		dto.DistanceKm = &distance
+		eventDTOs = append(eventDTOs, dto)
+	}
+	respondWithEvents(c, eventDTOs, fields)
+}
+
+// GetNearbyEvents lists published events within radius_km (default 10, at
+// most 500) of lat and lng, nearest first
+func GetNearbyEvents(c *gin.Context) {
+	lat, err := parseCoordinate(c, "lat", 90)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	lng, err := parseCoordinate(c, "lng", 180)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	radius := defaultNearbyRadiusKm
+	if value := c.Query("radius_km"); value != "" {
+		radius, err = strconv.ParseFloat(value, 64)
+		if err != nil || !(radius > 0 && radius <= maxNearbyRadiusKm) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid radius_km"})
+			return
+		}
+	}
+
+	listNearEvents(c, boundsAround(lat, lng, radius), lat, lng, radius)
+}
+
+// GetEventsInBounds lists published events inside the box given by south,
+// west, north and east, such as a map's visible area. Events are ordered by
+// distance from lat and lng when given, otherwise from the box's centre.
+func GetEventsInBounds(c *gin.Context) {
+	var bounds geoBounds
+	var err error
+	for _, edge := range []struct {
+		name  string
+		limit float64
+		value *float64
+	}{
+		{"south", 90, &bounds.South},
+		{"west", 180, &bounds.West},
+		{"north", 90, &bounds.North},
+		{"east", 180, &bounds.East},
+	} {
+		if *edge.value, err = parseCoordinate(c, edge.name, edge.limit); err != nil {
+			badRequest(c, err)
+			return
+		}
+	}
+	if bounds.South > bounds.North {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "South must not be above north"})
+		return
+	}
+
+	lat := (bounds.South + bounds.North) / 2
+	lng := (bounds.West + bounds.East) / 2
+	if bounds.West > bounds.East {
+		lng = wrapLongitude(lng + 180)
+	}
+	if c.Query("lat") != "" || c.Query("lng") != "" {
+		if lat, err = parseCoordinate(c, "lat", 90); err == nil {
+			lng, err = parseCoordinate(c, "lng", 180)
+		}
+		if err != nil {
+			badRequest(c, err)
+			return
+		}
+	}
+
+	listNearEvents(c, bounds, lat, lng, 0)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"backend/auth"
 	"backend/data"
 	"backend/database"
 	"errors"
@@ -12,11 +13,26 @@ import (
 
 // CreateOrganizer handles adding a new organizer
 func CreateOrganizer(c *gin.Context) {
-	var organizer data.Organizer
-	if err := c.ShouldBindJSON(&organizer); err != nil {
+	// Only the profile is read from the body: accounts are linked to organizer
+	// profiles by CreateEvent, and events are created through the event API
+	var input struct {
+		Name           string `json:"name"`
+		Email          string `json:"email"`
+		Password       string `json:"password"`
+		Description    string `json:"description"`
+		ContactDetails string `json:"contact_details"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
 		return
 	}
+	organizer := data.Organizer{
+		Name:           input.Name,
+		Email:          input.Email,
+		Password:       input.Password,
+		Description:    input.Description,
+		ContactDetails: input.ContactDetails,
+	}
 
 	// Check if an organizer with the same email already exists
 	var existing data.Organizer
@@ -27,6 +43,16 @@ func CreateOrganizer(c *gin.Context) {
 		return
 	}
 
+	// Store only a hash of the password; scraped organizers have none
+	if organizer.Password != "" {
+		hashed, err := auth.HashPassword(organizer.Password)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organizer"})
+			return
+		}
+		organizer.Password = hashed
+	}
+
 	// Create the organizer if no duplicate
 	if err := database.DB.Create(&organizer).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organizer"})
@@ -82,29 +108,59 @@ func LoginOrganizer(c *gin.Context) {
 		return
 	}
 
+	invalidCredentials := gin.H{
+		"error":     "Invalid email or password",
+		"logged_in": false, // Add this line to explicitly set logged_in to false
+	}
+
 	var organizer data.Organizer
 	// Check if the organizer exists in the database
-	if err := database.DB.Where("email = ? AND password = ?", loginData.Email, loginData.Password).First(&organizer).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":     "Invalid email or password",
-			"logged_in": false, // Add this line to explicitly set logged_in to false
-		})
+	if err := database.DB.Where("email = ?", loginData.Email).First(&organizer).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, invalidCredentials)
 		return
 	}
 
-	// Return the organizer's ID and other details on success
-	c.JSON(http.StatusOK, gin.H{
-		"message":      "Organizer logged in successfully",
-		"organizer_id": organizer.ID,
-		"name":         organizer.Name,
-		"email":        organizer.Email,
-		"logged_in":    true,
-	})
+	ok, needsRehash := auth.VerifyPassword(organizer.Password, loginData.Password)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, invalidCredentials)
+		return
+	}
+
+	// Upgrade a legacy plaintext password now that we know it is correct
+	if needsRehash {
+		hashed, err := auth.HashPassword(loginData.Password)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in organizer"})
+			return
+		}
+		if err := database.DB.Model(&organizer).Update("password", hashed).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in organizer"})
+			return
+		}
+	}
+
+	response, err := issueSession(auth.KindOrganizer, organizer.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		return
+	}
+
+	// Return the organizer's ID, other details and tokens on success
+	response["message"] = "Organizer logged in successfully"
+	response["organizer_id"] = organizer.ID
+	response["name"] = organizer.Name
+	response["email"] = organizer.Email
+	response["logged_in"] = true
+
+	c.JSON(http.StatusOK, response)
 }
 
+// LogoutOrganizer revokes the session behind the caller's token
 func LogoutOrganizer(c *gin.Context) {
-	// This function would typically clear the session or token for the organizer
-	// For simplicity, we will just return a success message
+	if err := revokeSession(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":   "Organizer logged out successfully",
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"backend/data"
+	"backend/database"
+	"backend/scraper"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultScrapePageSize = 50
+	maxScrapePageSize     = 200
+)
+
+// AdminListScrapes lists scrape runs, newest first, without their errors.
+// ?source= and ?status= filter them, ?before= takes the runs older than the
+// given run ID and ?limit= caps the number returned (default 50, at most 200).
+func AdminListScrapes(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultScrapePageSize)))
+	if err != nil || limit < 1 || limit > maxScrapePageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	query := database.DB.Model(&data.ScrapeRun{}).Order("id DESC").Limit(limit)
+	if source := c.Query("source"); source != "" {
+		query = query.Where("source = ?", source)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if before := c.Query("before"); before != "" {
+		id, err := strconv.ParseUint(before, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before"})
+			return
+		}
+		query = query.Where("id < ?", id)
+	}
+
+	runs := []data.ScrapeRun{}
+	if err := query.Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve scrape runs"})
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// AdminGetScrape returns a scrape run with the errors it met
+func AdminGetScrape(c *gin.Context) {
+	var runs []data.ScrapeRun
+	if err := database.DB.Preload("Errors").Where("id = ?", c.Param("id")).Limit(1).Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve scrape run"})
+		return
+	}
+	if len(runs) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Scrape run not found"})
+		return
+	}
+	c.JSON(http.StatusOK, runs[0])
+}
+
+// AdminTriggerScrape starts a run of the source named in the body. The run goes
+// on in the background; its record is returned so it can be followed with
+// AdminGetScrape.
+func AdminTriggerScrape(c *gin.Context) {
+	var input struct {
+		Source string `json:"source" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	source, ok := scraper.Lookup(input.Source)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Source not found"})
+		return
+	}
+
+	run, err := scraper.DefaultScheduler.Trigger(source)
+	if err != nil {
+		if errors.Is(err, scraper.ErrRunInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": "This source is already being scraped"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start scrape"})
+		return
+	}
+	c.JSON(http.StatusAccepted, run)
+}
+
+// AdminScrapeHealth reports on every source: whether it is enabled and running,
+// its last run and success, consecutive failures and next run
+func AdminScrapeHealth(c *gin.Context) {
+	report, err := scraper.DefaultScheduler.Health()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve scrape health"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
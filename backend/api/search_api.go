@@ -0,0 +1,174 @@
+package api
+
+import (
+	"backend/data"
+	"backend/database"
+	"backend/eventtags"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// likePattern lower-cases s and escapes LIKE wildcards so it matches literally inside %...%
+func likePattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(s))
+	return "%" + s + "%"
+}
+
+// listColumn returns SQL that renders a comma-separated column as ",a,b,c,":
+// lower-cased, without spaces, so one entry can be matched with LIKE '%,entry,%'
+func listColumn(column string) string {
+	return "(',' || REPLACE(LOWER(COALESCE(" + column + ", '')), ' ', '') || ',')"
+}
+
+// listEntryPattern matches one entry of a column rendered by listColumn
+func listEntryPattern(entry string) string {
+	entry = strings.ReplaceAll(strings.ToLower(entry), " ", "")
+	return "%," + strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(entry) + ",%"
+}
+
+// splitList splits a comma-separated query parameter, dropping empty entries
+func splitList(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// searchRankWeights weight the events_fts columns (name, description, tags,
+// location, organizer) for bm25
+const searchRankWeights = "10, 1, 5, 2, 3"
+
+// ftsQuery turns free text into an FTS5 query where every word must match,
+// as a word or as the start of one
+func ftsQuery(text string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"*`
+	}
+	return strings.Join(words, " ")
+}
+
+// searchEvents narrows a query of published events by the search parameters.
+// It returns a user-facing message when a parameter is invalid.
+func searchEvents(c *gin.Context, query *gorm.DB) (*gorm.DB, eventListOptions, string) {
+	var options eventListOptions
+
+	// Every word must appear in the name, description, tags, location or organizer name
+	if q := strings.TrimSpace(c.Query("q")); q != "" && database.FullTextSearch {
+		query, options = matchEvents(query, ftsQuery(q))
+	} else {
+		for _, word := range strings.Fields(q) {
+			pattern := likePattern(word)
+			query = query.Where(
+				`(LOWER(events.name) LIKE ? ESCAPE '\' OR LOWER(events.description) LIKE ? ESCAPE '\' OR LOWER(events.tags) LIKE ? ESCAPE '\' OR LOWER(events.location) LIKE ? ESCAPE '\'`+
+					` OR events.organizer_id IN (SELECT id FROM organizers WHERE LOWER(name) LIKE ? ESCAPE '\'))`,
+				pattern, pattern, pattern, pattern, pattern,
+			)
+		}
+	}
+
+	// Any of the given categories
+	if categories := splitList(c.Query("category")); len(categories) > 0 {
+		conditions := make([]string, 0, len(categories))
+		args := make([]interface{}, 0, len(categories))
+		for _, category := range categories {
+			conditions = append(conditions, listColumn("events.category")+` LIKE ? ESCAPE '\'`)
+			args = append(args, listEntryPattern(category))
+		}
+		query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+
+	// All of the given tags, by slug or synonym
+	for _, tag := range splitList(c.Query("tags")) {
+		query = query.Where(`events.id IN (SELECT event_tags.event_id FROM event_tags
+			JOIN tag_synonyms ON tag_synonyms.tag_id = event_tags.tag_id WHERE tag_synonyms.alias = ?)`, eventtags.Key(tag))
+	}
+
+	if value := c.Query("organizer_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, options, "Invalid organizer_id"
+		}
+		query = query.Where("events.organizer_id = ?", id)
+	}
+	if value := strings.TrimSpace(c.Query("organizer")); value != "" {
+		query = query.Where(`events.organizer_id IN (SELECT id FROM organizers WHERE LOWER(name) LIKE ? ESCAPE '\')`, likePattern(value))
+	}
+
+	return query, options, ""
+}
+
+// matchEvents restricts query to the events matching an FTS5 query and ranks
+// them by relevance, best first. The page's ranks and highlighted snippets are
+// read back from the index once the page is known.
+func matchEvents(query *gorm.DB, match string) (*gorm.DB, eventListOptions) {
+	query = query.Joins(`JOIN (SELECT rowid AS fts_id, bm25(events_fts, `+searchRankWeights+`) AS search_rank
+		FROM events_fts WHERE events_fts MATCH ?) AS matches ON matches.fts_id = events.id`, match)
+
+	ranks := make(map[uint]float64)
+	return query, eventListOptions{
+		sortKeys: map[string]eventSortKey{
+			// bm25 scores are negative, more so for better matches
+			"relevance": {
+				column: "matches.search_rank",
+				value:  func(e data.Event) interface{} { return ranks[e.ID] },
+			},
+		},
+		defaultSort: "relevance",
+		decorate: func(events []data.Event, dtos []data.EventDTO) error {
+			if len(events) == 0 {
+				return nil
+			}
+			ids := make([]uint, 0, len(events))
+			for _, event := range events {
+				ids = append(ids, event.ID)
+			}
+			var rows []struct {
+				ID      uint
+				Rank    float64
+				Snippet string
+			}
+			if err := database.DB.Raw(`SELECT rowid AS id, bm25(events_fts, `+searchRankWeights+`) AS rank,
+				snippet(events_fts, -1, '<mark>', '</mark>', '…', 12) AS snippet
+				FROM events_fts WHERE events_fts MATCH ? AND rowid IN ?`, match, ids).Scan(&rows).Error; err != nil {
+				return err
+			}
+			snippets := make(map[uint]string, len(rows))
+			for _, row := range rows {
+				ranks[row.ID] = row.Rank
+				snippets[row.ID] = row.Snippet
+			}
+			for i := range dtos {
+				dtos[i].Snippet = snippets[dtos[i].ID]
+			}
+			return nil
+		},
+	}
+}
+
+// SearchEvents finds published events matching all of the given criteria:
+//
+//	q=jazz downtown       every word in the name, description, tags, location or organizer name;
+//	                      with the full-text index, words also match as prefixes and results
+//	                      come best match first (sort=relevance) with a highlighted snippet
+//	category=Music,Arts   any of these categories
+//	tags=jazz,outdoor     all of these tags, by slug or synonym
+//	organizer_id, organizer  organizer by ID, or by part of its name
+//
+// Matching ignores case. The source, cost and date filters, sorting and
+// pagination of GetAllEvents apply as well.
+func SearchEvents(c *gin.Context) {
+	query, options, message := searchEvents(c, database.DB.Model(&data.Event{}).Where("events.active = ?", true))
+	if message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return
+	}
+	listEvents(c, query, options)
+}
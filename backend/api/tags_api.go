@@ -0,0 +1,65 @@
+package api
+
+import (
+	"backend/data"
+	"backend/database"
+	"backend/eventtags"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTagPageSize = 100
+	maxTagPageSize     = 500
+)
+
+// GetTags lists the tags of published events, most used first, with how many
+// events use each. ?q= keeps tags whose slug or name starts with the given
+// text and ?limit= caps the number returned (default 100, at most 500).
+func GetTags(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultTagPageSize)))
+	if err != nil || limit < 1 || limit > maxTagPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	query := database.DB.Model(&data.Tag{}).
+		Select("tags.slug, tags.name, COUNT(events.id) AS count").
+		Joins("JOIN event_tags ON event_tags.tag_id = tags.id").
+		Joins("JOIN events ON events.id = event_tags.event_id AND events.active = ?", true).
+		Group("tags.id")
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		prefix := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(q)) + "%"
+		query = query.Where(`(tags.slug LIKE ? ESCAPE '\' OR LOWER(tags.name) LIKE ? ESCAPE '\')`, eventtags.Slug(q)+"%", prefix)
+	}
+
+	tags := []data.TagDTO{}
+	if err := query.Order("count DESC").Order("tags.slug").Limit(limit).Scan(&tags).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tags"})
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// GetTagEvents lists the published events with a tag, found by its slug or any
+// of its synonyms. The filters, sorting and pagination of GetAllEvents apply.
+func GetTagEvents(c *gin.Context) {
+	tag, err := eventtags.Find(database.DB, c.Param("slug"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tag"})
+		return
+	}
+
+	listEvents(c, database.DB.Model(&data.Event{}).
+		Where("events.active = ?", true).
+		Where("events.id IN (SELECT event_id FROM event_tags WHERE tag_id = ?)", tag.ID), eventListOptions{})
+}
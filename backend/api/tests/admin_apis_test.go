@@ -0,0 +1,243 @@
+package api_tests
+
+import (
+	"backend/api"
+	"backend/data"
+	"backend/database"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupAdminRouter() *gin.Engine {
+	router := setupAuthRouter()
+	router.GET("/GetAllEvents", api.GetAllEvents)
+	admin := router.Group("/admin", api.RequireAuth(), api.RequireRole(data.RoleModerator, data.RoleAdmin))
+	admin.GET("/users", api.AdminListUsers)
+	admin.POST("/users/:id/ban", api.AdminBanUser)
+	admin.POST("/users/:id/unban", api.AdminUnbanUser)
+	admin.POST("/events/:id/unpublish", api.AdminSetEventActive(false))
+	admin.POST("/events/:id/publish", api.AdminSetEventActive(true))
+	admin.GET("/scrapes", api.AdminListScrapes)
+	admin.GET("/scrapes/health", api.AdminScrapeHealth)
+	admin.GET("/scrapes/:id", api.AdminGetScrape)
+	admin.GET("/duplicates", api.AdminListDuplicates)
+	admin.POST("/duplicates/:id/merge", api.AdminMergeDuplicate)
+	admin.POST("/duplicates/:id/distinct", api.AdminDismissDuplicate)
+	adminOnly := admin.Group("/", api.RequireRole(data.RoleAdmin))
+	adminOnly.PUT("/users/:id/role", api.AdminSetUserRole)
+	adminOnly.POST("/organizers/merge", api.AdminMergeOrganizers)
+	adminOnly.PUT("/organizers/:id/user", api.AdminLinkOrganizer)
+	adminOnly.POST("/scrapes", api.AdminTriggerScrape)
+	return router
+}
+
+// setupAdminTestDB creates a plain user, a moderator and an admin, all with password "pw"
+func setupAdminTestDB() (user, moderator, admin data.User) {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	database.Migrate(db)
+	database.DB = db
+
+	user = data.User{Name: "User", Email: "user@example.com", Password: "pw"}
+	moderator = data.User{Name: "Moderator", Email: "mod@example.com", Password: "pw", Role: data.RoleModerator}
+	admin = data.User{Name: "Admin", Email: "admin@example.com", Password: "pw", Role: data.RoleAdmin}
+	db.Create(&user)
+	db.Create(&moderator)
+	db.Create(&admin)
+	return
+}
+
+func serveWithToken(router *gin.Engine, method, path, token, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminListUsers_RequiresModerator(t *testing.T) {
+	router := setupAdminRouter()
+	user, moderator, _ := setupAdminTestDB()
+
+	userToken := login(t, router, "/LoginUser", user.Email, "pw")["token"].(string)
+	w := serveWithToken(router, http.MethodGet, "/admin/users", userToken, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	modToken := login(t, router, "/LoginUser", moderator.Email, "pw")["token"].(string)
+	w = serveWithToken(router, http.MethodGet, "/admin/users?role=admin", modToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var users []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &users)
+	assert.Len(t, users, 1)
+	assert.Equal(t, "admin@example.com", users[0]["email"])
+	assert.Nil(t, users[0]["password"])
+}
+
+func TestAdminBanUser_BlocksLoginAndRevokesTokens(t *testing.T) {
+	router := setupAdminRouter()
+	user, moderator, admin := setupAdminTestDB()
+
+	userToken := login(t, router, "/LoginUser", user.Email, "pw")["token"].(string)
+	modToken := login(t, router, "/LoginUser", moderator.Email, "pw")["token"].(string)
+
+	w := serveWithToken(router, http.MethodPost, fmt.Sprintf("/admin/users/%d/ban", user.ID), modToken, `{"reason":"spam"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Existing tokens stop working and new logins are refused
+	w = serveWithToken(router, http.MethodGet, "/whoami", userToken, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = serveWithToken(router, http.MethodPost, "/LoginUser", "", `{"email":"user@example.com","password":"pw"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Moderators cannot ban admins
+	w = serveWithToken(router, http.MethodPost, fmt.Sprintf("/admin/users/%d/ban", admin.ID), modToken, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Nor lift a ban an admin put on staff
+	adminToken := login(t, router, "/LoginUser", admin.Email, "pw")["token"].(string)
+	w = serveWithToken(router, http.MethodPost, fmt.Sprintf("/admin/users/%d/ban", moderator.ID), adminToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	otherMod := data.User{Name: "Other Moderator", Email: "mod2@example.com", Password: "pw", Role: data.RoleModerator}
+	database.DB.Create(&otherMod)
+	otherModToken := login(t, router, "/LoginUser", otherMod.Email, "pw")["token"].(string)
+	w = serveWithToken(router, http.MethodPost, fmt.Sprintf("/admin/users/%d/unban", moderator.ID), otherModToken, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serveWithToken(router, http.MethodPost, fmt.Sprintf("/admin/users/%d/unban", moderator.ID), adminToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	modToken = login(t, router, "/LoginUser", moderator.Email, "pw")["token"].(string)
+
+	w = serveWithToken(router, http.MethodPost, fmt.Sprintf("/admin/users/%d/unban", user.ID), modToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	login(t, router, "/LoginUser", user.Email, "pw")
+}
+
+func TestAdminSetUserRole_AdminOnly(t *testing.T) {
+	router := setupAdminRouter()
+	user, moderator, admin := setupAdminTestDB()
+
+	modToken := login(t, router, "/LoginUser", moderator.Email, "pw")["token"].(string)
+	w := serveWithToken(router, http.MethodPut, fmt.Sprintf("/admin/users/%d/role", user.ID), modToken, `{"role":"moderator"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	adminToken := login(t, router, "/LoginUser", admin.Email, "pw")["token"].(string)
+	w = serveWithToken(router, http.MethodPut, fmt.Sprintf("/admin/users/%d/role", user.ID), adminToken, `{"role":"superuser"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serveWithToken(router, http.MethodPut, fmt.Sprintf("/admin/users/%d/role", user.ID), adminToken, `{"role":"moderator"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var dbUser data.User
+	database.DB.First(&dbUser, user.ID)
+	assert.Equal(t, data.RoleModerator, dbUser.Role)
+
+	// The last admin cannot be demoted, by themselves or anyone
+	w = serveWithToken(router, http.MethodPut, fmt.Sprintf("/admin/users/%d/role", admin.ID), adminToken, `{"role":"user"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var dbAdmin data.User
+	database.DB.First(&dbAdmin, admin.ID)
+	assert.Equal(t, data.RoleAdmin, dbAdmin.Role)
+
+	w = serveWithToken(router, http.MethodPut, fmt.Sprintf("/admin/users/%d/role", user.ID), adminToken, `{"role":"admin"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serveWithToken(router, http.MethodPut, fmt.Sprintf("/admin/users/%d/role", admin.ID), adminToken, `{"role":"user"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAdminUnpublishEvent_HidesFromListing(t *testing.T) {
+	router := setupAdminRouter()
+	_, moderator, _ := setupAdminTestDB()
+
+	spam := data.Event{Name: "Spam Event"}
+	genuine := data.Event{Name: "Real Event"}
+	database.DB.Create(&spam)
+	database.DB.Create(&genuine)
+
+	modToken := login(t, router, "/LoginUser", moderator.Email, "pw")["token"].(string)
+	w := serveWithToken(router, http.MethodPost, fmt.Sprintf("/admin/events/%d/unpublish", spam.ID), modToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serveWithToken(router, http.MethodGet, "/GetAllEvents", "", "")
+	var events []data.EventDTO
+	json.Unmarshal(w.Body.Bytes(), &events)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "Real Event", events[0].Name)
+
+	w = serveWithToken(router, http.MethodPost, fmt.Sprintf("/admin/events/%d/publish", spam.ID), modToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serveWithToken(router, http.MethodGet, "/GetAllEvents", "", "")
+	json.Unmarshal(w.Body.Bytes(), &events)
+	assert.Len(t, events, 2)
+
+	// A merged duplicate stays unpublished
+	duplicate := data.Event{Name: "Real Event, again", DuplicateOfID: &genuine.ID}
+	database.DB.Create(&duplicate)
+	database.DB.Model(&duplicate).Update("active", false)
+	w = serveWithToken(router, http.MethodPost, fmt.Sprintf("/admin/events/%d/publish", duplicate.ID), modToken, "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	database.DB.First(&duplicate, duplicate.ID)
+	assert.False(t, duplicate.Active)
+}
+
+func TestAdminMergeOrganizers(t *testing.T) {
+	router := setupAdminRouter()
+	user, _, admin := setupAdminTestDB()
+
+	source := data.Organizer{Name: "Hippodrome", Email: "hippo-dup@example.com", ContactDetails: "352-555-0100", UserID: &user.ID}
+	target := data.Organizer{Name: "Hippodrome Theatre", Email: "hippo@example.com"}
+	database.DB.Create(&source)
+	database.DB.Create(&target)
+	database.DB.Create(&data.Event{Name: "Play", OrganizerID: source.ID})
+	database.DB.Create(&data.Event{Name: "Film", OrganizerID: source.ID})
+
+	adminToken := login(t, router, "/LoginUser", admin.Email, "pw")["token"].(string)
+	body := fmt.Sprintf(`{"source_id":%d,"target_id":%d}`, source.ID, target.ID)
+	w := serveWithToken(router, http.MethodPost, "/admin/organizers/merge", adminToken, body)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var moved int64
+	database.DB.Model(&data.Event{}).Where("organizer_id = ?", target.ID).Count(&moved)
+	assert.Equal(t, int64(2), moved)
+
+	var merged data.Organizer
+	database.DB.First(&merged, target.ID)
+	assert.Equal(t, "352-555-0100", merged.ContactDetails)
+	assert.Equal(t, user.ID, *merged.UserID)
+	assert.Error(t, database.DB.First(&data.Organizer{}, source.ID).Error)
+}
+
+func TestAdminLinkOrganizer(t *testing.T) {
+	router := setupAdminRouter()
+	user, moderator, admin := setupAdminTestDB()
+	organizer := data.Organizer{Name: "Hippodrome", Email: "user@example.com"}
+	database.DB.Create(&organizer)
+	path := fmt.Sprintf("/admin/organizers/%d/user", organizer.ID)
+
+	modToken := login(t, router, "/LoginUser", moderator.Email, "pw")["token"].(string)
+	w := serveWithToken(router, http.MethodPut, path, modToken, fmt.Sprintf(`{"user_id":%d}`, user.ID))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	adminToken := login(t, router, "/LoginUser", admin.Email, "pw")["token"].(string)
+	w = serveWithToken(router, http.MethodPut, path, adminToken, `{"user_id":999}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = serveWithToken(router, http.MethodPut, path, adminToken, fmt.Sprintf(`{"user_id":%d}`, user.ID))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	database.DB.First(&organizer, organizer.ID)
+	assert.Equal(t, user.ID, *organizer.UserID)
+
+	w = serveWithToken(router, http.MethodPut, path, adminToken, `{"user_id":null}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	database.DB.First(&organizer, organizer.ID)
+	assert.Nil(t, organizer.UserID)
+}
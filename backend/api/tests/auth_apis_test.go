@@ -0,0 +1,185 @@
+package api_tests
+
+import (
+	"backend/api"
+	"backend/auth"
+	"backend/data"
+	"backend/database"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupAuthTestDB() *gorm.DB {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	database.Migrate(db)
+	return db
+}
+
+func setupAuthRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	auth.SetSigningKey([]byte("test-signing-key"))
+
+	router := gin.New()
+	authed := router.Group("/", api.RequireAuth())
+	router.POST("/LoginUser", api.LoginUser)
+	router.POST("/loginOrganizer", api.LoginOrganizer)
+	router.POST("/refreshToken", api.RefreshToken)
+	authed.POST("/logout/:id", api.AuthorizeUserParam("id"), api.LogoutUser)
+	authed.POST("/logoutOrganizer", api.LogoutOrganizer)
+	authed.GET("/whoami", func(c *gin.Context) {
+		principal, _ := api.CurrentPrincipal(c)
+		c.JSON(http.StatusOK, gin.H{"kind": principal.Kind, "id": principal.ID})
+	})
+	return router
+}
+
+// login posts credentials to the given login route and returns the decoded response
+func login(t *testing.T, router *gin.Engine, path, email, password string) map[string]any {
+	body := `{"email":"` + email + `","password":"` + password + `"}`
+	req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response map[string]any
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response
+}
+
+func authedRequest(method, path, token string) *http.Request {
+	req, _ := http.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestLoginUser_IssuesToken(t *testing.T) {
+	database.DB = setupAuthTestDB()
+	router := setupAuthRouter()
+
+	user := data.User{Name: "Test User", Email: "auth@example.com", Password: "1234"}
+	database.DB.Create(&user)
+
+	response := login(t, router, "/LoginUser", user.Email, "1234")
+	token, _ := response["token"].(string)
+	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, response["refresh_token"])
+	assert.NotEmpty(t, response["expires_at"])
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodGet, "/whoami", token))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var whoami map[string]any
+	json.Unmarshal(w.Body.Bytes(), &whoami)
+	assert.Equal(t, auth.KindUser, whoami["kind"])
+	assert.Equal(t, float64(user.ID), whoami["id"])
+}
+
+func TestLoginOrganizer_IssuesToken(t *testing.T) {
+	database.DB = setupAuthTestDB()
+	router := setupAuthRouter()
+
+	organizer := data.Organizer{Name: "Test Organizer", Email: "org@example.com", Password: "secret"}
+	database.DB.Create(&organizer)
+
+	response := login(t, router, "/loginOrganizer", organizer.Email, "secret")
+	token, _ := response["token"].(string)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodGet, "/whoami", token))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var whoami map[string]any
+	json.Unmarshal(w.Body.Bytes(), &whoami)
+	assert.Equal(t, auth.KindOrganizer, whoami["kind"])
+	assert.Equal(t, float64(organizer.ID), whoami["id"])
+}
+
+func TestRequireAuth_RejectsMissingAndTamperedTokens(t *testing.T) {
+	database.DB = setupAuthTestDB()
+	router := setupAuthRouter()
+
+	user := data.User{Name: "Test User", Email: "auth@example.com", Password: "1234"}
+	database.DB.Create(&user)
+	token := login(t, router, "/LoginUser", user.Email, "1234")["token"].(string)
+
+	// No Authorization header
+	req, _ := http.NewRequest(http.MethodGet, "/whoami", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Signature does not match the payload
+	parts := strings.Split(token, ".")
+	forged := parts[0] + "." + parts[1] + "x." + parts[2]
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodGet, "/whoami", forged))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestLogoutUser_RevokesToken(t *testing.T) {
+	database.DB = setupAuthTestDB()
+	router := setupAuthRouter()
+
+	user := data.User{Name: "Test User", Email: "auth@example.com", Password: "1234"}
+	database.DB.Create(&user)
+	response := login(t, router, "/LoginUser", user.Email, "1234")
+	token := response["token"].(string)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodPost, "/logout/"+strconv.Itoa(int(user.ID)), token))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The access token is rejected once its session is revoked
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodGet, "/whoami", token))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// So is the refresh token
+	body := `{"refresh_token":"` + response["refresh_token"].(string) + `"}`
+	req, _ := http.NewRequest(http.MethodPost, "/refreshToken", bytes.NewBufferString(body))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRefreshToken_RotatesRefreshToken(t *testing.T) {
+	database.DB = setupAuthTestDB()
+	router := setupAuthRouter()
+
+	user := data.User{Name: "Test User", Email: "auth@example.com", Password: "1234"}
+	database.DB.Create(&user)
+	oldRefresh := login(t, router, "/LoginUser", user.Email, "1234")["refresh_token"].(string)
+
+	req, _ := http.NewRequest(http.MethodPost, "/refreshToken", bytes.NewBufferString(`{"refresh_token":"`+oldRefresh+`"}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]any
+	json.Unmarshal(w.Body.Bytes(), &response)
+	newToken, _ := response["token"].(string)
+	assert.NotEmpty(t, newToken)
+	assert.NotEqual(t, oldRefresh, response["refresh_token"])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, authedRequest(http.MethodGet, "/whoami", newToken))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The previous refresh token cannot be replayed
+	req, _ = http.NewRequest(http.MethodPost, "/refreshToken", bytes.NewBufferString(`{"refresh_token":"`+oldRefresh+`"}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
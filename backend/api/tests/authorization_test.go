@@ -0,0 +1,343 @@
+package api_tests
+
+import (
+	"backend/api"
+	"backend/data"
+	"backend/database"
+	"backend/scraper"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// ownershipFixture holds the records and tokens shared by the authorization tests
+type ownershipFixture struct {
+	owner, other, admin data.User
+	organizer, spareOrg data.Organizer
+	event               data.Event
+	tokens              map[string]string // keyed by "owner", "other", "admin", "organizer"
+}
+
+func setupAuthorizationRouter() *gin.Engine {
+	router := setupAuthRouter()
+	router.POST("/register", api.RegisterUser)
+	router.POST("/createOrganizer", api.CreateOrganizer)
+	router.GET("/GetEvent/:id", api.OptionalAuth(), api.GetEventByID)
+	authed := router.Group("/", api.RequireAuth())
+	authed.PUT("/editUser/:id", api.AuthorizeUserParam("id"), api.EditUserInfo)
+	authed.DELETE("/users/:id", api.AuthorizeUserParam("id"), api.RemoveUser)
+	authed.POST("/CreateEvent", api.CreateEvent)
+	authed.PUT("/EditEvent/:id", api.AuthorizeEventParam("id"), api.EditEvent)
+	authed.DELETE("/DeleteEvent/:id", api.AuthorizeEventParam("id"), api.DeleteEvent)
+	authed.POST("/mapUserToEvent", api.MapUserToEvent)
+	authed.POST("/unmapUserFromEvent", api.UnmapUserFromEvent)
+	authed.DELETE("/deleteOrganizer/:id", api.AuthorizeOrganizerParam("id"), api.DeleteOrganizer)
+	authed.POST("/events/:id/comments", api.AddCommentToEvent)
+	return router
+}
+
+func setupOwnershipFixture(t *testing.T, router *gin.Engine) *ownershipFixture {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	database.Migrate(db)
+	database.DB = db
+
+	f := &ownershipFixture{
+		owner: data.User{Name: "Owner", Email: "owner@example.com", Password: "pw"},
+		other: data.User{Name: "Other", Email: "other@example.com", Password: "pw"},
+		admin: data.User{Name: "Admin", Email: "admin@example.com", Password: "pw", Role: data.RoleAdmin},
+	}
+	db.Create(&f.owner)
+	db.Create(&f.other)
+	db.Create(&f.admin)
+
+	f.organizer = data.Organizer{Name: "Owner", Email: "owner-org@example.com", Password: "pw", UserID: &f.owner.ID}
+	f.spareOrg = data.Organizer{Name: "Owner Spare", Email: "spare@example.com", UserID: &f.owner.ID}
+	db.Create(&f.organizer)
+	db.Create(&f.spareOrg)
+
+	f.event = data.Event{Name: "Owned Event", OrganizerID: f.organizer.ID}
+	db.Create(&f.event)
+
+	f.tokens = map[string]string{
+		"owner":     login(t, router, "/LoginUser", f.owner.Email, "pw")["token"].(string),
+		"other":     login(t, router, "/LoginUser", f.other.Email, "pw")["token"].(string),
+		"admin":     login(t, router, "/LoginUser", f.admin.Email, "pw")["token"].(string),
+		"organizer": login(t, router, "/loginOrganizer", f.organizer.Email, "pw")["token"].(string),
+	}
+	return f
+}
+
+func TestMutatingRoutes_OwnershipAuthorization(t *testing.T) {
+	type request struct {
+		method string
+		path   func(f *ownershipFixture) string
+		body   func(f *ownershipFixture) string
+	}
+
+	routes := map[string]request{
+		"edit user": {http.MethodPut,
+			func(f *ownershipFixture) string { return fmt.Sprintf("/editUser/%d", f.owner.ID) },
+			func(f *ownershipFixture) string { return `{"name":"Renamed","email":"owner@example.com"}` }},
+		"delete user": {http.MethodDelete,
+			func(f *ownershipFixture) string { return fmt.Sprintf("/users/%d", f.owner.ID) },
+			nil},
+		"logout user": {http.MethodPost,
+			func(f *ownershipFixture) string { return fmt.Sprintf("/logout/%d", f.owner.ID) },
+			nil},
+		"create event": {http.MethodPost,
+			func(f *ownershipFixture) string { return "/CreateEvent" },
+			func(f *ownershipFixture) string {
+				return fmt.Sprintf(`{"name":"New","date":"2025-06-15","time":"10:00 AM","organizer_id":%d}`, f.owner.ID)
+			}},
+		"edit event": {http.MethodPut,
+			func(f *ownershipFixture) string { return fmt.Sprintf("/EditEvent/%d", f.event.ID) },
+			func(f *ownershipFixture) string { return `{"name":"Renamed Event"}` }},
+		"delete event": {http.MethodDelete,
+			func(f *ownershipFixture) string { return fmt.Sprintf("/DeleteEvent/%d", f.event.ID) },
+			nil},
+		"map user": {http.MethodPost,
+			func(f *ownershipFixture) string { return "/mapUserToEvent" },
+			func(f *ownershipFixture) string {
+				return fmt.Sprintf(`{"user_id":%d,"event_id":%d}`, f.owner.ID, f.event.ID)
+			}},
+		"unmap user": {http.MethodPost,
+			func(f *ownershipFixture) string { return "/unmapUserFromEvent" },
+			func(f *ownershipFixture) string {
+				return fmt.Sprintf(`{"user_id":%d,"event_id":%d}`, f.owner.ID, f.event.ID)
+			}},
+		"delete organizer": {http.MethodDelete,
+			func(f *ownershipFixture) string { return fmt.Sprintf("/deleteOrganizer/%d", f.spareOrg.ID) },
+			nil},
+		"add comment": {http.MethodPost,
+			func(f *ownershipFixture) string { return fmt.Sprintf("/events/%d/comments", f.event.ID) },
+			func(f *ownershipFixture) string { return fmt.Sprintf(`{"user_id":%d,"content":"Hi"}`, f.owner.ID) }},
+	}
+
+	successCodes := map[string]int{"create event": http.StatusCreated}
+
+	for name, route := range routes {
+		for _, caller := range []string{"owner", "other", "admin"} {
+			t.Run(name+"/"+caller, func(t *testing.T) {
+				router := setupAuthorizationRouter()
+				f := setupOwnershipFixture(t, router)
+
+				body := bytes.NewBuffer(nil)
+				if route.body != nil {
+					body = bytes.NewBufferString(route.body(f))
+				}
+				req, _ := http.NewRequest(route.method, route.path(f), body)
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", "Bearer "+f.tokens[caller])
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				expected := http.StatusOK
+				if code, ok := successCodes[name]; ok {
+					expected = code
+				}
+				if caller == "other" {
+					expected = http.StatusForbidden
+				}
+				assert.Equal(t, expected, w.Code, w.Body.String())
+			})
+		}
+	}
+}
+
+func TestEditEvent_OrganizerAccountOwnsItsEvents(t *testing.T) {
+	router := setupAuthorizationRouter()
+	f := setupOwnershipFixture(t, router)
+
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("/EditEvent/%d", f.event.ID), bytes.NewBufferString(`{"name":"Renamed Event"}`))
+	req.Header.Set("Authorization", "Bearer "+f.tokens["organizer"])
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// An organizer account cannot act on another organizer's profile
+	req, _ = http.NewRequest(http.MethodDelete, fmt.Sprintf("/deleteOrganizer/%d", f.spareOrg.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+f.tokens["organizer"])
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestEditUserInfo_CannotSelfAssignAdmin(t *testing.T) {
+	router := setupAuthorizationRouter()
+	f := setupOwnershipFixture(t, router)
+
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("/editUser/%d", f.owner.ID), bytes.NewBufferString(`{"role":"admin"}`))
+	req.Header.Set("Authorization", "Bearer "+f.tokens["owner"])
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var dbUser data.User
+	database.DB.First(&dbUser, f.owner.ID)
+	assert.Equal(t, data.RoleUser, dbUser.Role)
+}
+
+func TestEditUserInfo_IgnoresIDInBody(t *testing.T) {
+	router := setupAuthorizationRouter()
+	f := setupOwnershipFixture(t, router)
+
+	body := fmt.Sprintf(`{"id":%d,"name":"Hijacked","email":"owner@example.com"}`, f.other.ID)
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("/editUser/%d", f.owner.ID), bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+f.tokens["owner"])
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var owner, other data.User
+	database.DB.First(&owner, f.owner.ID)
+	database.DB.First(&other, f.other.ID)
+	assert.Equal(t, "Hijacked", owner.Name)
+	assert.Equal(t, "Other", other.Name)
+	assert.Equal(t, "other@example.com", other.Email)
+}
+
+func TestAccountRoutes_IgnoreLinkedRecordsInBody(t *testing.T) {
+	router := setupAuthorizationRouter()
+	f := setupOwnershipFixture(t, router)
+	events := fmt.Sprintf(`[{"id":%d,"name":"Rewritten","organizer_id":%d},{"name":"Planted","organizer_id":%d}]`,
+		f.event.ID, f.organizer.ID, f.organizer.ID)
+
+	requests := []*http.Request{}
+	req, _ := http.NewRequest(http.MethodPost, "/register", bytes.NewBufferString(
+		`{"name":"New","email":"new@example.com","password":"pw","role":"admin","Events":`+events+`}`))
+	requests = append(requests, req)
+	req, _ = http.NewRequest(http.MethodPut, fmt.Sprintf("/editUser/%d", f.owner.ID), bytes.NewBufferString(`{"Events":`+events+`}`))
+	req.Header.Set("Authorization", "Bearer "+f.tokens["owner"])
+	requests = append(requests, req)
+	req, _ = http.NewRequest(http.MethodPost, "/createOrganizer", bytes.NewBufferString(
+		`{"name":"Org","email":"org@example.com","user_id":`+fmt.Sprint(f.other.ID)+`,"Events":`+events+`}`))
+	requests = append(requests, req)
+
+	for _, req := range requests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Less(t, w.Code, 300, req.URL.Path+": "+w.Body.String())
+	}
+
+	var names []string
+	database.DB.Model(&data.Event{}).Order("id").Pluck("name", &names)
+	assert.Equal(t, []string{"Owned Event"}, names)
+
+	var registered data.User
+	database.DB.Where("email = ?", "new@example.com").First(&registered)
+	assert.Equal(t, data.RoleUser, registered.Role)
+	var organizer data.Organizer
+	database.DB.Where("email = ?", "org@example.com").First(&organizer)
+	assert.Nil(t, organizer.UserID)
+}
+
+func TestCreateEvent_IgnoresLinkedRecordsInBody(t *testing.T) {
+	router := setupAuthorizationRouter()
+	f := setupOwnershipFixture(t, router)
+
+	// Geocode offline so the coordinates are saved after the event is created
+	geocoder := scraper.Geocoder
+	scraper.Geocoder = func(location string) (float64, float64, error) { return 29.6516, -82.3248, nil }
+	t.Cleanup(func() { scraper.Geocoder = geocoder })
+
+	body := fmt.Sprintf(`{"name":"New","date":"2025-06-15","location":"Bo Diddley Plaza","tags":"Music","organizer_id":%d,
+		"Users":[{"id":%d},{"name":"Planted","email":"planted@example.com","role":"admin","password":"x"}],
+		"Organizer":{"id":%d,"name":"Taken Over","email":"taken@example.com"}}`, f.owner.ID, f.owner.ID, f.spareOrg.ID)
+	req, _ := http.NewRequest(http.MethodPost, "/CreateEvent", bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+f.tokens["other"])
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	body = strings.ReplaceAll(body, fmt.Sprintf(`"organizer_id":%d`, f.owner.ID), fmt.Sprintf(`"organizer_id":%d`, f.other.ID))
+	req, _ = http.NewRequest(http.MethodPost, "/CreateEvent", bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+f.tokens["other"])
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var planted int64
+	database.DB.Model(&data.User{}).Where("email = ?", "planted@example.com").Count(&planted)
+	assert.Zero(t, planted)
+	var spare data.Organizer
+	database.DB.First(&spare, f.spareOrg.ID)
+	assert.Equal(t, "Owner Spare", spare.Name)
+	assert.Equal(t, "spare@example.com", spare.Email)
+
+	var created data.Event
+	database.DB.Preload("Users").Preload("Organizer").Where("name = ?", "New").First(&created)
+	assert.Equal(t, 29.6516, created.Latitude)
+	assert.Equal(t, "Other", created.Organizer.Name)
+	assert.Empty(t, created.Users)
+}
+
+func TestGetEventByID_HidesInactiveEventsFromOthers(t *testing.T) {
+	router := setupAuthorizationRouter()
+	f := setupOwnershipFixture(t, router)
+	database.DB.Model(&f.event).Update("active", false)
+
+	for caller, expected := range map[string]int{
+		"":      http.StatusNotFound,
+		"other": http.StatusNotFound,
+		"owner": http.StatusOK,
+		"admin": http.StatusOK,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/GetEvent/%d", f.event.ID), nil)
+		if caller != "" {
+			req.Header.Set("Authorization", "Bearer "+f.tokens[caller])
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, expected, w.Code, caller)
+	}
+}
+
+func TestScrapedOrganizerEmail_GrantsNoOwnership(t *testing.T) {
+	router := setupAuthorizationRouter()
+	setupOwnershipFixture(t, router)
+
+	// Visit Gainesville gives its organizers a shared placeholder address
+	scraped := data.Organizer{Name: "Visit Gainesville", Email: "example@ex.com"}
+	database.DB.Create(&scraped)
+	event := data.Event{Name: "Scraped Event", OrganizerID: scraped.ID, Source: data.SourceVisitGainesville}
+	database.DB.Create(&event)
+
+	w := serveWithToken(router, http.MethodPost, "/register", "", `{"name":"Visit Gainesville","email":"example@ex.com","password":"pw"}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var squatter data.User
+	database.DB.Where("email = ?", "example@ex.com").First(&squatter)
+	token := login(t, router, "/LoginUser", "example@ex.com", "pw")["token"].(string)
+
+	w = serveWithToken(router, http.MethodPut, fmt.Sprintf("/EditEvent/%d", event.ID), token, `{"name":"Hijacked"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serveWithToken(router, http.MethodDelete, fmt.Sprintf("/DeleteEvent/%d", event.ID), token, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serveWithToken(router, http.MethodDelete, fmt.Sprintf("/deleteOrganizer/%d", scraped.ID), token, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Creating an event gives the account its own profile rather than claiming the scraped one
+	w = serveWithToken(router, http.MethodPost, "/CreateEvent", token, `{"name":"Mine","date":"2025-06-15"}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	database.DB.First(&scraped, scraped.ID)
+	assert.Nil(t, scraped.UserID)
+	var mine data.Event
+	database.DB.Preload("Organizer").Where("name = ?", "Mine").First(&mine)
+	assert.NotEqual(t, scraped.ID, mine.OrganizerID)
+	assert.Equal(t, squatter.ID, *mine.Organizer.UserID)
+	w = serveWithToken(router, http.MethodPut, fmt.Sprintf("/EditEvent/%d", event.ID), token, `{"name":"Hijacked"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Deleting the account leaves the scraped profile and its events alone
+	w = serveWithToken(router, http.MethodDelete, fmt.Sprintf("/users/%d", squatter.ID), token, "")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NoError(t, database.DB.First(&data.Organizer{}, scraped.ID).Error)
+	assert.NoError(t, database.DB.First(&data.Event{}, event.ID).Error)
+}
@@ -0,0 +1,119 @@
+package api_tests
+
+import (
+	"backend/api"
+	"backend/data"
+	"backend/database"
+	"backend/taxonomy"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupCategoryFixture() *gin.Engine {
+	database.DB = setupCommentTestDB()
+	router := setupAuthRouter()
+	router.GET("/categories", api.GetCategories)
+	router.GET("/categories/:slug/events", api.GetCategoryEvents)
+
+	events := []struct {
+		source string
+		event  data.Event
+	}{
+		{data.SourceVisitGainesville, data.Event{Name: "Jazz Night", Category: "Jazz"}},
+		{data.SourceGainesvilleSun, data.Event{Name: "Open Mic", Category: "Music"}},
+		{data.SourceGainesvilleSun, data.Event{Name: "Stargazing", Category: "Astrology"}},
+		{data.SourceVisitGainesville, data.Event{Name: "Hidden Gig", Category: "Live Music"}},
+	}
+	for i := range events {
+		taxonomy.Apply(database.DB, events[i].source, &events[i].event)
+		database.DB.Create(&events[i].event)
+	}
+	database.DB.Model(&events[3].event).Update("active", false)
+	return router
+}
+
+func findCategory(categories []data.CategoryDTO, slug string) *data.CategoryDTO {
+	for i := range categories {
+		if categories[i].Slug == slug {
+			return &categories[i]
+		}
+		if found := findCategory(categories[i].Children, slug); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func TestGetCategories(t *testing.T) {
+	router := setupCategoryFixture()
+
+	w := serveWithToken(router, http.MethodGet, "/categories", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var categories []data.CategoryDTO
+	json.Unmarshal(w.Body.Bytes(), &categories)
+
+	music := findCategory(categories, "music")
+	if assert.NotNil(t, music) {
+		assert.Equal(t, int64(2), music.Count)
+		assert.NotNil(t, findCategory(music.Children, "jazz-blues"))
+	}
+	assert.Equal(t, int64(1), findCategory(categories, "jazz-blues").Count)
+	assert.Equal(t, int64(0), findCategory(categories, "live-music").Count)
+	assert.Equal(t, int64(1), findCategory(categories, data.UncategorizedSlug).Count)
+	assert.Nil(t, findCategory(categories[0].Children, "music"))
+}
+
+func TestGetCategoryEvents(t *testing.T) {
+	router := setupCategoryFixture()
+
+	w := serveWithToken(router, http.MethodGet, "/categories/music/events", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var events []data.EventDTO
+	json.Unmarshal(w.Body.Bytes(), &events)
+	assert.ElementsMatch(t, []string{"Jazz Night", "Open Mic"}, eventNames(events))
+	for _, event := range events {
+		if event.Name == "Jazz Night" && assert.NotNil(t, event.CategoryRef) {
+			assert.Equal(t, "jazz-blues", event.CategoryRef.Slug)
+			assert.Equal(t, "Jazz", event.Category)
+		}
+	}
+
+	w = serveWithToken(router, http.MethodGet, "/categories/"+data.UncategorizedSlug+"/events", "", "")
+	json.Unmarshal(w.Body.Bytes(), &events)
+	assert.Equal(t, []string{"Stargazing"}, eventNames(events))
+
+	w = serveWithToken(router, http.MethodGet, "/categories/nope/events", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestMigrate_CategorizesEvents(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, db.AutoMigrate(&data.Event{}))
+
+	theatre := data.Event{Name: "Play", Category: "Theatre"}
+	support := data.Event{Name: "Group", Category: "Support Groups"}
+	blank := data.Event{Name: "Blank"}
+	for _, event := range []*data.Event{&theatre, &support, &blank} {
+		db.Create(event)
+	}
+
+	assert.NoError(t, database.Migrate(db))
+
+	slugOf := func(id uint) string {
+		var event data.Event
+		db.Preload("CategoryRef").First(&event, id)
+		if event.CategoryRef == nil {
+			return ""
+		}
+		return event.CategoryRef.Slug
+	}
+	assert.Equal(t, "theatre", slugOf(theatre.ID))
+	assert.Equal(t, "support-groups", slugOf(support.ID))
+	assert.Equal(t, data.UncategorizedSlug, slugOf(blank.ID))
+}
@@ -0,0 +1,322 @@
+package api_tests
+
+import (
+	"backend/api"
+	"backend/auth"
+	"backend/data"
+	"backend/database"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupCommentTestDB() *gorm.DB {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	database.Migrate(db)
+	return db
+}
+
+func setupCommentRouter() *gin.Engine {
+	router := setupAuthRouter()
+	router.GET("/events/:event_id/GetAllComments", api.GetAllComments)
+	authed := router.Group("/", api.RequireAuth())
+	authed.POST("/events/:id/comments", api.AddCommentToEvent)
+	authed.PUT("/comments/:id", api.AuthorizeCommentParam("id"), api.EditComment)
+	authed.DELETE("/comments/:id", api.AuthorizeCommentParam("id"), api.DeleteComment)
+	return router
+}
+
+func TestAddCommentToEvent(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := setupCommentTestDB()
+	database.DB = db
+
+	// Create a test event and author
+	event := data.Event{Name: "Test Event"}
+	db.Create(&event)
+	user := data.User{Name: "John Doe", Email: "john@example.com"}
+	db.Create(&user)
+
+	commentJSON, _ := json.Marshal(map[string]any{"user_id": user.ID, "content": "This is a great event!"})
+	req, _ := http.NewRequest(http.MethodPost, "/events/"+strconv.FormatUint(uint64(event.ID), 10)+"/comments", bytes.NewBuffer(commentJSON))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: strconv.FormatUint(uint64(event.ID), 10)}}
+	c.Request = req
+	api.SetPrincipal(c, &api.Principal{Kind: auth.KindUser, ID: user.ID, Role: data.RoleUser})
+
+	// Call the function
+	api.AddCommentToEvent(c)
+
+	// Check the response
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Message string          `json:"message"`
+		Comment data.CommentDTO `json:"comment"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "Comment added successfully", response.Message)
+	assert.Equal(t, "John Doe", response.Comment.UserName)
+
+	// The comment is its own row, not part of the event
+	var comments []data.Comment
+	db.Where("event_id = ?", event.ID).Find(&comments)
+	assert.Len(t, comments, 1)
+	assert.Equal(t, user.ID, *comments[0].UserID)
+	assert.Equal(t, "This is a great event!", comments[0].Content)
+	assert.False(t, comments[0].CreatedAt.IsZero())
+}
+
+func TestGetAllComments_Paginates(t *testing.T) {
+	database.DB = setupCommentTestDB()
+	router := setupCommentRouter()
+
+	event := data.Event{Name: "Test Event"}
+	database.DB.Create(&event)
+	alice := data.User{Name: "Alice", Email: "alice@example.com"}
+	database.DB.Create(&alice)
+
+	start := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		database.DB.Create(&data.Comment{EventID: event.ID, UserID: &alice.ID, Content: fmt.Sprintf("Comment %d", i), CreatedAt: start.Add(time.Duration(i) * time.Minute)})
+	}
+	// A comment whose author has since been deleted
+	database.DB.Create(&data.Comment{EventID: event.ID, Content: "Orphaned", CreatedAt: start.Add(time.Hour)})
+
+	w := serveWithToken(router, http.MethodGet, fmt.Sprintf("/events/%d/GetAllComments?page=2&limit=2", event.ID), "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Comments []data.CommentDTO `json:"comments"`
+		Total    int               `json:"total"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 6, response.Total)
+	assert.Len(t, response.Comments, 2)
+	assert.Equal(t, "Comment 2", response.Comments[0].Content)
+	assert.Equal(t, "Alice", response.Comments[0].UserName)
+
+	w = serveWithToken(router, http.MethodGet, fmt.Sprintf("/events/%d/GetAllComments?page=3&limit=2", event.ID), "", "")
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "Deleted User", response.Comments[1].UserName)
+	assert.Nil(t, response.Comments[1].UserID)
+}
+
+func TestEditAndDeleteComment_AuthorOrModerator(t *testing.T) {
+	database.DB = setupCommentTestDB()
+	router := setupCommentRouter()
+
+	event := data.Event{Name: "Test Event"}
+	database.DB.Create(&event)
+	author := data.User{Name: "Author", Email: "author@example.com", Password: "pw"}
+	other := data.User{Name: "Other", Email: "other@example.com", Password: "pw"}
+	moderator := data.User{Name: "Mod", Email: "mod@example.com", Password: "pw", Role: data.RoleModerator}
+	database.DB.Create(&author)
+	database.DB.Create(&other)
+	database.DB.Create(&moderator)
+	comment := data.Comment{EventID: event.ID, UserID: &author.ID, Content: "Original"}
+	database.DB.Create(&comment)
+
+	authorToken := login(t, router, "/LoginUser", author.Email, "pw")["token"].(string)
+	otherToken := login(t, router, "/LoginUser", other.Email, "pw")["token"].(string)
+	modToken := login(t, router, "/LoginUser", moderator.Email, "pw")["token"].(string)
+	path := fmt.Sprintf("/comments/%d", comment.ID)
+
+	w := serveWithToken(router, http.MethodPut, path, otherToken, `{"content":"Hijacked"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serveWithToken(router, http.MethodPut, path, authorToken, `{"content":"Edited"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var stored data.Comment
+	database.DB.First(&stored, comment.ID)
+	assert.Equal(t, "Edited", stored.Content)
+
+	w = serveWithToken(router, http.MethodDelete, path, otherToken, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serveWithToken(router, http.MethodDelete, path, modToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Error(t, database.DB.First(&data.Comment{}, comment.ID).Error)
+
+	w = serveWithToken(router, http.MethodDelete, path, authorToken, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCommentReplies_NestAndLimitDepth(t *testing.T) {
+	database.DB = setupCommentTestDB()
+	router := setupCommentRouter()
+
+	event := data.Event{Name: "Test Event"}
+	database.DB.Create(&event)
+	alice := data.User{Name: "Alice", Email: "alice@example.com", Password: "pw"}
+	database.DB.Create(&alice)
+	token := login(t, router, "/LoginUser", alice.Email, "pw")["token"].(string)
+	path := fmt.Sprintf("/events/%d/comments", event.ID)
+
+	post := func(body string) (int, data.CommentDTO) {
+		w := serveWithToken(router, http.MethodPost, path, token, body)
+		var response struct {
+			Comment data.CommentDTO `json:"comment"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response.Comment
+	}
+
+	// Build a thread as deep as allowed
+	code, parent := post(`{"content":"Root"}`)
+	assert.Equal(t, http.StatusOK, code)
+	root := parent
+	for depth := 1; depth <= data.MaxCommentDepth; depth++ {
+		code, parent = post(fmt.Sprintf(`{"content":"Reply %d","parent_id":%d}`, depth, parent.ID))
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, uint(depth), parent.Depth)
+	}
+	post(fmt.Sprintf(`{"content":"Second reply","parent_id":%d}`, root.ID))
+
+	// One more level is rejected
+	code, _ = post(fmt.Sprintf(`{"content":"Too deep","parent_id":%d}`, parent.ID))
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// A parent on another event is rejected
+	other := data.Event{Name: "Other Event"}
+	database.DB.Create(&other)
+	w := serveWithToken(router, http.MethodPost, fmt.Sprintf("/events/%d/comments", other.ID), token, fmt.Sprintf(`{"content":"Wrong event","parent_id":%d}`, root.ID))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response struct {
+		Comments []data.CommentDTO `json:"comments"`
+		Total    int               `json:"total"`
+	}
+	w = serveWithToken(router, http.MethodGet, fmt.Sprintf("/events/%d/GetAllComments", event.ID), "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, data.MaxCommentDepth+1, response.Comments[0].ReplyCount)
+	assert.Len(t, response.Comments[0].Replies, 2)
+	assert.Equal(t, "Reply 1", response.Comments[0].Replies[0].Content)
+	assert.Equal(t, "Second reply", response.Comments[0].Replies[1].Content)
+
+	// Limiting the depth trims the tree but keeps the counts
+	w = serveWithToken(router, http.MethodGet, fmt.Sprintf("/events/%d/GetAllComments?depth=1", event.ID), "", "")
+	json.Unmarshal(w.Body.Bytes(), &response)
+	firstReply := response.Comments[0].Replies[0]
+	assert.Empty(t, firstReply.Replies)
+	assert.Equal(t, data.MaxCommentDepth-1, firstReply.ReplyCount)
+}
+
+func TestCommentMentions_ResolveToUsers(t *testing.T) {
+	database.DB = setupCommentTestDB()
+	router := setupCommentRouter()
+
+	event := data.Event{Name: "Test Event"}
+	database.DB.Create(&event)
+	alice := data.User{Name: "Alice", Email: "alice@example.com", Password: "pw"}
+	bob := data.User{Name: "Bob Smith", Email: "bsmith@example.com"}
+	carol := data.User{Name: "Carol", Email: "cj@example.com"}
+	database.DB.Create(&alice)
+	database.DB.Create(&bob)
+	database.DB.Create(&carol)
+	token := login(t, router, "/LoginUser", alice.Email, "pw")["token"].(string)
+
+	body := `{"content":"@bobsmith and @CJ, see you there! Mail dave@example.com, not @nobody."}`
+	w := serveWithToken(router, http.MethodPost, fmt.Sprintf("/events/%d/comments", event.ID), token, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Comment data.CommentDTO `json:"comment"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, []data.MentionDTO{{UserID: bob.ID, Name: "Bob Smith"}, {UserID: carol.ID, Name: "Carol"}}, response.Comment.Mentions)
+
+	// Editing the text re-resolves the mentions
+	w = serveWithToken(router, http.MethodPut, fmt.Sprintf("/comments/%d", response.Comment.ID), token, `{"content":"Only @carol now"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var mentions []data.CommentMention
+	database.DB.Where("comment_id = ?", response.Comment.ID).Find(&mentions)
+	assert.Len(t, mentions, 1)
+	assert.Equal(t, carol.ID, mentions[0].UserID)
+
+	// A handle naming several users notifies none of them
+	database.DB.Create(&data.User{Name: "Jon Doe", Email: "john@one.example"})
+	database.DB.Create(&data.User{Name: "J Smith", Email: "john@two.example"})
+	w = serveWithToken(router, http.MethodPut, fmt.Sprintf("/comments/%d", response.Comment.ID), token, `{"content":"@john, and @carol"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	database.DB.Where("comment_id = ?", response.Comment.ID).Find(&mentions)
+	assert.Len(t, mentions, 1)
+	assert.Equal(t, carol.ID, mentions[0].UserID)
+}
+
+func TestDeleteComment_KeepsRepliesReadable(t *testing.T) {
+	database.DB = setupCommentTestDB()
+	router := setupCommentRouter()
+
+	event := data.Event{Name: "Test Event"}
+	database.DB.Create(&event)
+	alice := data.User{Name: "Alice", Email: "alice@example.com", Password: "pw"}
+	database.DB.Create(&alice)
+	token := login(t, router, "/LoginUser", alice.Email, "pw")["token"].(string)
+
+	root := data.Comment{EventID: event.ID, UserID: &alice.ID, Content: "Root"}
+	database.DB.Create(&root)
+	reply := data.Comment{EventID: event.ID, UserID: &alice.ID, ParentID: &root.ID, Depth: 1, Content: "Reply"}
+	database.DB.Create(&reply)
+
+	// The root still has a reply, so it is blanked rather than removed
+	w := serveWithToken(router, http.MethodDelete, fmt.Sprintf("/comments/%d", root.ID), token, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var stored data.Comment
+	assert.NoError(t, database.DB.First(&stored, root.ID).Error)
+	assert.True(t, stored.Deleted)
+	assert.Empty(t, stored.Content)
+
+	// Removing the last reply clears the placeholder too
+	w = serveWithToken(router, http.MethodDelete, fmt.Sprintf("/comments/%d", reply.ID), token, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var remaining int64
+	database.DB.Model(&data.Comment{}).Where("event_id = ?", event.ID).Count(&remaining)
+	assert.Zero(t, remaining)
+}
+
+func TestMigrate_MovesCommentBlobsToTable(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	// Recreate the old layout: comments stored as JSON on each event
+	db.AutoMigrate(&data.User{}, &data.Event{})
+	db.Exec("ALTER TABLE events ADD COLUMN `comments` text")
+	user := data.User{Name: "Alice", Email: "alice@example.com"}
+	db.Create(&user)
+	event := data.Event{Name: "Legacy Event"}
+	db.Create(&event)
+	blob := fmt.Sprintf(`[{"event_id":%d,"user_id":%d,"user_name":"Alice","content":"Great event!","created_at":"April 2, 2025 3:04 PM","likes":2},
+		{"event_id":%d,"user_id":999,"user_name":"Gone","content":"Had a fantastic time!","created_at":"April 3, 2025 9:00 AM","likes":0}]`, event.ID, user.ID, event.ID)
+	db.Exec("UPDATE events SET comments = ? WHERE id = ?", blob, event.ID)
+
+	assert.NoError(t, database.Migrate(db))
+
+	var comments []data.Comment
+	db.Order("id").Find(&comments)
+	assert.Len(t, comments, 2)
+	assert.Equal(t, "Great event!", comments[0].Content)
+	assert.Equal(t, user.ID, *comments[0].UserID)
+	assert.Zero(t, comments[0].Likes) // Anonymous like counts are dropped once likes are tracked per user
+	assert.Equal(t, 2025, comments[0].CreatedAt.Year())
+	assert.Nil(t, comments[1].UserID)
+	assert.False(t, db.Migrator().HasColumn(&data.Event{}, "comments"))
+
+	// Running again is a no-op
+	assert.NoError(t, database.Migrate(db))
+	var count int64
+	db.Model(&data.Comment{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
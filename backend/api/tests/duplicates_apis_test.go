@@ -0,0 +1,95 @@
+package api_tests
+
+import (
+	"backend/data"
+	"backend/database"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminDuplicates(t *testing.T) {
+	router := setupAdminRouter()
+	user, moderator, admin := setupAdminTestDB()
+	modToken := login(t, router, "/LoginUser", moderator.Email, "pw")["token"].(string)
+
+	event := data.Event{Name: "Jazz Night at the Depot", Description: "Jazz.", Source: "gainesville_sun"}
+	duplicate := data.Event{Name: "Jazz Night", Description: "An evening of jazz.", ImageURL: "https://example.com/jazz.jpg", Source: "visit_gainesville"}
+	merged := data.Event{Name: "Jazz Night (old listing)"}
+	poetry := data.Event{Name: "Poetry Slam"}
+	database.DB.Create(&event)
+	database.DB.Create(&duplicate)
+	database.DB.Create(&merged)
+	database.DB.Create(&poetry)
+	database.DB.Model(&merged).Updates(map[string]interface{}{"duplicate_of_id": duplicate.ID, "active": false})
+
+	// Both users liked the duplicate, one of them the event too
+	database.DB.Create(&data.Like{UserID: user.ID, TargetType: data.LikeTargetEvent, TargetID: event.ID})
+	database.DB.Create(&data.Like{UserID: user.ID, TargetType: data.LikeTargetEvent, TargetID: duplicate.ID})
+	database.DB.Create(&data.Like{UserID: admin.ID, TargetType: data.LikeTargetEvent, TargetID: duplicate.ID})
+	database.DB.Create(&data.Comment{EventID: duplicate.ID, UserID: &user.ID, Content: "See you there"})
+
+	pair := data.DuplicateCandidate{EventID: event.ID, DuplicateID: duplicate.ID, Score: 0.88, Status: data.CandidatePending}
+	unrelated := data.DuplicateCandidate{EventID: event.ID, DuplicateID: poetry.ID, Score: 0.66, Status: data.CandidatePending}
+	database.DB.Create(&pair)
+	database.DB.Create(&unrelated)
+
+	w := serveWithToken(router, http.MethodGet, "/admin/duplicates", modToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var queue []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	if assert.Len(t, queue, 2) {
+		assert.EqualValues(t, unrelated.ID, queue[0]["id"])
+		assert.Equal(t, "Poetry Slam", queue[0]["duplicate"].(map[string]any)["name"])
+		assert.Equal(t, "Jazz Night at the Depot", queue[1]["event"].(map[string]any)["name"])
+	}
+
+	w = serveWithToken(router, http.MethodPost, fmt.Sprintf("/admin/duplicates/%d/merge", pair.ID), modToken, "")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var kept, hidden, relinked data.Event
+	database.DB.First(&kept, event.ID)
+	database.DB.First(&hidden, duplicate.ID)
+	database.DB.First(&relinked, merged.ID)
+	assert.Equal(t, "Jazz Night at the Depot", kept.Name)
+	assert.Equal(t, "An evening of jazz.", kept.Description)
+	assert.Equal(t, "https://example.com/jazz.jpg", kept.ImageURL)
+	assert.EqualValues(t, 2, kept.Likes)
+	assert.False(t, hidden.Active)
+	assert.EqualValues(t, 0, hidden.Likes)
+	assert.Equal(t, event.ID, *hidden.DuplicateOfID)
+	assert.Equal(t, event.ID, *relinked.DuplicateOfID)
+	var comments int64
+	database.DB.Model(&data.Comment{}).Where("event_id = ?", event.ID).Count(&comments)
+	assert.EqualValues(t, 1, comments)
+
+	// A pair is reviewed once
+	w = serveWithToken(router, http.MethodPost, fmt.Sprintf("/admin/duplicates/%d/merge", pair.ID), modToken, "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = serveWithToken(router, http.MethodPost, fmt.Sprintf("/admin/duplicates/%d/distinct", unrelated.ID), modToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	database.DB.First(&unrelated, unrelated.ID)
+	assert.Equal(t, data.CandidateDistinct, unrelated.Status)
+	assert.Equal(t, moderator.ID, *unrelated.ResolvedBy)
+	database.DB.First(&poetry, poetry.ID)
+	assert.True(t, poetry.Active)
+	assert.Nil(t, poetry.DuplicateOfID)
+
+	w = serveWithToken(router, http.MethodGet, "/admin/duplicates", modToken, "")
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	assert.Empty(t, queue)
+	w = serveWithToken(router, http.MethodGet, "/admin/duplicates?status=merged", modToken, "")
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	assert.Len(t, queue, 1)
+
+	for _, path := range []string{"/admin/duplicates?status=maybe", "/admin/duplicates?limit=0"} {
+		w = serveWithToken(router, http.MethodGet, path, modToken, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+	}
+	w = serveWithToken(router, http.MethodPost, "/admin/duplicates/999/distinct", modToken, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -2,6 +2,7 @@ package api_tests
 
 import (
 	"backend/api"
+	"backend/auth"
 	"backend/data"
 	"backend/database"
 	"bytes"
@@ -12,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -21,7 +23,7 @@ import (
 
 func setupTestDB() *gorm.DB {
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	db.AutoMigrate(&data.Event{}, &data.Comment{}, &data.User{})
+	db.AutoMigrate(&data.Event{}, &data.Comment{}, &data.CommentMention{}, &data.Like{}, &data.User{}, &data.Tag{}, &data.TagSynonym{}, &data.Category{}, &data.CategoryMapping{}, &data.DuplicateCandidate{})
 	return db
 }
 
@@ -35,8 +37,8 @@ func TestCreateEvent(t *testing.T) {
 	user := data.User{ID: 3, Name: "John Doe", Email: "johndoe@example.com", Password: "securepassword"}
 	db.Create(&user)
 
-	// Ensure organizer exists
-	organizer := data.Organizer{ID: 3, Name: "John Doe", Email: "johndoe@example.com", ContactDetails: "3534444444"}
+	// Ensure the user's organizer profile exists
+	organizer := data.Organizer{ID: 3, Name: "John Doe", Email: "johndoe@example.com", ContactDetails: "3534444444", UserID: &user.ID}
 	db.Create(&organizer)
 
 	// Prepare event data
@@ -54,6 +56,12 @@ func TestCreateEvent(t *testing.T) {
 		"website":          "https://techconference2025.com",
 		"max_participants": 500,
 		"contact_details":  "3534444444",
+		"source":           "gainesville_sun", // Ignored: the API decides the provenance
+		"external_id":      "12345",
+		"likes":            999, // Ignored: likes, ratings and bookkeeping are the server's
+		"rating":           5,
+		"duplicate_of_id":  1,
+		"created_at":       "2000-01-01T00:00:00Z",
 	}
 
 	eventJSON, _ := json.Marshal(eventData)
@@ -65,9 +73,10 @@ func TestCreateEvent(t *testing.T) {
 	// Create response recorder
 	w := httptest.NewRecorder()
 
-	// Create Gin context
+	// Create Gin context, authenticated as the user creating the event
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
+	api.SetPrincipal(c, &api.Principal{Kind: auth.KindUser, ID: user.ID, Role: data.RoleUser})
 
 	// Call API
 	api.CreateEvent(c)
@@ -93,7 +102,23 @@ func TestCreateEvent(t *testing.T) {
 	// Validate event fields
 	assert.Equal(t, "Tech Summit 2026", dbEvent.Name)
 	assert.Equal(t, "New York, NY", dbEvent.Location)
-	assert.Equal(t, "June 15, 10:00 AM - 12:00 PM", dbEvent.Date)
+	assert.Equal(t, "June 15, 2025, 10:00 AM - 12:00 PM", dbEvent.Date)
+	assert.Equal(t, "2025-06-15T14:00:00Z", dbEvent.StartsAt.UTC().Format(time.RFC3339))
+	assert.Equal(t, "2025-06-15T16:00:00Z", dbEvent.EndsAt.UTC().Format(time.RFC3339))
+	assert.False(t, dbEvent.AllDay)
+	assert.Equal(t, data.SourceUser, dbEvent.Source)
+	assert.Empty(t, dbEvent.ExternalID)
+	assert.NotNil(t, dbEvent.FirstSeenAt)
+	assert.Zero(t, dbEvent.Likes)
+	assert.Zero(t, dbEvent.Rating)
+	assert.Nil(t, dbEvent.DuplicateOfID)
+	assert.WithinDuration(t, time.Now(), dbEvent.CreatedAt, time.Minute)
+
+	// The response has the shape of GetEventByID's, in Gainesville time
+	assert.Equal(t, "2025-06-15T10:00:00-04:00", response["starts_at"])
+	assert.Equal(t, dbEvent.Date, response["date"])
+	assert.NotEmpty(t, response["display_date"])
+	assert.Equal(t, "johndoe@example.com", response["organizer"].(map[string]interface{})["email"])
 
 	// Fix float64 type conversion for organizer_id
 	receivedOrganizerID := int(response["organizer_id"].(float64))
@@ -104,6 +129,71 @@ func TestCreateEvent(t *testing.T) {
 	assert.Equal(t, 500, maxParticipants)
 }
 
+func TestCreateEvent_OrganizerKeepsNoPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database.DB = setupTestDB()
+	database.DB.AutoMigrate(&data.Organizer{})
+	user := data.User{Name: "John Doe", Email: "johndoe@example.com", Password: "securepassword"}
+	database.DB.Create(&user)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/CreateEvent", strings.NewReader(`{"name":"Gala","date":"2025-06-15"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	api.SetPrincipal(c, &api.Principal{Kind: auth.KindUser, ID: user.ID, Role: data.RoleUser})
+	api.CreateEvent(c)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// The user's credentials stay on the account; the profile is reached through the link
+	var organizer data.Organizer
+	database.DB.Where("user_id = ?", user.ID).First(&organizer)
+	assert.Equal(t, "johndoe@example.com", organizer.Email)
+	assert.Empty(t, organizer.Password)
+}
+
+func TestCreateEvent_PricesOnServer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database.DB = setupTestDB()
+	database.DB.AutoMigrate(&data.Organizer{})
+	user := data.User{Name: "John Doe", Email: "johndoe@example.com", Password: "securepassword"}
+	database.DB.Create(&user)
+
+	create := func(body string) (int, data.Event) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/CreateEvent", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		api.SetPrincipal(c, &api.Principal{Kind: auth.KindUser, ID: user.ID, Role: data.RoleUser})
+		api.CreateEvent(c)
+
+		var event data.Event
+		var response map[string]interface{}
+		if json.Unmarshal(w.Body.Bytes(), &response) == nil && response["id"] != nil {
+			database.DB.First(&event, int(response["id"].(float64)))
+		}
+		return w.Code, event
+	}
+
+	// A free flag sent with a price is not believed
+	code, event := create(`{"name":"Gala","date":"2025-06-15","cost":50,"free":true,"currency":"EUR"}`)
+	assert.Equal(t, http.StatusCreated, code)
+	assert.False(t, event.Free)
+	assert.Equal(t, 50.0, event.MaxCost)
+	assert.Equal(t, "USD", event.Currency)
+
+	// A cost text, when given, decides the price
+	code, event = create(`{"name":"Open Studio","date":"2025-06-15","cost_text":"Free for members, $10 otherwise","cost":99}`)
+	assert.Equal(t, http.StatusCreated, code)
+	assert.True(t, event.Free)
+	assert.Zero(t, event.Cost)
+	assert.Equal(t, 10.0, event.MaxCost)
+
+	for _, body := range []string{`{"name":"Bad","date":"2025-06-15","cost":-1}`, `{"name":"Bad","date":"2025-06-15","cost_text":"See website"}`} {
+		code, _ = create(body)
+		assert.Equal(t, http.StatusBadRequest, code, body)
+	}
+}
+
 func TestGetAllEvents(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -176,6 +266,41 @@ func TestEditEvent(t *testing.T) {
 	assert.Equal(t, updatedEvent.Name, dbEvent.Name)
 }
 
+func TestEditEvent_StoresDatesAsCreateDoes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database.DB = setupTestDB()
+	event := data.Event{Name: "Test Event"}
+	database.DB.Create(&event)
+
+	edit := func(body string) (int, data.Event) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{gin.Param{Key: "id", Value: strconv.FormatUint(uint64(event.ID), 10)}}
+		c.Request, _ = http.NewRequest(http.MethodPut, "/EditEvent/"+c.Params[0].Value, strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		api.EditEvent(c)
+
+		var stored data.Event
+		database.DB.First(&stored, event.ID)
+		return w.Code, stored
+	}
+
+	code, stored := edit(`{"name":"Test Event","date":"2025-07-04","time":"7:00 PM"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "July 4, 2025, 7:00 PM", stored.Date)
+	assert.Equal(t, "2025-07-04T23:00:00Z", stored.StartsAt.UTC().Format(time.RFC3339))
+
+	// The stored date can be sent back as it is
+	code, stored = edit(`{"name":"Renamed","date":"July 4, 2025, 7:00 PM"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "July 4, 2025, 7:00 PM", stored.Date)
+	assert.Equal(t, "Renamed", stored.Name)
+	assert.NotNil(t, stored.StartsAt)
+
+	code, _ = edit(`{"name":"Renamed","date":"July 5, 2025"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
 func TestDeleteEvent(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -212,62 +337,6 @@ func TestDeleteEvent(t *testing.T) {
 	assert.Error(t, result.Error)
 }
 
-func TestAddCommentToEvent(t *testing.T) {
-    // Setup
-    gin.SetMode(gin.TestMode)
-    db := setupTestDB()
-    database.DB = db
-
-    // Create a test event
-    event := data.Event{Name: "Test Event"}
-    db.Create(&event)
-
-    // Create a test comment
-    comment := data.Comment{
-        UserID:   2,
-        UserName: "John Doe",
-        Content:  "This is a great event!",
-    }
-    commentJSON, _ := json.Marshal(comment)
-
-    // Create a request
-    req, _ := http.NewRequest(http.MethodPost, "/events/"+strconv.FormatUint(uint64(event.ID), 10)+"/comments", bytes.NewBuffer(commentJSON))
-    req.Header.Set("Content-Type", "application/json")
-
-    // Create a response recorder
-    w := httptest.NewRecorder()
-
-    // Create a Gin context
-    c, _ := gin.CreateTestContext(w)
-    c.Params = gin.Params{gin.Param{Key: "id", Value: strconv.FormatUint(uint64(event.ID), 10)}}
-    c.Request = req
-
-    // Call the function
-    api.AddCommentToEvent(c)
-
-    // Check the response
-    assert.Equal(t, http.StatusOK, w.Code)
-    var response map[string]string
-    json.Unmarshal(w.Body.Bytes(), &response)
-    assert.Equal(t, "Comment added successfully", response["message"])
-
-    // Check the database for the updated event
-    var dbEvent data.Event
-    db.First(&dbEvent, event.ID)
-
-    // Unmarshal comments from JSON string
-    var comments []data.Comment
-    if err := json.Unmarshal([]byte(dbEvent.Comments), &comments); err != nil {
-        t.Fatalf("Error unmarshaling comments: %v", err)
-    }
-
-    // Assert that the comment was added
-    assert.Len(t, comments, 1)
-    assert.Equal(t, comment.UserID, comments[0].UserID)
-    assert.Equal(t, comment.UserName, comments[0].UserName)
-    assert.Equal(t, comment.Content, comments[0].Content)
-}
-
 /////////////////////////////////////////////////////////////////////////
 
 func TestMapUserToEvent_Success(t *testing.T) {
@@ -293,9 +362,10 @@ func TestMapUserToEvent_Success(t *testing.T) {
 	// Create a response recorder
 	w := httptest.NewRecorder()
 
-	// Create a Gin context
+	// Create a Gin context, authenticated as the user being mapped
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
+	api.SetPrincipal(c, &api.Principal{Kind: auth.KindUser, ID: user.ID, Role: data.RoleUser})
 
 	// Call the function
 	api.MapUserToEvent(c)
@@ -326,6 +396,15 @@ func TestGetRegisteredEvents_Success(t *testing.T) {
 	db.Create(&event)
 	db.Model(&event).Association("Users").Append(&user) // Map user to event
 
+	// Unpublished events and merged duplicates are not listed
+	unpublished := data.Event{Name: "Unpublished Event"}
+	duplicate := data.Event{Name: "Merged Event", DuplicateOfID: &event.ID}
+	for _, hidden := range []*data.Event{&unpublished, &duplicate} {
+		db.Create(hidden)
+		db.Model(hidden).Update("active", false)
+		db.Model(hidden).Association("Users").Append(&user)
+	}
+
 	// Create a request
 	req, _ := http.NewRequest(http.MethodGet, "/user/"+strconv.FormatUint(uint64(user.ID), 10)+"/GetUserRegisteredEvents", nil)
 
@@ -365,9 +444,11 @@ func TestUnmapUserFromEvent(t *testing.T) {
 	// Associate user with event
 	testDB.Model(&event).Association("Users").Append(&user)
 
-	// Create a test router
+	// Create a test router, authenticated as the user being unmapped
 	router := gin.Default()
-	router.POST("/unmapUserFromEvent", api.UnmapUserFromEvent)
+	router.POST("/unmapUserFromEvent", func(c *gin.Context) {
+		api.SetPrincipal(c, &api.Principal{Kind: auth.KindUser, ID: user.ID, Role: data.RoleUser})
+	}, api.UnmapUserFromEvent)
 
 	// Prepare the JSON payload
 	requestBody, _ := json.Marshal(map[string]interface{}{
@@ -397,38 +478,6 @@ func TestUnmapUserFromEvent(t *testing.T) {
 }
 
 
-func TestGetAllComments(t *testing.T) {
-	db := setupTestDB()
-	database.DB = db
-
-	router := gin.Default()
-	router.GET("/events/:event_id/GetAllComments", api.GetAllComments)
-
-	// Create a test event with comments
-	event := data.Event{
-		ID:       11,
-		Comments: `[{"user":"Alice","message":"Great event!"}, {"user":"Bob","message":"Had a fantastic time!"}]`,
-	}
-	db.Create(&event)
-
-	// Create request
-	req, _ := http.NewRequest("GET", "/events/11/GetAllComments", nil)
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-
-	// Check response
-	assert.Equal(t, http.StatusOK, w.Code)
-
-	var response map[string][]map[string]interface{}
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.NoError(t, err)
-	assert.Len(t, response["comments"], 2)
-	assert.Equal(t, "Alice", response["comments"][0]["user"])
-	assert.Equal(t, "Great event!", response["comments"][0]["message"])
-	assert.Equal(t, "Bob", response["comments"][1]["user"])
-	assert.Equal(t, "Had a fantastic time!", response["comments"][1]["message"])
-}
-
 func TestGetUsersByEvent_Success(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -0,0 +1,68 @@
+package api_tests
+
+import (
+	"backend/api"
+	"backend/data"
+	"backend/database"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMigrate_BackfillsEventDates(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, db.AutoMigrate(&data.Event{}))
+
+	// Rows as each source used to store them, before StartsAt existed
+	visit := data.Event{Name: "Visit Gainesville", Date: "2025-04-29 20:00:00 - 2025-04-29 23:00:00"}
+	sun := data.Event{Name: "Gainesville Sun", Date: "2025-04-26"}
+	created := data.Event{Name: "API", Date: "June 15, 2025, 10:00 AM - 12:00 PM", Time: "10:00 AM - 12:00 PM"}
+	unknown := data.Event{Name: "Unknown", Date: "TBA"}
+	for _, event := range []*data.Event{&visit, &sun, &created, &unknown} {
+		db.Create(event)
+	}
+
+	assert.NoError(t, database.Migrate(db))
+
+	stored := func(id uint) (event data.Event) {
+		db.First(&event, id)
+		return
+	}
+
+	visitRow := stored(visit.ID)
+	assert.Equal(t, "2025-04-30T00:00:00Z", visitRow.StartsAt.UTC().Format(time.RFC3339))
+	assert.Equal(t, "2025-04-30T03:00:00Z", visitRow.EndsAt.UTC().Format(time.RFC3339))
+
+	sunRow := stored(sun.ID)
+	assert.True(t, sunRow.AllDay)
+	assert.Equal(t, "2025-04-26T04:00:00Z", sunRow.StartsAt.UTC().Format(time.RFC3339))
+
+	assert.Equal(t, "2025-06-15T14:00:00Z", stored(created.ID).StartsAt.UTC().Format(time.RFC3339))
+	assert.Nil(t, stored(unknown.ID).StartsAt)
+}
+
+func TestGetEvent_IncludesISOAndDisplayDates(t *testing.T) {
+	database.DB = setupCommentTestDB()
+	router := setupAuthRouter()
+	router.GET("/GetEvent/:id", api.GetEventByID)
+
+	start := time.Date(2025, 12, 5, 19, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	event := data.Event{Name: "Winter Concert", Date: "2025-12-05", StartsAt: &start, EndsAt: &end}
+	database.DB.Create(&event)
+
+	w := serveWithToken(router, http.MethodGet, fmt.Sprintf("/GetEvent/%d", event.ID), "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]any
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "2025-12-05T14:00:00-05:00", response["starts_at"])
+	assert.Equal(t, "2025-12-05T16:00:00-05:00", response["ends_at"])
+	assert.Equal(t, "Friday, December 5, 2025, 2:00 PM – 4:00 PM", response["display_date"])
+	assert.Equal(t, "2025-12-05", response["date"])
+}
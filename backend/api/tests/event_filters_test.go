@@ -0,0 +1,157 @@
+package api_tests
+
+import (
+	"backend/api"
+	"backend/data"
+	"backend/database"
+	"backend/eventdate"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAllEvents_DateFilters(t *testing.T) {
+	database.DB = setupCommentTestDB()
+	router := setupAuthRouter()
+	router.GET("/GetAllEvents", api.GetAllEvents)
+
+	ny := func(year int, month time.Month, day, hour int) *time.Time {
+		t := time.Date(year, month, day, hour, 0, 0, 0, eventdate.Location)
+		return &t
+	}
+	now := time.Now()
+	ongoingStart, ongoingEnd := now.Add(-time.Hour), now.Add(time.Hour)
+
+	events := []data.Event{
+		{Name: "Past", StartsAt: ny(2020, 1, 1, 19), EndsAt: ny(2020, 1, 1, 21)},
+		{Name: "Future all day", StartsAt: ny(2099, 6, 1, 0), AllDay: true},
+		{Name: "Future evening", StartsAt: ny(2099, 7, 4, 21)},
+		{Name: "Ongoing", StartsAt: &ongoingStart, EndsAt: &ongoingEnd},
+		{Name: "Unparsed date", Date: "TBA"},
+	}
+	for i := range events {
+		database.DB.Create(&events[i])
+	}
+
+	names := func(query string) []string {
+		w := serveWithToken(router, http.MethodGet, "/GetAllEvents"+query, "", "")
+		assert.Equal(t, http.StatusOK, w.Code, query)
+		var dtos []data.EventDTO
+		json.Unmarshal(w.Body.Bytes(), &dtos)
+		result := []string{}
+		for _, dto := range dtos {
+			result = append(result, dto.Name)
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	assert.Len(t, names(""), 5)
+	assert.Equal(t, []string{"Future all day", "Future evening", "Ongoing"}, names("?upcoming=true"))
+	assert.Equal(t, []string{"Past"}, names("?past=true"))
+	assert.Equal(t, []string{"Future all day"}, names("?from=2099-06-01&to=2099-06-30"))
+	assert.Equal(t, []string{"Future evening"}, names("?from=2099-06-02"))
+	assert.Equal(t, []string{"Future all day"}, names("?from=2099-06-01T12:00:00-04:00&to=2099-06-01"))
+	assert.Equal(t, []string{"Past"}, names("?to=2020-01-01"))
+	assert.Contains(t, names("?when=today"), "Ongoing")
+
+	for query, message := range map[string]string{
+		"?upcoming=maybe":                "Invalid upcoming, expected true or false",
+		"?when=someday":                  "Invalid when, expected today or this_weekend",
+		"?upcoming=true&past=true":       "Upcoming and past cannot both be set",
+		"?from=June":                     "Invalid from date",
+		"?from=2099-06-02&to=2099-06-01": "From must be before to",
+	} {
+		w := serveWithToken(router, http.MethodGet, "/GetAllEvents"+query, "", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.JSONEq(t, `{"error":"`+message+`"}`, w.Body.String(), query)
+	}
+}
+
+func TestGetAllEvents_SourceFilter(t *testing.T) {
+	database.DB = setupCommentTestDB()
+	router := setupAuthRouter()
+	router.GET("/GetAllEvents", api.GetAllEvents)
+
+	seen := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	events := []data.Event{
+		{Name: "From the Sun", Source: data.SourceGainesvilleSun, ExternalID: "3008004", SourceURL: "https://discovery.evvnt.com/api/publisher/458/home_page_events?page=0", FirstSeenAt: &seen, LastSeenAt: &seen},
+		{Name: "From Visit Gainesville", Source: data.SourceVisitGainesville, ExternalID: "https://www.visitgainesville.com/event/a/"},
+		{Name: "From a user", Source: data.SourceUser},
+		{Name: "Unknown"},
+	}
+	for i := range events {
+		database.DB.Create(&events[i])
+	}
+
+	get := func(query string) []data.EventDTO {
+		w := serveWithToken(router, http.MethodGet, "/GetAllEvents"+query, "", "")
+		assert.Equal(t, http.StatusOK, w.Code, query)
+		var dtos []data.EventDTO
+		json.Unmarshal(w.Body.Bytes(), &dtos)
+		return dtos
+	}
+
+	dtos := get("?source=gainesville_sun")
+	if assert.Len(t, dtos, 1) {
+		assert.Equal(t, "From the Sun", dtos[0].Name)
+		assert.Equal(t, data.SourceGainesvilleSun, dtos[0].Source)
+		assert.Equal(t, "3008004", dtos[0].ExternalID)
+		assert.Equal(t, events[0].SourceURL, dtos[0].SourceURL)
+		assert.True(t, seen.Equal(*dtos[0].FirstSeenAt))
+		assert.True(t, seen.Equal(*dtos[0].LastSeenAt))
+	}
+	names := eventNames(get("?source=user,visit_gainesville"))
+	sort.Strings(names)
+	assert.Equal(t, []string{"From Visit Gainesville", "From a user"}, names)
+	assert.Len(t, get(""), 4)
+
+	for _, query := range []string{"?source=evvnt", "?source=user,"} {
+		w := serveWithToken(router, http.MethodGet, "/GetAllEvents"+query, "", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestGetAllEvents_CostFilters(t *testing.T) {
+	database.DB = setupCommentTestDB()
+	router := setupAuthRouter()
+	router.GET("/GetAllEvents", api.GetAllEvents)
+	router.GET("/SearchEvents", api.SearchEvents)
+
+	events := []data.Event{
+		{Name: "Market", CostText: "Free", Free: true},
+		{Name: "Concert", CostText: "$15 advance / $20 door", Cost: 15, MaxCost: 20, Currency: "USD"},
+		{Name: "Gala", CostText: "$100", Cost: 100, MaxCost: 100, Currency: "USD"},
+		{Name: "Open Studio", CostText: "Free - $10", MaxCost: 10, Currency: "USD", Free: true},
+		{Name: "Unpriced"},
+	}
+	for i := range events {
+		database.DB.Create(&events[i])
+	}
+
+	names := func(path string) []string {
+		w := serveWithToken(router, http.MethodGet, path, "", "")
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		var dtos []data.EventDTO
+		json.Unmarshal(w.Body.Bytes(), &dtos)
+		names := eventNames(dtos)
+		sort.Strings(names)
+		return names
+	}
+
+	assert.Equal(t, []string{"Market", "Open Studio"}, names("/GetAllEvents?free=true"))
+	assert.Equal(t, []string{"Concert", "Gala", "Unpriced"}, names("/GetAllEvents?free=false"))
+	// Price ranges overlapping 18 to 50
+	assert.Equal(t, []string{"Concert"}, names("/GetAllEvents?min_cost=18&max_cost=50"))
+	assert.Equal(t, []string{"Concert", "Gala", "Open Studio"}, names("/GetAllEvents?min_cost=10"))
+	assert.Equal(t, []string{"Open Studio"}, names("/SearchEvents?q=open&max_cost=0"))
+
+	for _, query := range []string{"?free=maybe", "?min_cost=-1", "?max_cost=ten"} {
+		w := serveWithToken(router, http.MethodGet, "/GetAllEvents"+query, "", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
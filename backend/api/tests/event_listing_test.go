@@ -0,0 +1,138 @@
+package api_tests
+
+import (
+	"backend/api"
+	"backend/data"
+	"backend/database"
+	"backend/eventdate"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupListingFixture() (*gin.Engine, data.User) {
+	database.DB = setupCommentTestDB()
+	router := setupAuthRouter()
+	router.GET("/GetAllEvents", api.GetAllEvents)
+	router.GET("/user/:id/GetUserRegisteredEvents", api.GetRegisteredEvents)
+
+	organizer := data.Organizer{Name: "Gainesville Parks", Email: "parks@example.com"}
+	database.DB.Create(&organizer)
+	user := data.User{Name: "Alice", Email: "alice@example.com"}
+	database.DB.Create(&user)
+
+	start := time.Date(2025, 5, 1, 18, 0, 0, 0, time.UTC)
+	for i, likes := range []uint{3, 9, 1, 9, 5} {
+		startsAt := start.AddDate(0, 0, i)
+		event := data.Event{Name: fmt.Sprintf("Event %d", i), StartsAt: &startsAt, Likes: likes, Cost: float64(10 * i), OrganizerID: organizer.ID}
+		database.DB.Create(&event)
+		if i%2 == 0 {
+			database.DB.Model(&user).Association("Events").Append(&event)
+		}
+	}
+	database.DB.Create(&data.Event{Name: "Undated", OrganizerID: organizer.ID})
+	return router, user
+}
+
+// walkPages follows X-Next-Cursor from path and returns the event names in order
+func walkPages(t *testing.T, router *gin.Engine, path string) ([]string, string) {
+	var names []string
+	var total string
+	next := path
+	for pages := 0; next != ""; pages++ {
+		if !assert.Less(t, pages, 10, "pagination should terminate") {
+			break
+		}
+		w := serveWithToken(router, http.MethodGet, next, "", "")
+		assert.Equal(t, http.StatusOK, w.Code, next)
+		var events []data.EventDTO
+		json.Unmarshal(w.Body.Bytes(), &events)
+		for _, event := range events {
+			names = append(names, event.Name)
+		}
+		total = w.Header().Get("X-Total-Count")
+		next = ""
+		if cursor := w.Header().Get("X-Next-Cursor"); cursor != "" {
+			next = path + "&cursor=" + cursor
+		}
+	}
+	return names, total
+}
+
+func TestGetAllEvents_CursorPagination(t *testing.T) {
+	router, _ := setupListingFixture()
+
+	names, total := walkPages(t, router, "/GetAllEvents?limit=2")
+	assert.Equal(t, "6", total)
+	assert.Equal(t, []string{"Event 0", "Event 1", "Event 2", "Event 3", "Event 4", "Undated"}, names)
+
+	// Ties on likes fall back to the ID, in the same direction
+	names, _ = walkPages(t, router, "/GetAllEvents?limit=2&sort=-likes")
+	assert.Equal(t, []string{"Event 3", "Event 1", "Event 4", "Event 0", "Event 2", "Undated"}, names)
+
+	names, total = walkPages(t, router, "/GetAllEvents?limit=1&sort=-cost&upcoming=false&from=2025-05-02")
+	assert.Equal(t, "4", total)
+	assert.Equal(t, []string{"Event 4", "Event 3", "Event 2", "Event 1"}, names)
+}
+
+func TestGetAllEvents_FieldsAndOrganizer(t *testing.T) {
+	router, _ := setupListingFixture()
+
+	w := serveWithToken(router, http.MethodGet, "/GetAllEvents?limit=1", "", "")
+	var full []data.EventDTO
+	json.Unmarshal(w.Body.Bytes(), &full)
+	assert.Equal(t, "Gainesville Parks", full[0].Organizer.Name)
+
+	w = serveWithToken(router, http.MethodGet, "/GetAllEvents?limit=1&fields=name,likes", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var sparse []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &sparse)
+	assert.Equal(t, map[string]any{"id": float64(1), "name": "Event 0", "likes": float64(3)}, sparse[0])
+
+	for _, query := range []string{"?sort=popularity", "?limit=0", "?limit=501", "?fields=name,secret", "?cursor=not-a-cursor"} {
+		w := serveWithToken(router, http.MethodGet, "/GetAllEvents"+query, "", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	// A cursor only continues the ordering it came from
+	w = serveWithToken(router, http.MethodGet, "/GetAllEvents?limit=1", "", "")
+	cursor := w.Header().Get("X-Next-Cursor")
+	w = serveWithToken(router, http.MethodGet, "/GetAllEvents?limit=1&sort=likes&cursor="+cursor, "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetRegisteredEvents_Paginates(t *testing.T) {
+	router, user := setupListingFixture()
+
+	names, total := walkPages(t, router, fmt.Sprintf("/user/%d/GetUserRegisteredEvents?limit=2", user.ID))
+	assert.Equal(t, "3", total)
+	assert.Equal(t, []string{"Event 0", "Event 2", "Event 4"}, names)
+}
+
+func TestGetAllEvents_CreatedSortOutsideUTC(t *testing.T) {
+	// A server running in Gainesville's timezone
+	local := time.Local
+	time.Local = eventdate.Location
+	t.Cleanup(func() { time.Local = local })
+
+	database.DB = setupCommentTestDB()
+	router := setupAuthRouter()
+	router.GET("/GetAllEvents", api.GetAllEvents)
+
+	// Late on May 1 in Gainesville is already May 2 in UTC
+	late := time.Date(2025, 5, 1, 23, 30, 0, 0, eventdate.Location)
+	evening := time.Date(2025, 5, 1, 21, 0, 0, 0, eventdate.Location)
+	database.DB.Create(&data.Event{Name: "Late", CreatedAt: late})
+	database.DB.Create(&data.Event{Name: "Evening", CreatedAt: evening})
+	database.DB.Create(&data.Event{Name: "Now"})
+
+	names, _ := walkPages(t, router, "/GetAllEvents?limit=1&sort=created")
+	assert.Equal(t, []string{"Evening", "Late", "Now"}, names)
+	names, _ = walkPages(t, router, "/GetAllEvents?limit=1&sort=-created")
+	assert.Equal(t, []string{"Now", "Late", "Evening"}, names)
+}
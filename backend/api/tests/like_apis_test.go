@@ -0,0 +1,134 @@
+package api_tests
+
+import (
+	"backend/api"
+	"backend/data"
+	"backend/database"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupLikeRouter() *gin.Engine {
+	router := setupAuthRouter()
+	router.GET("/GetAllEvents", api.OptionalAuth(), api.GetAllEvents)
+	router.GET("/GetEvent/:id", api.OptionalAuth(), api.GetEventByID)
+	authed := router.Group("/", api.RequireAuth())
+	authed.PUT("/events/:id/like", api.LikeEvent)
+	authed.DELETE("/events/:id/like", api.UnlikeEvent)
+	authed.PUT("/comments/:id/like", api.LikeComment)
+	authed.DELETE("/comments/:id/like", api.UnlikeComment)
+	authed.DELETE("/users/:id", api.AuthorizeUserParam("id"), api.RemoveUser)
+	return router
+}
+
+func likeResponse(t *testing.T, router *gin.Engine, method, path, token string) (int, uint) {
+	w := serveWithToken(router, method, path, token, "")
+	var response struct {
+		Likes uint `json:"likes"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w.Code, response.Likes
+}
+
+func TestLikeEvent_IsIdempotentPerUser(t *testing.T) {
+	user, moderator, _ := setupAdminTestDB()
+	router := setupLikeRouter()
+
+	event := data.Event{Name: "Concert"}
+	database.DB.Create(&event)
+	userToken := login(t, router, "/LoginUser", user.Email, "pw")["token"].(string)
+	modToken := login(t, router, "/LoginUser", moderator.Email, "pw")["token"].(string)
+	path := fmt.Sprintf("/events/%d/like", event.ID)
+
+	// Liking twice counts once
+	code, likes := likeResponse(t, router, http.MethodPut, path, userToken)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, uint(1), likes)
+	_, likes = likeResponse(t, router, http.MethodPut, path, userToken)
+	assert.Equal(t, uint(1), likes)
+
+	_, likes = likeResponse(t, router, http.MethodPut, path, modToken)
+	assert.Equal(t, uint(2), likes)
+
+	// Unliking twice is fine too
+	_, likes = likeResponse(t, router, http.MethodDelete, path, userToken)
+	assert.Equal(t, uint(1), likes)
+	code, likes = likeResponse(t, router, http.MethodDelete, path, userToken)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, uint(1), likes)
+
+	var stored data.Event
+	database.DB.First(&stored, event.ID)
+	assert.Equal(t, uint(1), stored.Likes)
+
+	// Anonymous callers and missing events are rejected
+	code, _ = likeResponse(t, router, http.MethodPut, path, "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = likeResponse(t, router, http.MethodPut, "/events/999/like", userToken)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestLikeComment_KeepsEditedFlag(t *testing.T) {
+	user, _, _ := setupAdminTestDB()
+	router := setupLikeRouter()
+
+	event := data.Event{Name: "Concert"}
+	database.DB.Create(&event)
+	comment := data.Comment{EventID: event.ID, UserID: &user.ID, Content: "Nice"}
+	database.DB.Create(&comment)
+	token := login(t, router, "/LoginUser", user.Email, "pw")["token"].(string)
+
+	code, likes := likeResponse(t, router, http.MethodPut, fmt.Sprintf("/comments/%d/like", comment.ID), token)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, uint(1), likes)
+
+	var stored data.Comment
+	database.DB.First(&stored, comment.ID)
+	assert.Equal(t, uint(1), stored.Likes)
+	assert.Equal(t, comment.UpdatedAt.Unix(), stored.UpdatedAt.Unix())
+}
+
+func TestEventDTO_LikedByMe(t *testing.T) {
+	user, moderator, _ := setupAdminTestDB()
+	router := setupLikeRouter()
+
+	liked := data.Event{Name: "Liked"}
+	other := data.Event{Name: "Other"}
+	database.DB.Create(&liked)
+	database.DB.Create(&other)
+	userToken := login(t, router, "/LoginUser", user.Email, "pw")["token"].(string)
+	modToken := login(t, router, "/LoginUser", moderator.Email, "pw")["token"].(string)
+	serveWithToken(router, http.MethodPut, fmt.Sprintf("/events/%d/like", liked.ID), userToken, "")
+
+	likedByMe := func(token string) map[string]bool {
+		w := serveWithToken(router, http.MethodGet, "/GetAllEvents", token, "")
+		var events []data.EventDTO
+		json.Unmarshal(w.Body.Bytes(), &events)
+		result := make(map[string]bool)
+		for _, event := range events {
+			result[event.Name] = event.LikedByMe
+		}
+		return result
+	}
+
+	assert.Equal(t, map[string]bool{"Liked": true, "Other": false}, likedByMe(userToken))
+	assert.Equal(t, map[string]bool{"Liked": false, "Other": false}, likedByMe(modToken))
+	assert.Equal(t, map[string]bool{"Liked": false, "Other": false}, likedByMe(""))
+
+	w := serveWithToken(router, http.MethodGet, fmt.Sprintf("/GetEvent/%d", liked.ID), userToken, "")
+	var dto data.EventDTO
+	json.Unmarshal(w.Body.Bytes(), &dto)
+	assert.True(t, dto.LikedByMe)
+	assert.Equal(t, uint(1), dto.Likes)
+
+	// Deleting the account takes its likes with it
+	serveWithToken(router, http.MethodDelete, fmt.Sprintf("/users/%d", user.ID), userToken, "")
+	var stored data.Event
+	database.DB.First(&stored, liked.ID)
+	assert.Zero(t, stored.Likes)
+}
@@ -0,0 +1,113 @@
+package api_tests
+
+import (
+	"backend/api"
+	"backend/data"
+	"backend/database"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupNearbyFixture() *gin.Engine {
+	database.DB = setupCommentTestDB()
+	router := setupAuthRouter()
+	router.GET("/events/nearby", api.GetNearbyEvents)
+	router.GET("/events/in_bounds", api.GetEventsInBounds)
+
+	events := []data.Event{
+		{Name: "Bo Diddley Plaza", Latitude: 29.6520, Longitude: -82.3240},
+		{Name: "Depot Park", Latitude: 29.6455, Longitude: -82.3190},
+		{Name: "UF Campus", Latitude: 29.6436, Longitude: -82.3549},
+		{Name: "Paynes Prairie", Latitude: 29.5531, Longitude: -82.2993},
+		{Name: "Ocala", Latitude: 29.1872, Longitude: -82.1401},
+		{Name: "Unresolved"},
+		{Name: "Unpublished", Latitude: 29.6520, Longitude: -82.3240},
+	}
+	for i := range events {
+		database.DB.Create(&events[i])
+	}
+	database.DB.Model(&events[6]).Update("active", false)
+	return router
+}
+
+func getNearEvents(t *testing.T, router *gin.Engine, path string, params url.Values) ([]data.EventDTO, http.Header) {
+	w := serveWithToken(router, http.MethodGet, path+"?"+params.Encode(), "", "")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var events []data.EventDTO
+	json.Unmarshal(w.Body.Bytes(), &events)
+	return events, w.Header()
+}
+
+func eventNames(events []data.EventDTO) []string {
+	names := []string{}
+	for _, event := range events {
+		names = append(names, event.Name)
+	}
+	return names
+}
+
+func TestGetNearbyEvents(t *testing.T) {
+	router := setupNearbyFixture()
+	downtown := url.Values{"lat": {"29.6516"}, "lng": {"-82.3248"}}
+
+	// Default radius of 10 km, nearest first, with distances
+	events, _ := getNearEvents(t, router, "/events/nearby", downtown)
+	assert.Equal(t, []string{"Bo Diddley Plaza", "Depot Park", "UF Campus"}, eventNames(events))
+	if assert.NotNil(t, events[0].DistanceKm) {
+		assert.InDelta(t, 0.09, *events[0].DistanceKm, 0.02)
+	}
+	assert.InDelta(t, 3.0, *events[2].DistanceKm, 0.2)
+
+	params := url.Values{"lat": downtown["lat"], "lng": downtown["lng"], "radius_km": {"20"}}
+	events, _ = getNearEvents(t, router, "/events/nearby", params)
+	assert.Equal(t, []string{"Bo Diddley Plaza", "Depot Park", "UF Campus", "Paynes Prairie"}, eventNames(events))
+
+	// Pages follow the distance order
+	params.Set("limit", "3")
+	events, header := getNearEvents(t, router, "/events/nearby", params)
+	assert.Len(t, events, 3)
+	assert.Equal(t, "4", header.Get("X-Total-Count"))
+	params.Set("cursor", header.Get("X-Next-Cursor"))
+	events, header = getNearEvents(t, router, "/events/nearby", params)
+	assert.Equal(t, []string{"Paynes Prairie"}, eventNames(events))
+	assert.Empty(t, header.Get("X-Next-Cursor"))
+
+	for _, query := range []string{"lat=29.6", "lat=91&lng=0", "lat=29.6&lng=-82.3&radius_km=0", "lat=29.6&lng=-82.3&radius_km=1000", "lat=29.6&lng=-82.3&cursor=abc"} {
+		w := serveWithToken(router, http.MethodGet, "/events/nearby?"+query, "", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestGetEventsInBounds(t *testing.T) {
+	router := setupNearbyFixture()
+
+	// Ordered from the box's centre, which is closest to the prairie
+	events, _ := getNearEvents(t, router, "/events/in_bounds", url.Values{
+		"south": {"29.0"}, "west": {"-83.0"}, "north": {"29.7"}, "east": {"-82.0"},
+	})
+	assert.Equal(t, []string{"Paynes Prairie", "UF Campus", "Depot Park", "Bo Diddley Plaza", "Ocala"}, eventNames(events))
+
+	// Or from a given point; (0, 0) never counts as a location
+	events, _ = getNearEvents(t, router, "/events/in_bounds", url.Values{
+		"south": {"-1"}, "west": {"-83.0"}, "north": {"29.7"}, "east": {"1"}, "lat": {"29.6520"}, "lng": {"-82.3240"},
+	})
+	assert.Equal(t, []string{"Bo Diddley Plaza", "Depot Park", "UF Campus", "Paynes Prairie", "Ocala"}, eventNames(events))
+
+	// Boxes across the antimeridian
+	events, _ = getNearEvents(t, router, "/events/in_bounds", url.Values{
+		"south": {"29.0"}, "west": {"170"}, "north": {"29.7"}, "east": {"-82.33"},
+	})
+	assert.Equal(t, []string{"UF Campus"}, eventNames(events))
+
+	for _, query := range []string{"south=29&west=-83&north=30", "south=30&west=-83&north=29&east=-82", "south=29&west=-83&north=30&east=-82&lat=29"} {
+		w := serveWithToken(router, http.MethodGet, "/events/in_bounds?"+query, "", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	w := serveWithToken(router, http.MethodGet, "/events/in_bounds?south=30&west=-83&north=29&east=-82", "", "")
+	assert.JSONEq(t, `{"error":"South must not be above north"}`, w.Body.String())
+}
@@ -2,6 +2,7 @@ package api_tests
 
 import (
 	"backend/api"
+	"backend/auth"
 	"backend/data"
 	"backend/database"
 	"bytes"
@@ -19,7 +20,7 @@ import (
 
 func setupTestOrgDB() *gorm.DB {
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	db.AutoMigrate(&data.Organizer{}, &data.Event{})
+	db.AutoMigrate(&data.Organizer{}, &data.Event{}, &data.Session{})
 	return db
 }
 
@@ -216,3 +217,71 @@ func TestLoginOrganizer_Failure(t *testing.T) {
 	assert.Equal(t, organizer.Email, dbOrganizer.Email)
 	assert.Equal(t, organizer.Password, dbOrganizer.Password)
 }
+
+func TestCreateOrganizer_HashesPassword(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := setupTestOrgDB()
+	database.DB = db
+
+	organizerJSON := `{"name": "Test Organizer", "email": "org@example.com", "password": "secret"}`
+	req, _ := http.NewRequest(http.MethodPost, "/organizers", bytes.NewBufferString(organizerJSON))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	api.CreateOrganizer(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var dbOrganizer data.Organizer
+	db.First(&dbOrganizer)
+	assert.NotEqual(t, "secret", dbOrganizer.Password)
+	assert.True(t, auth.IsHashed(dbOrganizer.Password))
+}
+
+func TestLoginOrganizer_UpgradesLegacyPlaintextPassword(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := setupTestOrgDB()
+	database.DB = db
+
+	// A row written before passwords were hashed
+	organizer := data.Organizer{Name: "Test Organizer", Email: "testemail@testexample.com", Password: "testpassword"}
+	db.Create(&organizer)
+
+	reqBody := `{"email":"` + organizer.Email + `", "password": "testpassword"}`
+	req, _ := http.NewRequest(http.MethodPost, "/organizers/login", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	api.LoginOrganizer(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var dbOrganizer data.Organizer
+	db.First(&dbOrganizer, organizer.ID)
+	assert.True(t, auth.IsHashed(dbOrganizer.Password))
+	ok, _ := auth.VerifyPassword(dbOrganizer.Password, "testpassword")
+	assert.True(t, ok)
+}
+
+func TestGetOrganizerByID_HidesPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database.DB = setupTestOrgDB()
+	hashed, _ := auth.HashPassword("secret")
+	organizer := data.Organizer{Name: "Test Organizer", Email: "org@example.com", Password: hashed}
+	database.DB.Create(&organizer)
+
+	router := gin.New()
+	router.GET("/organizer/:id", api.GetOrganizerByID)
+	req, _ := http.NewRequest(http.MethodGet, "/organizer/"+strconv.Itoa(int(organizer.ID)), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "org@example.com")
+	assert.NotContains(t, w.Body.String(), "password")
+	assert.NotContains(t, w.Body.String(), hashed)
+}
@@ -0,0 +1,156 @@
+package api_tests
+
+import (
+	"backend/data"
+	"backend/database"
+	"backend/scraper"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubSource returns two listings it cannot normalize, failing the fetch
+// afterwards if fail is set and waiting for release first if that is set
+type stubSource struct {
+	fail    bool
+	release chan struct{}
+}
+
+func (s *stubSource) Name() string { return "stub_source" }
+
+func (s *stubSource) Fetch(ctx context.Context) ([]interface{}, error) {
+	if s.release != nil {
+		<-s.release
+	}
+	scraper.PageFetched(ctx)
+	scraper.ReportError(ctx, "https://example.com/event", errors.New("no venue"))
+	if s.fail {
+		return []interface{}{"first"}, errors.New("parsing page 2: unexpected end of JSON input")
+	}
+	return []interface{}{"first", "second"}, nil
+}
+
+func (s *stubSource) Normalize(listing interface{}) (scraper.RawEvent, error) {
+	return scraper.RawEvent{}, fmt.Errorf("cannot normalize %v", listing)
+}
+
+var stub = &stubSource{}
+
+func init() {
+	scraper.Register(stub)
+}
+
+func setupScrapeFixture() (*gin.Engine, string, string) {
+	router := setupAdminRouter()
+	_, moderator, admin := setupAdminTestDB()
+	// Runs write from their own goroutine, and every connection to :memory: is a
+	// database of its own
+	sqlDB, _ := database.DB.DB()
+	sqlDB.SetMaxOpenConns(1)
+	*stub = stubSource{}
+	return router, moderator.Email, admin.Email
+}
+
+func triggerScrape(t *testing.T, router *gin.Engine, token string) data.ScrapeRun {
+	w := serveWithToken(router, http.MethodPost, "/admin/scrapes", token, `{"source": "stub_source"}`)
+	assert.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var run data.ScrapeRun
+	json.Unmarshal(w.Body.Bytes(), &run)
+	assert.Eventually(t, func() bool { return !scraper.DefaultScheduler.Running(stub.Name()) }, 5*time.Second, 5*time.Millisecond)
+	return run
+}
+
+func stubHealth(t *testing.T, router *gin.Engine, token string) scraper.SourceHealth {
+	w := serveWithToken(router, http.MethodGet, "/admin/scrapes/health", token, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var report []scraper.SourceHealth
+	json.Unmarshal(w.Body.Bytes(), &report)
+	for _, health := range report {
+		if health.Source == stub.Name() {
+			return health
+		}
+	}
+	t.Fatal("stub_source missing from the health report")
+	return scraper.SourceHealth{}
+}
+
+func TestAdminScrapes(t *testing.T) {
+	router, moderatorEmail, adminEmail := setupScrapeFixture()
+	modToken := login(t, router, "/LoginUser", moderatorEmail, "pw")["token"].(string)
+	adminToken := login(t, router, "/LoginUser", adminEmail, "pw")["token"].(string)
+
+	assert.Equal(t, scraper.HealthNeverRun, stubHealth(t, router, modToken).Status)
+
+	// Only admins start scrapes
+	w := serveWithToken(router, http.MethodPost, "/admin/scrapes", modToken, `{"source": "stub_source"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serveWithToken(router, http.MethodPost, "/admin/scrapes", adminToken, `{"source": "nowhere"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	run := triggerScrape(t, router, adminToken)
+	assert.Equal(t, data.ScrapeRunning, run.Status)
+	assert.Equal(t, scraper.TriggerManual, run.Trigger)
+
+	// Every listing failed, but the fetch itself went through
+	w = serveWithToken(router, http.MethodGet, fmt.Sprintf("/admin/scrapes/%d", run.ID), modToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &run)
+	assert.Equal(t, data.ScrapePartial, run.Status)
+	assert.NotNil(t, run.EndedAt)
+	assert.Equal(t, 1, run.Pages)
+	assert.Equal(t, 2, run.Seen)
+	assert.Equal(t, 2, run.Failed)
+	if assert.Len(t, run.Errors, 3) {
+		assert.Equal(t, "https://example.com/event", run.Errors[0].Listing)
+		assert.Equal(t, "no venue", run.Errors[0].Message)
+		assert.Equal(t, "cannot normalize first", run.Errors[1].Message)
+	}
+	health := stubHealth(t, router, modToken)
+	assert.Equal(t, scraper.HealthOK, health.Status)
+	assert.True(t, health.Enabled)
+	assert.NotNil(t, health.NextRunAt)
+
+	// A broken source shows up as failing
+	stub.fail = true
+	failed := triggerScrape(t, router, adminToken)
+	triggerScrape(t, router, adminToken)
+	health = stubHealth(t, router, modToken)
+	assert.Equal(t, scraper.HealthFailing, health.Status)
+	assert.Equal(t, 2, health.ConsecutiveFailures)
+	assert.Equal(t, *run.EndedAt, health.LastSuccessAt.UTC())
+
+	w = serveWithToken(router, http.MethodGet, "/admin/scrapes?source=stub_source&status=failed", modToken, "")
+	var runs []data.ScrapeRun
+	json.Unmarshal(w.Body.Bytes(), &runs)
+	if assert.Len(t, runs, 2) {
+		assert.Greater(t, runs[0].ID, runs[1].ID)
+		assert.Equal(t, "parsing page 2: unexpected end of JSON input", runs[1].Error)
+		assert.Nil(t, runs[1].Errors)
+	}
+	w = serveWithToken(router, http.MethodGet, fmt.Sprintf("/admin/scrapes?before=%d", failed.ID), modToken, "")
+	json.Unmarshal(w.Body.Bytes(), &runs)
+	assert.Len(t, runs, 1)
+	assert.Equal(t, run.ID, runs[0].ID)
+
+	// Runs of a source never overlap
+	stub.release = make(chan struct{})
+	w = serveWithToken(router, http.MethodPost, "/admin/scrapes", adminToken, `{"source": "stub_source"}`)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	w = serveWithToken(router, http.MethodPost, "/admin/scrapes", adminToken, `{"source": "stub_source"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.True(t, stubHealth(t, router, modToken).Running)
+	close(stub.release)
+	assert.Eventually(t, func() bool { return !scraper.DefaultScheduler.Running(stub.Name()) }, 5*time.Second, 5*time.Millisecond)
+
+	for _, path := range []string{"/admin/scrapes/999", "/admin/scrapes?limit=0", "/admin/scrapes?before=x"} {
+		w = serveWithToken(router, http.MethodGet, path, modToken, "")
+		assert.NotEqual(t, http.StatusOK, w.Code, path)
+	}
+}
@@ -0,0 +1,125 @@
+package api_tests
+
+import (
+	"backend/api"
+	"backend/data"
+	"backend/database"
+	"backend/eventtags"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSearchFixture() *gin.Engine {
+	database.DB = setupCommentTestDB()
+	router := setupAuthRouter()
+	router.GET("/events/search", api.SearchEvents)
+
+	jazzClub := data.Organizer{Name: "Downtown Jazz Club", Email: "jazz@example.com"}
+	parks := data.Organizer{Name: "Gainesville Parks", Email: "parks@example.com"}
+	database.DB.Create(&jazzClub)
+	database.DB.Create(&parks)
+
+	events := []data.Event{
+		{Name: "Friday Night JAZZ", Location: "Bo Diddley Plaza", Category: "Music, Nightlife", Tags: "Live Music, Jazz", Cost: 0, OrganizerID: jazzClub.ID},
+		{Name: "Blues Brunch", Description: "Smooth jazz and eggs", Location: "Downtown", Category: "Food", Tags: "brunch,music", Cost: 25, OrganizerID: jazzClub.ID},
+		{Name: "Nature Walk", Location: "Paynes Prairie", Category: "Outdoors", Tags: "Hiking, Family", Cost: 5, OrganizerID: parks.ID},
+		{Name: "100% Fun Run", Location: "Depot Park", Category: "Sports", Tags: "running", Cost: 15, OrganizerID: parks.ID},
+		{Name: "Hidden Jazz", Category: "Music", Tags: "Jazz", OrganizerID: jazzClub.ID},
+	}
+	for i := range events {
+		database.DB.Create(&events[i])
+		eventtags.Apply(database.DB, &events[i])
+	}
+	database.DB.Model(&events[4]).Update("active", false)
+	return router
+}
+
+func searchNames(t *testing.T, router *gin.Engine, params url.Values) []string {
+	w := serveWithToken(router, http.MethodGet, "/events/search?"+params.Encode(), "", "")
+	assert.Equal(t, http.StatusOK, w.Code, params.Encode())
+	var events []data.EventDTO
+	json.Unmarshal(w.Body.Bytes(), &events)
+	names := []string{}
+	for _, event := range events {
+		names = append(names, event.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestSearchEvents(t *testing.T) {
+	router := setupSearchFixture()
+
+	tests := []struct {
+		name   string
+		params url.Values
+		want   []string
+	}{
+		{"text ignores case", url.Values{"q": {"jazz"}}, []string{"Blues Brunch", "Friday Night JAZZ"}},
+		{"every word must match", url.Values{"q": {"jazz downtown"}}, []string{"Blues Brunch", "Friday Night JAZZ"}},
+		{"matches organizer name", url.Values{"q": {"gainesville parks"}}, []string{"100% Fun Run", "Nature Walk"}},
+		{"wildcards are literal", url.Values{"q": {"100%"}}, []string{"100% Fun Run"}},
+		{"underscore is literal", url.Values{"q": {"_"}}, []string{}},
+		{"any category", url.Values{"category": {"nightlife,outdoors"}}, []string{"Friday Night JAZZ", "Nature Walk"}},
+		{"category is a whole entry", url.Values{"category": {"night"}}, []string{}},
+		{"all tags", url.Values{"tags": {"live music, jazz"}}, []string{"Friday Night JAZZ"}},
+		{"cost range", url.Values{"min_cost": {"5"}, "max_cost": {"20"}}, []string{"100% Fun Run", "Nature Walk"}},
+		{"organizer by name", url.Values{"organizer": {"JAZZ club"}, "max_cost": {"0"}}, []string{"Friday Night JAZZ"}},
+		{"unpublished events are hidden", url.Values{"q": {"hidden"}}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, searchNames(t, router, tt.params))
+		})
+	}
+
+	for _, query := range []string{"min_cost=free", "max_cost=-1", "organizer_id=abc", "sort=relevance"} {
+		w := serveWithToken(router, http.MethodGet, "/events/search?"+query, "", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestFullTextSearch(t *testing.T) {
+	router := setupSearchFixture()
+	if !database.FullTextSearch {
+		t.Skip("SQLite built without FTS5; run with -tags sqlite_fts5")
+	}
+
+	// Words match as prefixes and through stemming
+	assert.Equal(t, []string{"Blues Brunch", "Friday Night JAZZ"}, searchNames(t, router, url.Values{"q": {"jaz"}}))
+	assert.Equal(t, []string{"Nature Walk"}, searchNames(t, router, url.Values{"q": {"walking"}}))
+
+	// A name match outranks a description match, and carries a snippet
+	w := serveWithToken(router, http.MethodGet, "/events/search?q=jazz", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var events []data.EventDTO
+	json.Unmarshal(w.Body.Bytes(), &events)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "Friday Night JAZZ", events[0].Name)
+		assert.Contains(t, events[1].Snippet, "<mark>jazz</mark>")
+	}
+
+	// Relevance pages with cursors like any other sort
+	w = serveWithToken(router, http.MethodGet, "/events/search?q=jazz&limit=1", "", "")
+	assert.Equal(t, "2", w.Header().Get("X-Total-Count"))
+	cursor := w.Header().Get("X-Next-Cursor")
+	assert.NotEmpty(t, cursor)
+	assert.Equal(t, []string{"Blues Brunch"}, searchNames(t, router, url.Values{"q": {"jazz"}, "limit": {"1"}, "cursor": {cursor}}))
+
+	// The index follows edits to events and organizer names
+	database.DB.Model(&data.Event{}).Where("name = ?", "Nature Walk").Update("description", "Birdwatching at dawn")
+	database.DB.Model(&data.Organizer{}).Where("name = ?", "Gainesville Parks").Update("name", "Alachua Parks")
+	assert.Equal(t, []string{"Nature Walk"}, searchNames(t, router, url.Values{"q": {"birdwatching"}}))
+	assert.Equal(t, []string{"100% Fun Run", "Nature Walk"}, searchNames(t, router, url.Values{"q": {"alachua"}}))
+	assert.Equal(t, []string{}, searchNames(t, router, url.Values{"q": {"gainesville"}}))
+
+	// Rebuilding leaves the same results
+	assert.NoError(t, database.RebuildSearchIndex(database.DB))
+	assert.Equal(t, []string{"Nature Walk"}, searchNames(t, router, url.Values{"q": {"birdwatching"}}))
+}
@@ -0,0 +1,114 @@
+package api_tests
+
+import (
+	"backend/api"
+	"backend/data"
+	"backend/database"
+	"backend/eventtags"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTagFixture() *gin.Engine {
+	database.DB = setupCommentTestDB()
+	router := setupAuthRouter()
+	router.GET("/tags", api.GetTags)
+	router.GET("/tags/:slug/events", api.GetTagEvents)
+
+	events := []data.Event{
+		{Name: "Jazz Night", Tags: "Live Music, Jazz"},
+		{Name: "Porch Fest", Tags: "livemusic,festival,kids"},
+		{Name: "Book Sale", Tags: "booksale,#books"},
+		{Name: "Hidden Show", Tags: "Live Music"},
+	}
+	for i := range events {
+		database.DB.Create(&events[i])
+		eventtags.Apply(database.DB, &events[i])
+	}
+	database.DB.Model(&events[3]).Update("active", false)
+	return router
+}
+
+func getTags(t *testing.T, router *gin.Engine, query string) []data.TagDTO {
+	w := serveWithToken(router, http.MethodGet, "/tags"+query, "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var tags []data.TagDTO
+	json.Unmarshal(w.Body.Bytes(), &tags)
+	return tags
+}
+
+func TestGetTags(t *testing.T) {
+	router := setupTagFixture()
+
+	tags := getTags(t, router, "")
+	assert.Equal(t, data.TagDTO{Slug: "live-music", Name: "Live Music", Count: 2}, tags[0])
+	assert.Len(t, tags, 6)
+
+	assert.Equal(t, []data.TagDTO{{Slug: "books", Name: "books", Count: 1}, {Slug: "booksale", Name: "booksale", Count: 1}}, getTags(t, router, "?q=Book"))
+	assert.Len(t, getTags(t, router, "?limit=2"), 2)
+
+	w := serveWithToken(router, http.MethodGet, "/tags?limit=0", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetTagEvents(t *testing.T) {
+	router := setupTagFixture()
+
+	for _, slug := range []string{"live-music", "livemusic"} {
+		w := serveWithToken(router, http.MethodGet, "/tags/"+slug+"/events", "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var events []data.EventDTO
+		json.Unmarshal(w.Body.Bytes(), &events)
+		assert.ElementsMatch(t, []string{"Jazz Night", "Porch Fest"}, eventNames(events))
+		for _, event := range events {
+			assert.Contains(t, event.TagList, data.Tag{Slug: "live-music", Name: "Live Music"})
+		}
+	}
+
+	// Synonyms lead to the same tag
+	w := serveWithToken(router, http.MethodGet, "/tags/children/events", "", "")
+	var events []data.EventDTO
+	json.Unmarshal(w.Body.Bytes(), &events)
+	assert.Equal(t, []string{"Porch Fest"}, eventNames(events))
+
+	w = serveWithToken(router, http.MethodGet, "/tags/unknown/events", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestMigrate_NormalizesEventTags(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, db.AutoMigrate(&data.Event{}))
+
+	// Rows as each scraper stored them, before the tags table existed
+	evvnt := data.Event{Name: "evvnt", Tags: "dudleyfarm,parktour,history"}
+	visit := data.Event{Name: "Visit Gainesville", Tags: "Park Tour, History"}
+	untagged := data.Event{Name: "Untagged"}
+	for _, event := range []*data.Event{&evvnt, &visit, &untagged} {
+		db.Create(event)
+	}
+
+	assert.NoError(t, database.Migrate(db))
+
+	tagsOf := func(id uint) []string {
+		var event data.Event
+		db.Preload("TagList").First(&event, id)
+		slugs := []string{}
+		for _, tag := range event.TagList {
+			slugs = append(slugs, tag.Slug)
+		}
+		return slugs
+	}
+	assert.ElementsMatch(t, []string{"dudleyfarm", "park-tour", "history"}, tagsOf(evvnt.ID))
+	assert.ElementsMatch(t, []string{"park-tour", "history"}, tagsOf(visit.ID))
+	assert.Empty(t, tagsOf(untagged.ID))
+
+	var count int64
+	db.Model(&data.Tag{}).Count(&count)
+	assert.Equal(t, int64(3), count)
+}
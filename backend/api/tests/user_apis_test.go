@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"backend/api"
+	"backend/auth"
 	"backend/data"
 	"backend/database"
 
@@ -37,7 +38,7 @@ func setupRouter() *gin.Engine {
 
 func initTestDB() *gorm.DB {
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	db.AutoMigrate(&data.User{}) // Create the User table in memory
+	db.AutoMigrate(&data.User{}, &data.Session{}, &data.Comment{}, &data.CommentMention{}, &data.Like{}) // Create the User, Session, Comment and Like tables in memory
 	return db
 }
 
@@ -132,7 +133,7 @@ func TestGetUserByID_Success(t *testing.T) {
 	}
 
 	// Check if the response body matches the expected format
-	expected := `{"id":` + strconv.Itoa(int(user.ID)) + `,"name":"John Doe","email":"test1@gmail.com","password":"1234","Events":null,"logged_in":false}`
+	expected := `{"id":` + strconv.Itoa(int(user.ID)) + `,"name":"John Doe","email":"test1@gmail.com","role":"user","banned":false,"Events":null,"logged_in":false}`
 	if res.Body.String() != expected {
 		t.Errorf("Expected body to be %q, got %q", expected, res.Body.String())
 	}
@@ -480,4 +481,92 @@ func TestLogoutUser_UserNotFound(t *testing.T) {
     }
 }
 
-/////////////////////////////////////////////////////////////////////////////////////////
\ No newline at end of file
+/////////////////////////////////////////////////////////////////////////////////////////
+//////////////////////////////////////////////////////////////////////////////////
+
+func TestRegisterUser_HashesPassword(t *testing.T) {
+    database.DB = initTestDB()
+    router := setupRouter()
+
+    user := `{"name": "Jane Doe", "email": "jane.doe@example.com", "password": "securepassword"}`
+    req, _ := http.NewRequest("POST", "/register", bytes.NewBufferString(user))
+    res := httptest.NewRecorder()
+    router.ServeHTTP(res, req)
+
+    if res.Code != http.StatusCreated {
+        t.Fatalf("Expected status code %d, got %d", http.StatusCreated, res.Code)
+    }
+
+    var dbUser data.User
+    database.DB.Where("email = ?", "jane.doe@example.com").First(&dbUser)
+    if dbUser.Password == "securepassword" || !auth.IsHashed(dbUser.Password) {
+        t.Errorf("Expected password to be stored as a hash, got %q", dbUser.Password)
+    }
+
+    // The hashed password still works for login
+    loginData := `{"email": "jane.doe@example.com", "password": "securepassword"}`
+    req, _ = http.NewRequest("POST", "/login", bytes.NewBufferString(loginData))
+    res = httptest.NewRecorder()
+    router.ServeHTTP(res, req)
+
+    if res.Code != http.StatusOK {
+        t.Errorf("Expected status code %d, got %d", http.StatusOK, res.Code)
+    }
+}
+
+func TestLoginUser_UpgradesLegacyPlaintextPassword(t *testing.T) {
+    database.DB = initTestDB()
+    router := setupRouter()
+
+    // A row written before passwords were hashed
+    user := data.User{Name: "Legacy User", Email: "legacy@example.com", Password: "1234"}
+    database.DB.Create(&user)
+
+    loginData := `{"email": "legacy@example.com", "password": "1234"}`
+    req, _ := http.NewRequest("POST", "/login", bytes.NewBufferString(loginData))
+    res := httptest.NewRecorder()
+    router.ServeHTTP(res, req)
+
+    if res.Code != http.StatusOK {
+        t.Fatalf("Expected status code %d, got %d", http.StatusOK, res.Code)
+    }
+
+    var dbUser data.User
+    database.DB.First(&dbUser, user.ID)
+    if !auth.IsHashed(dbUser.Password) {
+        t.Errorf("Expected legacy password to be rehashed, got %q", dbUser.Password)
+    }
+
+    // Logging in again goes through the hashed path
+    req, _ = http.NewRequest("POST", "/login", bytes.NewBufferString(loginData))
+    res = httptest.NewRecorder()
+    router.ServeHTTP(res, req)
+
+    if res.Code != http.StatusOK {
+        t.Errorf("Expected status code %d, got %d", http.StatusOK, res.Code)
+    }
+}
+
+func TestEditUserInfo_HashesNewPassword(t *testing.T) {
+    database.DB = initTestDB()
+    router := setupRouter()
+
+    hashed, _ := auth.HashPassword("1234")
+    user := data.User{Name: "John Doe", Email: "john@example.com", Password: hashed}
+    database.DB.Create(&user)
+
+    editUser := `{"name": "John Doe", "email": "john@example.com", "password": "newpassword"}`
+    req, _ := http.NewRequest("PUT", "/editUser/"+strconv.Itoa(int(user.ID)), bytes.NewBufferString(editUser))
+    res := httptest.NewRecorder()
+    router.ServeHTTP(res, req)
+
+    if res.Code != http.StatusOK {
+        t.Fatalf("Expected status code %d, got %d", http.StatusOK, res.Code)
+    }
+
+    var dbUser data.User
+    database.DB.First(&dbUser, user.ID)
+    if ok, _ := auth.VerifyPassword(dbUser.Password, "newpassword"); !ok || !auth.IsHashed(dbUser.Password) {
+        t.Errorf("Expected new password to be stored as a hash, got %q", dbUser.Password)
+    }
+}
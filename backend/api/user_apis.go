@@ -1,20 +1,32 @@
 package api
 
 import (
+	"backend/auth"
 	"backend/data"
 	"backend/database"
+	"backend/eventtags"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// userInput is what a client may set on an account. Roles, bans and the
+// events linked to it are managed elsewhere, so they are not read from bodies.
+type userInput struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
 // AddUser handles adding a new user
 func AddUser(c *gin.Context) {
-	var user data.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var input userInput
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
 		return
 	}
+	user := data.User{Name: input.Name, Email: input.Email, Password: input.Password}
 
 	// Validate mandatory fields
 	if user.Email == "" || user.Password == "" {
@@ -23,11 +35,20 @@ func AddUser(c *gin.Context) {
 	}
 
 	// Check if the email already exists
-	if err := database.DB.Where("email = ?", user.Email).First(&user).Error; err == nil {
+	if err := database.DB.Where("email = ?", user.Email).First(&data.User{}).Error; err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
 		return
 	}
 
+	// Store only a hash of the password
+	hashed, err := auth.HashPassword(user.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+	user.Password = hashed
+	user.Role = data.RoleUser // Roles are never self-assigned
+
 	// Create the user
 	if err := database.DB.Create(&user).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
@@ -66,11 +87,24 @@ func EditUserInfo(c *gin.Context) {
 		return
 	}
 
-	// Bind the JSON input to the user variable
-	if err := c.ShouldBindJSON(&user); err != nil {
+	// Bind the JSON input over the current name and email, so fields left out
+	// are kept. The ID, role, bans and events are never taken from the body.
+	input := userInput{Name: user.Name, Email: user.Email}
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
 		return
 	}
+	user.Name, user.Email = input.Name, input.Email
+
+	// Keep the current password unless a new one was supplied, and hash it if so
+	if input.Password != "" {
+		hashed, err := auth.HashPassword(input.Password)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+			return
+		}
+		user.Password = hashed
+	}
 
 	// Optionally check if the email already exists for another user
 	if err := database.DB.Where("email = ? AND id != ?", user.Email, user.ID).First(&data.User{}).Error; err == nil {
@@ -108,10 +142,45 @@ func RemoveUser(c *gin.Context) {
 		return
 	}
 
-	// Step 3: Check if there's an organizer with same name and email
-	var organizer data.Organizer
-	if err := database.DB.Where("name = ? AND email = ?", user.Name, user.Email).First(&organizer).Error; err == nil {
-		// Step 3a: Delete all events created by that organizer
+	// Step 2b: Keep the user's comments but detach them from the account
+	if err := database.DB.Model(&data.Comment{}).Where("user_id = ?", user.ID).Update("user_id", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to detach user comments"})
+		return
+	}
+	if err := database.DB.Where("user_id = ?", user.ID).Delete(&data.CommentMention{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to detach user comments"})
+		return
+	}
+	if err := deleteUserLikes(database.DB, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove user likes"})
+		return
+	}
+
+	// Step 3: Find the organizer profiles linked to the account; one merely sharing its name and email is not the user's
+	var organizers []data.Organizer
+	if err := database.DB.Where("user_id = ?", user.ID).Find(&organizers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find user's organizers"})
+		return
+	}
+	for _, organizer := range organizers {
+		// Step 3a: Delete all events created by that organizer, with their comments, likes and tags
+		organizerEvents := database.DB.Model(&data.Event{}).Select("id").Where("organizer_id = ?", organizer.ID)
+		if err := deleteComments(database.DB, "event_id IN (?)", organizerEvents); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organizer's event comments"})
+			return
+		}
+		if err := deleteLikes(database.DB, data.LikeTargetEvent, organizerEvents); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organizer's event likes"})
+			return
+		}
+		if err := eventtags.Detach(database.DB, organizerEvents); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organizer's event tags"})
+			return
+		}
+		if err := deleteDuplicateCandidates(database.DB, organizerEvents); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organizer's event duplicates"})
+			return
+		}
 		if err := database.DB.Where("organizer_id = ?", organizer.ID).Delete(&data.Event{}).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organizer's events"})
 			return
@@ -138,13 +207,14 @@ func RemoveUser(c *gin.Context) {
 
 //Register User
 func RegisterUser(c *gin.Context) {
-    var user data.User
+    var input userInput
 
     // Bind the JSON input to the user variable
-    if err := c.ShouldBindJSON(&user); err != nil {
+    if err := c.ShouldBindJSON(&input); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
         return
     }
+    user := data.User{Name: input.Name, Email: input.Email, Password: input.Password}
 
     // Check if the email already exists
     if err := database.DB.Where("email = ?", user.Email).First(&data.User{}).Error; err == nil {
@@ -152,6 +222,15 @@ func RegisterUser(c *gin.Context) {
         return
     }
 
+    // Store only a hash of the password
+    hashed, err := auth.HashPassword(user.Password)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+        return
+    }
+    user.Password = hashed
+    user.Role = data.RoleUser // Roles are never self-assigned
+
     // Save the new user to the database
     if err := database.DB.Create(&user).Error; err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
@@ -183,11 +262,27 @@ func LoginUser(c *gin.Context) {
 		return
 	}
 
-	if user.Password != loginData.Password {
+	ok, needsRehash := auth.VerifyPassword(user.Password, loginData.Password)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
 
+	if user.Banned {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is banned"})
+		return
+	}
+
+	// Upgrade a legacy plaintext password now that we know it is correct
+	if needsRehash {
+		hashed, err := auth.HashPassword(loginData.Password)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update login status"})
+			return
+		}
+		user.Password = hashed
+	}
+
 	// Set the logged_in flag to true
 	user.LoggedIn = true
 	if err := database.DB.Save(&user).Error; err != nil {
@@ -195,11 +290,16 @@ func LoginUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"user_id": user.ID,
-		"name":    user.Name,
-	})
+	response, err := issueSession(auth.KindUser, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		return
+	}
+	response["message"] = "Login successful"
+	response["user_id"] = user.ID
+	response["name"] = user.Name
+
+	c.JSON(http.StatusOK, response)
 }
 
 // LogoutUser handles user logout
@@ -212,6 +312,21 @@ func LogoutUser(c *gin.Context) {
 		return
 	}
 
+	// Revoke the session behind the caller's token, or every session of the
+	// user when an admin logs someone else out
+	var err error
+	if principal, ok := CurrentPrincipal(c); ok && !(principal.Kind == auth.KindUser && principal.ID == user.ID) {
+		err = database.DB.Model(&data.Session{}).
+			Where("kind = ? AND subject_id = ? AND revoked_at IS NULL", auth.KindUser, user.ID).
+			Update("revoked_at", time.Now()).Error
+	} else {
+		err = revokeSession(c)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
 	// Set the logged_in flag to false
 	user.LoggedIn = false
 	if err := database.DB.Save(&user).Error; err != nil {
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HashPassword returns a bcrypt hash of the plaintext password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// IsHashed reports whether a stored password is already a bcrypt hash
+func IsHashed(stored string) bool {
+	return strings.HasPrefix(stored, "$2a$") || strings.HasPrefix(stored, "$2b$") || strings.HasPrefix(stored, "$2y$")
+}
+
+// VerifyPassword checks a login attempt against a stored password.
+// Rows written before hashing was introduced still hold plaintext; those are
+// compared in constant time and reported via needsRehash so callers can upgrade them.
+func VerifyPassword(stored, attempt string) (ok bool, needsRehash bool) {
+	if IsHashed(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(attempt)) == nil, false
+	}
+	if stored == "" {
+		return false, false
+	}
+	ok = subtle.ConstantTimeCompare([]byte(stored), []byte(attempt)) == 1
+	return ok, ok
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Principal kinds carried in token claims
+const (
+	KindUser      = "user"
+	KindOrganizer = "organizer"
+)
+
+// AccessTokenTTL is how long an access token stays valid
+const AccessTokenTTL = time.Hour
+
+// RefreshTokenTTL is how long a session can be refreshed before logging in again
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// Claims are the fields signed into an access token
+type Claims struct {
+	Kind      string `json:"kind"` // "user" or "organizer"
+	SubjectID uint   `json:"sub"`  // ID of the user or organizer
+	SessionID uint   `json:"sid"`  // ID of the server-side session, used for revocation
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+var (
+	secretOnce sync.Once
+	secret     []byte
+)
+
+// signingKey returns the HMAC key from JWT_SECRET, or a random per-process key if unset
+func signingKey() []byte {
+	secretOnce.Do(func() {
+		if env := os.Getenv("JWT_SECRET"); env != "" {
+			secret = []byte(env)
+			return
+		}
+		log.Println("Warning: JWT_SECRET is not set. Using a random key; tokens will not survive a restart.")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic("failed to generate JWT signing key")
+		}
+	})
+	return secret
+}
+
+// SetSigningKey overrides the HMAC key, mainly for tests
+func SetSigningKey(key []byte) {
+	secretOnce.Do(func() {})
+	secret = key
+}
+
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// IssueAccessToken signs an HS256 JWT for the given principal and session
+func IssueAccessToken(kind string, subjectID, sessionID uint) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(AccessTokenTTL)
+	claims := Claims{
+		Kind:      kind,
+		SubjectID: subjectID,
+		SessionID: sessionID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + sign(unsigned), expiresAt, nil
+}
+
+// ParseAccessToken verifies the signature and expiry of a token and returns its claims
+func ParseAccessToken(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenHeader {
+		return nil, ErrInvalidToken
+	}
+
+	expected := sign(parts[0] + "." + parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.Kind != KindUser && claims.Kind != KindOrganizer {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+
+	return &claims, nil
+}
+
+func sign(unsigned string) string {
+	mac := hmac.New(sha256.New, signingKey())
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// NewRefreshToken returns a random opaque refresh token
+func NewRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashRefreshToken returns the digest stored server-side for a refresh token
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"backend/auth"
+	"backend/data"
+	"backend/database"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// runCommand dispatches a command-line subcommand, e.g. `go run . create-admin -email ...`
+func runCommand(name string, args []string) error {
+	switch name {
+	case "create-admin":
+		return createAdmin(args)
+	case "rebuild-search-index":
+		return rebuildSearchIndex()
+	default:
+		return fmt.Errorf("unknown command %q (available: create-admin, rebuild-search-index)", name)
+	}
+}
+
+// createAdmin creates an admin account, or promotes an existing account with the same email
+func createAdmin(args []string) error {
+	fs := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	name := fs.String("name", "Administrator", "display name for a new account")
+	email := fs.String("email", "", "email address of the admin account (required)")
+	password := fs.String("password", "", "password for a new account (required unless the account exists)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errors.New("create-admin: -email is required")
+	}
+
+	var user data.User
+	err := database.DB.Where("email = ?", *email).First(&user).Error
+	if err == nil {
+		if err := database.DB.Model(&user).Updates(map[string]interface{}{"role": data.RoleAdmin, "banned": false}).Error; err != nil {
+			return err
+		}
+		log.Printf("Promoted existing user %d (%s) to admin", user.ID, user.Email)
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if *password == "" {
+		return errors.New("create-admin: -password is required for a new account")
+	}
+	hashed, err := auth.HashPassword(*password)
+	if err != nil {
+		return err
+	}
+
+	user = data.User{Name: *name, Email: *email, Password: hashed, Role: data.RoleAdmin}
+	if err := database.DB.Create(&user).Error; err != nil {
+		return err
+	}
+	log.Printf("Created admin user %d (%s)", user.ID, user.Email)
+	return nil
+}
+
+// rebuildSearchIndex repopulates the full-text search index from the events table
+func rebuildSearchIndex() error {
+	if !database.FullTextSearch {
+		return errors.New("rebuild-search-index: SQLite was built without FTS5; build with -tags sqlite_fts5")
+	}
+	if err := database.RebuildSearchIndex(database.DB); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := database.DB.Raw("SELECT COUNT(*) FROM events_fts").Scan(&count).Error; err != nil {
+		return err
+	}
+	log.Printf("Rebuilt search index with %d events", count)
+	return nil
+}
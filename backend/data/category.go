@@ -0,0 +1,37 @@
+package data
+
+// UncategorizedSlug is the category of events whose source category has no mapping
+const UncategorizedSlug = "uncategorized"
+
+// Where events come from, as used by category mappings
+const (
+	SourceVisitGainesville = "visit_gainesville"
+	SourceGainesvilleSun   = "gainesville_sun" // The Gainesville Sun's calendar, served by evvnt
+	SourceUser             = "user"            // Created through the API
+	SourceAny              = "*"               // Mappings that apply whatever the source
+)
+
+// Category is a node of the canonical category taxonomy
+type Category struct {
+	ID       uint      `json:"-" gorm:"primaryKey"`
+	Slug     string    `json:"slug" gorm:"not null;uniqueIndex"`
+	Name     string    `json:"name"`
+	ParentID *uint     `json:"-" gorm:"index"` // Nil for top-level categories
+	Parent   *Category `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+}
+
+// CategoryMapping maps a category as a source writes it, slugged, to a canonical category
+type CategoryMapping struct {
+	Source     string    `gorm:"primaryKey"` // One of the Source constants, or SourceAny
+	Value      string    `gorm:"primaryKey"`
+	CategoryID uint      `gorm:"not null;index"`
+	Category   *Category `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// CategoryDTO is a category with the number of published events in it and its subcategories
+type CategoryDTO struct {
+	Slug     string        `json:"slug"`
+	Name     string        `json:"name"`
+	Count    int64         `json:"count"`
+	Children []CategoryDTO `json:"children"`
+}
@@ -1,11 +1,30 @@
 package data
 
+import "time"
+
+// MaxCommentDepth is how deeply replies can nest; root comments are depth 0
+const MaxCommentDepth = 3
+
 // Comment represents a comment on an event
 type Comment struct {
-	EventID   uint   `json:"event_id"`   // ID of the event the comment is associated with
-	UserID    uint   `json:"user_id"`    // ID of the user who made the comment
-	UserName  string `json:"user_name"`  // Name of the user who made the comment
-	Content   string `json:"content"`    // The comment text
-	CreatedAt string `json:"created_at"` // Timestamp when the comment was created
-	Likes     uint   `json:"likes"`      // Number of likes for the comment
+	ID        uint             `json:"id" gorm:"primaryKey"`
+	EventID   uint             `json:"event_id" gorm:"not null;index"` // ID of the event the comment is associated with
+	Event     *Event           `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	ParentID  *uint            `json:"parent_id" gorm:"index"` // Comment this is a reply to; nil for top-level comments
+	Depth     uint             `json:"depth"`                  // 0 for top-level comments, parent depth + 1 for replies
+	UserID    *uint            `json:"user_id" gorm:"index"`   // ID of the user who made the comment; nil once the user is deleted
+	User      *User            `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+	Content   string           `json:"content" gorm:"type:text"` // The comment text
+	Deleted   bool             `json:"deleted"`                  // Deleted comments with replies are kept as placeholders
+	Mentions  []CommentMention `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	Likes     uint             `json:"likes"` // Cached count of the comment's rows in the likes table
+}
+
+// CommentMention records a user @mentioned in a comment
+type CommentMention struct {
+	CommentID uint  `json:"comment_id" gorm:"primaryKey"`
+	UserID    uint  `json:"user_id" gorm:"primaryKey;index"`
+	User      *User `json:"-" gorm:"constraint:OnDelete:CASCADE"`
 }
@@ -0,0 +1,26 @@
+package data
+
+import "time"
+
+type CommentDTO struct {
+	ID         uint         `json:"id"`
+	EventID    uint         `json:"event_id"`
+	ParentID   *uint        `json:"parent_id"`
+	Depth      uint         `json:"depth"`
+	UserID     *uint        `json:"user_id"`
+	UserName   string       `json:"user_name"` // "Deleted User" once the author's account is gone
+	Content    string       `json:"content"`   // Empty for deleted placeholders
+	Deleted    bool         `json:"deleted"`
+	Mentions   []MentionDTO `json:"mentions"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	Edited     bool         `json:"edited"`
+	Likes      uint         `json:"likes"`
+	ReplyCount int          `json:"reply_count"` // All replies below this comment, including ones beyond the returned depth
+	Replies    []CommentDTO `json:"replies"`
+}
+
+type MentionDTO struct {
+	UserID uint   `json:"user_id"`
+	Name   string `json:"name"`
+}
@@ -0,0 +1,28 @@
+package data
+
+import "time"
+
+// Statuses of a DuplicateCandidate
+const (
+	CandidatePending  = "pending"
+	CandidateMerged   = "merged"   // The events were the same and have been merged
+	CandidateDistinct = "distinct" // The events only looked alike
+)
+
+// DuplicateCandidate is a pair of events alike enough to be the same one but
+// not enough to merge them without a moderator's review
+type DuplicateCandidate struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	EventID        uint       `json:"event_id" gorm:"index"`     // The event stored first, kept if they are merged
+	Event          *Event     `json:"-"`                         // Preloaded for review
+	DuplicateID    uint       `json:"duplicate_id" gorm:"index"` // The event that resembled it when it was stored
+	Duplicate      *Event     `json:"-"`
+	Score          float64    `json:"score"`           // How alike the events are, from 0 to 1
+	TitleScore     float64    `json:"title_score"`     // How alike their titles are, from 0 to 1
+	MinutesApart   int        `json:"minutes_apart"`   // Between their start times
+	DistanceMeters *float64   `json:"distance_meters"` // Between their venues; nil when either was not geocoded
+	Status         string     `json:"status" gorm:"index"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	ResolvedBy     *uint      `json:"resolved_by"` // User who reviewed the pair
+}
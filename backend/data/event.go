@@ -1,29 +1,87 @@
 package data
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 // Event represents the event model
 type Event struct {
-	ID              uint      `json:"id" gorm:"primaryKey"`
-	Name            string    `json:"name"`
-	Location        string    `json:"location"`
-	Date            string    `json:"date"`
-	Time            string    `json:"time"`
-	OrganizerID     uint      `json:"organizer_id"`              // Foreign key for the organizer
-	Organizer       Organizer `gorm:"foreignKey:OrganizerID"`    // One-to-one relationship
-	Users           []*User   `gorm:"many2many:event_users"`     // Many-to-many relationship
-	Description     string    `json:"description"`               // Event description
-	Latitude        float64   `json:"latitude"`                  // Latitude for location
-	Longitude       float64   `json:"longitude"`                 // Longitude for location
-	Category        string    `json:"category"`                  // Category of the event
-	Tags            string    `json:"tags" gorm:"type:text"`     // This is the key change
-	Cost            float64   `json:"cost"`                      // Cost of the event
-	Comments        string    `json:"comments" gorm:"type:text"` // List of comments
-	Rating          float64   `json:"rating"`                    // Event rating
-	Active          bool      `json:"active"`                    // Is the event active
-	GoogleMapsLink  string    `json:"google_maps_link"`          // Google Maps directions link
-	Website         string    `json:"website"`                   // Event website link
-	ImageURL        string    `json:"image_url"`                 // URL for the event image
-	TicketsURL      string    `json:"tickets_url"`               // URL for the event tickets
-	MaxParticipants uint      `json:"max_participants"`          // Add max_participants field (type: int)
-	ContactDetails  string    `json:"contact_details"`           // Contact details for event
-	Likes           uint      `json:"likes"`                     // Number of likes for the event
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Name            string     `json:"name"`
+	Location        string     `json:"location"`
+	Date            string     `json:"date"` // Date as given by the source, kept for display
+	Time            string     `json:"time"`
+	StartsAt        *time.Time `json:"starts_at" gorm:"index"` // Parsed start; nil when Date could not be parsed
+	EndsAt          *time.Time `json:"ends_at" gorm:"index"`
+	AllDay          bool       `json:"all_day"`                                   // The source gave a date without a time of day
+	OrganizerID     uint       `json:"organizer_id"`                              // Foreign key for the organizer
+	Organizer       Organizer  `gorm:"foreignKey:OrganizerID"`                    // One-to-one relationship
+	Users           []*User    `gorm:"many2many:event_users"`                     // Many-to-many relationship
+	Description     string     `json:"description"`                               // Event description, as plain text
+	DescriptionHTML string     `json:"description_html" gorm:"type:text"`         // Safe HTML form of the description, when the source gave HTML
+	Latitude        float64    `json:"latitude" gorm:"index:idx_events_lat_lng"`  // Latitude for location, 0 with Longitude when unresolved
+	Longitude       float64    `json:"longitude" gorm:"index:idx_events_lat_lng"` // Longitude for location
+	Category        string     `json:"category"`                                  // Category as given by the source
+	CategoryID      *uint      `json:"category_id" gorm:"index"`                  // Canonical category Category is mapped to
+	CategoryRef     *Category  `json:"-" gorm:"foreignKey:CategoryID"`            // The canonical category, when preloaded
+	Tags            string     `json:"tags" gorm:"type:text"`                     // Comma-separated tags as given by the source
+	TagList         []Tag      `json:"-" gorm:"many2many:event_tags"`             // Tags parsed into the tags table
+	Cost            float64    `json:"cost"`                                      // Lowest price of the event; 0 when free or unknown
+	MaxCost         float64    `json:"max_cost"`                                  // Highest price of the event, Cost for a single price
+	Currency        string     `json:"currency"`                                  // ISO 4217 code of Cost and MaxCost, empty if unknown
+	Free            bool       `json:"free" gorm:"index"`                         // The event can be attended for free
+	CostText        string     `json:"cost_text"`                                 // Cost as given by the source, kept for display
+	Rating          float64    `json:"rating"`                                    // Event rating
+	Active          bool       `json:"active" gorm:"default:true"`                // Unpublished events are hidden from listings
+	Cancelled       bool       `json:"cancelled"`                                 // The source dropped the event before it took place
+	CancelledAt     *time.Time `json:"cancelled_at"`                              // When the event was found cancelled
+	GoogleMapsLink  string     `json:"google_maps_link"`                          // Google Maps directions link
+	Website         string     `json:"website"`                                   // Event website link
+	ImageURL        string     `json:"image_url"`                                 // URL for the event image
+	TicketsURL      string     `json:"tickets_url"`                               // URL for the event tickets
+	MaxParticipants uint       `json:"max_participants"`                          // Add max_participants field (type: int)
+	ContactDetails  string     `json:"contact_details"`                           // Contact details for event
+	Likes           uint       `json:"likes"`                                     // Cached count of the event's rows in the likes table
+	CreatedAt       time.Time  `json:"created_at" gorm:"index"`                   // When the event was added to the tracker
+	Source          string     `json:"source" gorm:"index:idx_events_ext"`        // Where the event came from: a scraper source or "user"; empty if unknown
+	ExternalID      string     `json:"external_id" gorm:"index:idx_events_ext"`   // The source's own ID for the event, stable across scrapes
+	SourceURL       string     `json:"source_url"`                                // Page of the source the event was read from
+	FirstSeenAt     *time.Time `json:"first_seen_at"`                             // When the event was first scraped or created
+	LastSeenAt      *time.Time `json:"last_seen_at"`                              // When a scrape last found the event on its source
+	DuplicateOfID   *uint      `json:"duplicate_of_id" gorm:"index"`              // Event this listing was merged into; merged listings are unpublished
+}
+
+// BeforeSave stores times in UTC so they compare correctly as SQLite text
+func (e *Event) BeforeSave(tx *gorm.DB) error {
+	if e.StartsAt != nil {
+		utc := e.StartsAt.UTC()
+		e.StartsAt = &utc
+	}
+	if e.EndsAt != nil {
+		utc := e.EndsAt.UTC()
+		e.EndsAt = &utc
+	}
+	if e.FirstSeenAt != nil {
+		utc := e.FirstSeenAt.UTC()
+		e.FirstSeenAt = &utc
+	}
+	if e.LastSeenAt != nil {
+		utc := e.LastSeenAt.UTC()
+		e.LastSeenAt = &utc
+	}
+	if !e.CreatedAt.IsZero() {
+		e.CreatedAt = e.CreatedAt.UTC()
+	}
+	return nil
+}
+
+// BeforeCreate sets CreatedAt in UTC, which GORM would otherwise set in the
+// server's local time
+func (e *Event) BeforeCreate(tx *gorm.DB) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	return nil
 }
@@ -1,23 +1,38 @@
 package data
 
+import "time"
+
 type EventDTO struct {
 	ID              uint         `json:"id"`
 	Name            string       `json:"name"`
 	Location        string       `json:"location"`
 	Date            string       `json:"date"`
 	Time            string       `json:"time"`
+	StartsAt        *time.Time   `json:"starts_at"` // ISO-8601 in America/New_York
+	EndsAt          *time.Time   `json:"ends_at"`
+	AllDay          bool         `json:"all_day"`
+	DisplayDate     string       `json:"display_date"` // Human-readable form of StartsAt/EndsAt, or Date if unparsed
 	OrganizerID     uint         `json:"organizer_id"`
 	Organizer       OrganizerDTO `json:"organizer"`
 	Users           []*User      `json:"users"` // optional: sanitize separately if needed
 	Description     string       `json:"description"`
+	DescriptionHTML string       `json:"description_html"` // Sanitized, safe to render; empty unless the source gave HTML
 	Latitude        float64      `json:"latitude"`
 	Longitude       float64      `json:"longitude"`
 	Category        string       `json:"category"`
+	CategoryID      *uint        `json:"category_id"`
+	CategoryRef     *Category    `json:"canonical_category"`
 	Tags            string       `json:"tags"`
+	TagList         []Tag        `json:"tag_list"`
 	Cost            float64      `json:"cost"`
-	Comments        string       `json:"comments"`
+	MaxCost         float64      `json:"max_cost"`
+	Currency        string       `json:"currency"`
+	Free            bool         `json:"free"`
+	CostText        string       `json:"cost_text"`
 	Rating          float64      `json:"rating"`
 	Active          bool         `json:"active"`
+	Cancelled       bool         `json:"cancelled"`
+	CancelledAt     *time.Time   `json:"cancelled_at"`
 	GoogleMapsLink  string       `json:"google_maps_link"`
 	Website         string       `json:"website"`
 	ImageURL        string       `json:"image_url"`
@@ -25,4 +40,14 @@ type EventDTO struct {
 	MaxParticipants uint         `json:"max_participants"`
 	ContactDetails  string       `json:"contact_details"`
 	Likes           uint         `json:"likes"`
+	LikedByMe       bool         `json:"liked_by_me"` // Whether the authenticated user likes the event
+	CreatedAt       time.Time    `json:"created_at"`
+	Source          string       `json:"source"` // Scraper source, "user" for events created through the API, or empty if unknown
+	ExternalID      string       `json:"external_id"`
+	SourceURL       string       `json:"source_url"`
+	FirstSeenAt     *time.Time   `json:"first_seen_at"`
+	LastSeenAt      *time.Time   `json:"last_seen_at"`
+	DuplicateOfID   *uint        `json:"duplicate_of_id"`       // Event to show instead of this one, once merged into it
+	Snippet         string       `json:"snippet,omitempty"`     // Highlighted match, in full-text search results only
+	DistanceKm      *float64     `json:"distance_km,omitempty"` // Distance from the requested point, in geographic queries only
 }
@@ -0,0 +1,19 @@
+package data
+
+import "time"
+
+// Things a user can like
+const (
+	LikeTargetEvent   = "event"
+	LikeTargetComment = "comment"
+)
+
+// Like records that a user likes an event or a comment; a user likes each target at most once
+type Like struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_like_user_target"`
+	User       *User     `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	TargetType string    `json:"target_type" gorm:"not null;uniqueIndex:idx_like_user_target;index:idx_like_target"` // LikeTargetEvent or LikeTargetComment
+	TargetID   uint      `json:"target_id" gorm:"not null;uniqueIndex:idx_like_user_target;index:idx_like_target"`
+	CreatedAt  time.Time `json:"created_at"`
+}
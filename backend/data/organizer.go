@@ -5,8 +5,9 @@ type Organizer struct {
 	ID             uint    `json:"id" gorm:"primaryKey"`
 	Name           string  `json:"name"`
 	Email          string  `json:"email" gorm:"unique,default:null"` // Allow null to ignore unique constraint when empty
-	Password       string  `json:"password"`
+	Password       string  `json:"-"`
 	Description    string  `json:"description"`            // Organizer description
 	ContactDetails string  `json:"contact_details"`        // Contact details for the organizer
+	UserID         *uint   `json:"user_id" gorm:"index"`   // User account that owns this organizer profile, if any
 	Events         []Event `gorm:"foreignKey:OrganizerID"` // One-to-many relationship
 }
@@ -0,0 +1,18 @@
+package data
+
+// Roles an account can hold, from least to most privileged
+const (
+	RoleUser      = "user"
+	RoleOrganizer = "organizer" // Users who publish events, and every organizer account
+	RoleModerator = "moderator" // Can ban users and unpublish events
+	RoleAdmin     = "admin"     // Full access, including role changes and organizer merges
+)
+
+// ValidRole reports whether role is one of the known roles
+func ValidRole(role string) bool {
+	switch role {
+	case RoleUser, RoleOrganizer, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,9 @@
+package data
+
+import "time"
+
+// SchemaMigration records a one-time data migration that has already been applied
+type SchemaMigration struct {
+	ID        string `gorm:"primaryKey"`
+	AppliedAt time.Time
+}
@@ -0,0 +1,39 @@
+package data
+
+import "time"
+
+// Statuses of a ScrapeRun
+const (
+	ScrapeRunning = "running"
+	ScrapeOK      = "ok"      // Every listing was handled
+	ScrapePartial = "partial" // The fetch completed but some listings failed
+	ScrapeFailed  = "failed"  // The fetch stopped early
+)
+
+// ScrapeRun records one scrape of a source
+type ScrapeRun struct {
+	ID         uint          `json:"id" gorm:"primaryKey"`
+	Source     string        `json:"source" gorm:"index"`
+	Trigger    string        `json:"trigger"` // "schedule" or "manual"
+	Status     string        `json:"status"`
+	StartedAt  time.Time     `json:"started_at"`
+	EndedAt    *time.Time    `json:"ended_at"` // Nil while the run is in progress
+	Pages      int           `json:"pages"`    // Pages fetched from the source
+	Seen       int           `json:"seen"`     // Listings the source returned
+	Inserted   int           `json:"inserted"`
+	Updated    int           `json:"updated"`
+	Unchanged  int           `json:"unchanged"`
+	Duplicates int           `json:"duplicates"`
+	Failed     int           `json:"failed"`    // Listings that could not be normalized or stored
+	Cancelled  int           `json:"cancelled"` // Upcoming events the source stopped listing
+	Error      string        `json:"error"`     // Why the fetch stopped, for failed runs
+	Errors     []ScrapeError `json:"errors,omitempty" gorm:"foreignKey:RunID;constraint:OnDelete:CASCADE"`
+}
+
+// ScrapeError is a problem met during a run, usually with one listing
+type ScrapeError struct {
+	ID      uint   `json:"-" gorm:"primaryKey"`
+	RunID   uint   `json:"-" gorm:"index"`
+	Listing string `json:"listing,omitempty"` // What the error is about: an event name or URL
+	Message string `json:"message"`
+}
@@ -0,0 +1,15 @@
+package data
+
+import "time"
+
+// ScrapeState is the scheduler's memory of a source, kept so that a restart
+// picks up the schedule where it left off instead of scraping again at once
+type ScrapeState struct {
+	Source     string    `json:"source" gorm:"primaryKey"`
+	LastRunAt  time.Time `json:"last_run_at"` // When the last run started
+	LastEndAt  time.Time `json:"last_end_at"` // When the last run finished
+	LastStatus string    `json:"last_status"` // Status of the last run
+	LastError  string    `json:"last_error"`  // Why the last run failed, if it did
+	Inserted   int       `json:"inserted"`    // Events the last run added
+	NextRunAt  time.Time `json:"next_run_at"` // When the scheduler will run the source next
+}
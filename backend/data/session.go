@@ -0,0 +1,14 @@
+package data
+
+import "time"
+
+// Session represents a login session backing issued access and refresh tokens
+type Session struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	Kind             string     `json:"kind" gorm:"index:idx_session_subject"`       // "user" or "organizer"
+	SubjectID        uint       `json:"subject_id" gorm:"index:idx_session_subject"` // ID of the user or organizer
+	RefreshTokenHash string     `json:"-" gorm:"uniqueIndex"`                        // SHA-256 of the current refresh token
+	CreatedAt        time.Time  `json:"created_at"`
+	ExpiresAt        time.Time  `json:"expires_at"` // When the refresh token stops being accepted
+	RevokedAt        *time.Time `json:"revoked_at"` // Set on logout; revoked sessions reject all tokens
+}
@@ -0,0 +1,23 @@
+package data
+
+// Tag is a normalized event keyword shared by every event that uses it
+type Tag struct {
+	ID   uint   `json:"-" gorm:"primaryKey"`
+	Slug string `json:"slug" gorm:"not null;uniqueIndex"` // URL-safe form, e.g. "live-music"
+	Name string `json:"name"`                             // Display form, e.g. "Live Music"
+}
+
+// TagSynonym maps an alias to the tag it stands for. Aliases are slugs without
+// separators; every tag has its own, and common variants point at the same tag.
+type TagSynonym struct {
+	Alias string `gorm:"primaryKey"`
+	TagID uint   `gorm:"not null;index"`
+	Tag   *Tag   `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// TagDTO is a tag with the number of published events using it
+type TagDTO struct {
+	Slug  string `json:"slug"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
@@ -1,11 +1,17 @@
 package data
 
+import "time"
+
 // User represents the user model
 type User struct {
-	ID       uint     `json:"id" gorm:"primaryKey"`
-	Name     string   `json:"name"`
-	Email    string   `json:"email" gorm:"unique"`
-	Password string   `json:"password"`
-	Events   []*Event `gorm:"many2many:event_users"` // Many-to-many relationship
-	LoggedIn  bool   `json:"logged_in"` // Flag indicating if the user is logged in
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email" gorm:"unique"`
+	Password  string     `json:"-"`                        // bcrypt hash, never sent in responses
+	Role      string     `json:"role" gorm:"default:user"` // One of the Role* constants; only changed by administrators
+	Banned    bool       `json:"banned"`                   // Banned users cannot log in or use existing tokens
+	BannedAt  *time.Time `json:"banned_at,omitempty"`
+	BanReason string     `json:"ban_reason,omitempty"`
+	Events    []*Event   `gorm:"many2many:event_users"` // Many-to-many relationship
+	LoggedIn  bool       `json:"logged_in"`             // Informational only; requests are authenticated by session tokens
 }
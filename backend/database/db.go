@@ -2,6 +2,7 @@ package database
 
 import (
 	"backend/data"
+	"backend/taxonomy"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -18,10 +19,23 @@ func InitDB() error {
 		return err
 	}
 
-	err = DB.AutoMigrate(&data.User{}, &data.Event{}, &data.Organizer{})
+	return Migrate(DB)
+}
+
+// Migrate brings the schema up to date and applies pending data migrations
+func Migrate(db *gorm.DB) error {
+	err := db.AutoMigrate(&data.User{}, &data.Event{}, &data.Organizer{}, &data.Session{}, &data.Comment{}, &data.CommentMention{}, &data.Like{}, &data.Tag{}, &data.TagSynonym{}, &data.Category{}, &data.CategoryMapping{}, &data.ScrapeState{}, &data.ScrapeRun{}, &data.ScrapeError{}, &data.DuplicateCandidate{}, &data.SchemaMigration{})
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if err := taxonomy.Seed(db); err != nil {
+		return err
+	}
+
+	if err := runMigrations(db); err != nil {
+		return err
+	}
+
+	return ensureSearchIndex(db)
 }
@@ -0,0 +1,451 @@
+package database
+
+import (
+	"backend/data"
+	"backend/dedup"
+	"backend/eventdate"
+	"backend/eventtags"
+	"backend/scraper/sanitize"
+	"backend/taxonomy"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migration is a one-time data change applied after the schema is auto-migrated
+type migration struct {
+	ID  string
+	Run func(tx *gorm.DB) error
+}
+
+// migrations run in order, each at most once per database
+var migrations = []migration{
+	{
+		// Active used to default to false and was never set for scraped events,
+		// so it carried no meaning before events could be unpublished
+		ID: "0001_activate_existing_events",
+		Run: func(tx *gorm.DB) error {
+			return tx.Model(&data.Event{}).Where("active = ?", false).Update("active", true).Error
+		},
+	},
+	{
+		// Comments used to live in a JSON blob on each event
+		ID:  "0002_move_comment_blobs_to_table",
+		Run: moveCommentBlobsToTable,
+	},
+	{
+		// Like counts used to be bare counters anyone could bump; they are now
+		// derived from the likes table, which starts out empty
+		ID: "0003_derive_like_counts",
+		Run: func(tx *gorm.DB) error {
+			if err := tx.Model(&data.Event{}).Where("likes <> 0").UpdateColumn("likes", 0).Error; err != nil {
+				return err
+			}
+			return tx.Model(&data.Comment{}).Where("likes <> 0").UpdateColumn("likes", 0).Error
+		},
+	},
+	{
+		// Dates used to be stored only as display strings in each source's format
+		ID:  "0004_backfill_event_dates",
+		Run: backfillEventDates,
+	},
+	{
+		// Events predating CreatedAt count as added when the column appeared
+		ID: "0005_backfill_event_created_at",
+		Run: func(tx *gorm.DB) error {
+			return tx.Model(&data.Event{}).Where("created_at IS NULL").UpdateColumn("created_at", time.Now().UTC()).Error
+		},
+	},
+	{
+		// Tags used to live only in each event's comma-separated Tags string
+		ID:  "0006_normalize_event_tags",
+		Run: normalizeEventTags,
+	},
+	{
+		// Categories used to be only each source's own free text; the source of
+		// existing events is unknown, so rules for every source are tried
+		ID:  "0007_categorize_events",
+		Run: categorizeEvents,
+	},
+	{
+		// Scraped events used to be matched only on name, date and location
+		ID:  "0008_backfill_external_ids",
+		Run: backfillExternalIDs,
+	},
+	{
+		// Events did not record where they came from or when they were seen
+		ID:  "0009_backfill_event_provenance",
+		Run: backfillEventProvenance,
+	},
+	{
+		// Sources listed the same events under different titles before
+		// duplicates were matched fuzzily; stored events may have likes and
+		// comments, so the pairs are queued for review rather than merged
+		ID:  "0010_queue_existing_duplicates",
+		Run: queueExistingDuplicates,
+	},
+	{
+		// Scraped titles and descriptions used to be stored with the HTML tags,
+		// entities and shortcodes of the source, only their whitespace cleaned
+		ID:  "0011_sanitize_scraped_text",
+		Run: sanitizeScrapedText,
+	},
+	{
+		// Events used to have a single cost, and scraped costs were never
+		// stored; they are read again when next scraped
+		ID: "0012_backfill_cost_ranges",
+		Run: func(tx *gorm.DB) error {
+			return tx.Model(&data.Event{}).Where("max_cost IS NULL").UpdateColumns(map[string]interface{}{
+				"max_cost":  gorm.Expr("cost"),
+				"currency":  "",
+				"free":      false,
+				"cost_text": "",
+			}).Error
+		},
+	},
+	{
+		// Servers outside UTC stored creation times, and the sightings
+		// backfilled from them, in their local time
+		ID:  "0013_store_event_times_in_utc",
+		Run: storeEventTimesInUTC,
+	},
+	{
+		// Organizer profiles created for users used to copy the user's
+		// password; the user now manages them through their own account
+		ID: "0014_clear_copied_organizer_passwords",
+		Run: func(tx *gorm.DB) error {
+			return tx.Model(&data.Organizer{}).
+				Where("user_id IS NOT NULL AND password <> '' AND password = (SELECT password FROM users WHERE users.id = organizers.user_id)").
+				Update("password", "").Error
+		},
+	},
+}
+
+// runMigrations applies every migration that has not been recorded yet
+func runMigrations(db *gorm.DB) error {
+	for _, m := range migrations {
+		var applied int64
+		if err := db.Model(&data.SchemaMigration{}).Where("id = ?", m.ID).Count(&applied).Error; err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Run(tx); err != nil {
+				return err
+			}
+			return tx.Create(&data.SchemaMigration{ID: m.ID, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return err
+		}
+		log.Println("Applied migration:", m.ID)
+	}
+	return nil
+}
+
+// legacyComment is one entry of the old events.comments JSON blob
+type legacyComment struct {
+	UserID    *uint  `json:"user_id"`
+	Content   string `json:"content"`
+	Message   string `json:"message"` // Some early rows used "message" instead of "content"
+	CreatedAt string `json:"created_at"`
+	Likes     uint   `json:"likes"`
+}
+
+func moveCommentBlobsToTable(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn(&data.Event{}, "comments") {
+		return nil
+	}
+
+	var rows []struct {
+		ID       uint
+		Comments string
+	}
+	if err := tx.Table("events").Select("id, comments").Where("comments IS NOT NULL AND comments != ''").Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	migrated := 0
+	for _, row := range rows {
+		var blob []legacyComment
+		if err := json.Unmarshal([]byte(row.Comments), &blob); err != nil {
+			log.Printf("Skipping unreadable comments on event %d: %v", row.ID, err)
+			continue
+		}
+
+		for _, old := range blob {
+			content := old.Content
+			if content == "" {
+				content = old.Message
+			}
+			if strings.TrimSpace(content) == "" {
+				continue
+			}
+
+			// Blobs stored timestamps as display strings in server local time
+			createdAt, err := time.ParseInLocation("January 2, 2006 3:04 PM", old.CreatedAt, time.Local)
+			if err != nil {
+				createdAt = time.Now()
+			}
+
+			// Authors deleted since commenting become "Deleted User"
+			userID := old.UserID
+			if userID != nil {
+				var exists int64
+				if err := tx.Model(&data.User{}).Where("id = ?", *userID).Count(&exists).Error; err != nil {
+					return err
+				}
+				if exists == 0 {
+					userID = nil
+				}
+			}
+
+			comment := data.Comment{
+				EventID:   row.ID,
+				UserID:    userID,
+				Content:   content,
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+				Likes:     old.Likes,
+			}
+			if err := tx.Create(&comment).Error; err != nil {
+				return err
+			}
+			migrated++
+		}
+	}
+	log.Printf("Moved %d comments from event blobs to the comments table", migrated)
+
+	return tx.Migrator().DropColumn(&data.Event{}, "comments")
+}
+
+func backfillEventDates(tx *gorm.DB) error {
+	var events []data.Event
+	if err := tx.Select("id, date, time").Where("starts_at IS NULL AND date != ''").Find(&events).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	unparsed := 0
+	for _, event := range events {
+		if err := eventdate.Schedule(&event, now); err != nil {
+			log.Printf("Leaving date of event %d unparsed (%q): %v", event.ID, event.Date, err)
+			unparsed++
+			continue
+		}
+		if err := tx.Model(&event).UpdateColumns(map[string]interface{}{
+			"starts_at": event.StartsAt.UTC(),
+			"ends_at":   utcOrNil(event.EndsAt),
+			"all_day":   event.AllDay,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Backfilled dates for %d events (%d unparsed)", len(events)-unparsed, unparsed)
+	return nil
+}
+
+func normalizeEventTags(tx *gorm.DB) error {
+	var events []data.Event
+	if err := tx.Select("id, tags").Where("tags IS NOT NULL AND tags != ''").Find(&events).Error; err != nil {
+		return err
+	}
+	for i := range events {
+		if err := eventtags.Apply(tx, &events[i]); err != nil {
+			return err
+		}
+	}
+
+	var tags int64
+	if err := tx.Model(&data.Tag{}).Count(&tags).Error; err != nil {
+		return err
+	}
+	log.Printf("Normalized tags of %d events into %d tags", len(events), tags)
+	return nil
+}
+
+func categorizeEvents(tx *gorm.DB) error {
+	var events []data.Event
+	if err := tx.Select("id, category").Where("category_id IS NULL").Find(&events).Error; err != nil {
+		return err
+	}
+	var uncategorized data.Category
+	if err := tx.Where("slug = ?", data.UncategorizedSlug).Take(&uncategorized).Error; err != nil {
+		return err
+	}
+
+	unmapped := 0
+	for _, event := range events {
+		id, err := taxonomy.Categorize(tx, "", event.Category)
+		if err != nil {
+			return err
+		}
+		if id == uncategorized.ID {
+			unmapped++
+		}
+		if err := tx.Model(&event).UpdateColumn("category_id", id).Error; err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Categorized %d events (%d uncategorized)", len(events), unmapped)
+	return nil
+}
+
+func utcOrNil(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// backfillExternalIDs fills the columns added for update-in-place scraping.
+// Visit Gainesville's external ID is the event's link, which was stored as its
+// website; events of other sources are adopted when next scraped.
+func backfillExternalIDs(tx *gorm.DB) error {
+	// Added columns start out NULL
+	if err := tx.Model(&data.Event{}).Where("source IS NULL").UpdateColumn("source", "").Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&data.Event{}).Where("external_id IS NULL").UpdateColumn("external_id", "").Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&data.Event{}).Where("cancelled IS NULL").UpdateColumn("cancelled", false).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&data.Event{}).
+		Where("external_id = '' AND website LIKE ?", "https://www.visitgainesville.com/%").
+		UpdateColumns(map[string]interface{}{"source": data.SourceVisitGainesville, "external_id": gorm.Expr("website")}).Error
+}
+
+// backfillEventProvenance guesses the source of the events whose source is
+// unknown and dates their sightings from when they were added. Gainesville Sun
+// events are recognizable by their maps link, and events created through the
+// API by an organizer that can log in, which scraped organizers cannot.
+func backfillEventProvenance(tx *gorm.DB) error {
+	if err := tx.Model(&data.Event{}).Where("source_url IS NULL").UpdateColumn("source_url", "").Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&data.Event{}).Where("source = ?", data.SourceVisitGainesville).UpdateColumn("source_url", gorm.Expr("website")).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&data.Event{}).Where("source = '' AND google_maps_link LIKE ?", "https://www.google.com/maps?q=address=%").
+		UpdateColumn("source", data.SourceGainesvilleSun).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&data.Event{}).
+		Where("source = '' AND organizer_id IN (?)", tx.Model(&data.Organizer{}).Select("id").Where("user_id IS NOT NULL OR password != ''")).
+		UpdateColumn("source", data.SourceUser).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&data.Event{}).Where("first_seen_at IS NULL").UpdateColumn("first_seen_at", gorm.Expr("created_at")).Error; err != nil {
+		return err
+	}
+	return tx.Model(&data.Event{}).Where("last_seen_at IS NULL").UpdateColumn("last_seen_at", gorm.Expr("created_at")).Error
+}
+
+// queueExistingDuplicates queues each published event with the earlier one it
+// most resembles, if any
+func queueExistingDuplicates(tx *gorm.DB) error {
+	var events []data.Event
+	if err := tx.Where("active = ? AND cancelled = ? AND duplicate_of_id IS NULL", true, false).Order("id").Find(&events).Error; err != nil {
+		return err
+	}
+	for i := range events {
+		match, found, err := dedup.Find(tx, &events[i])
+		if err != nil {
+			return err
+		}
+		// Pairs are queued once, from their later event
+		if !found || match.Event.ID > events[i].ID {
+			continue
+		}
+		if err := dedup.Queue(tx, events[i].ID, match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sanitizeScrapedText converts the names and descriptions of scraped events,
+// and the names of scraped organizers, to clean text. Visit Gainesville gave
+// descriptions as HTML, which is also kept in its sanitized form.
+func sanitizeScrapedText(tx *gorm.DB) error {
+	if err := tx.Model(&data.Event{}).Where("description_html IS NULL").UpdateColumn("description_html", "").Error; err != nil {
+		return err
+	}
+
+	var events []data.Event
+	if err := tx.Select("id, source, name, description").
+		Where("source IN ?", []string{data.SourceGainesvilleSun, data.SourceVisitGainesville}).Find(&events).Error; err != nil {
+		return err
+	}
+	sanitized := 0
+	for _, event := range events {
+		updates := map[string]interface{}{}
+		if name := sanitize.Line(event.Name); name != event.Name {
+			updates["name"] = name
+		}
+		if description := sanitize.Text(event.Description); description != event.Description {
+			updates["description"] = description
+		}
+		if event.Source == data.SourceVisitGainesville {
+			updates["description_html"] = sanitize.HTML(event.Description)
+		}
+		if len(updates) == 0 {
+			continue
+		}
+		if err := tx.Model(&event).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+		sanitized++
+	}
+
+	// Scraped organizers are the ones that cannot log in
+	var organizers []data.Organizer
+	if err := tx.Select("id, name").Where("user_id IS NULL AND (password IS NULL OR password = '')").Find(&organizers).Error; err != nil {
+		return err
+	}
+	for _, organizer := range organizers {
+		if name := sanitize.Line(organizer.Name); name != organizer.Name {
+			if err := tx.Model(&organizer).UpdateColumn("name", name).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Printf("Sanitized the text of %d of %d scraped events", sanitized, len(events))
+	return nil
+}
+
+// storeEventTimesInUTC rewrites the creation and sighting times not stored in
+// UTC, which compare wrongly as SQLite text against those that are
+func storeEventTimesInUTC(tx *gorm.DB) error {
+	var events []data.Event
+	if err := tx.Select("id, created_at, first_seen_at, last_seen_at").
+		Where("created_at NOT LIKE ? OR first_seen_at NOT LIKE ? OR last_seen_at NOT LIKE ?", "%+00:00", "%+00:00", "%+00:00").
+		Find(&events).Error; err != nil {
+		return err
+	}
+	for _, event := range events {
+		if err := tx.Model(&event).UpdateColumns(map[string]interface{}{
+			"created_at":    event.CreatedAt.UTC(),
+			"first_seen_at": utcOrNil(event.FirstSeenAt),
+			"last_seen_at":  utcOrNil(event.LastSeenAt),
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Stored the times of %d events in UTC", len(events))
+	return nil
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// FullTextSearch reports whether the events_fts index is available. It needs
+// SQLite built with FTS5 (go build -tags sqlite_fts5), without which the
+// server refuses to start; tests built without it fall back to LIKE matching.
+var FullTextSearch bool
+
+// searchIndexColumns are the indexed columns of events_fts, in bm25 weight order
+const searchIndexColumns = "name, description, tags, location, organizer"
+
+// searchIndexRow selects the indexed values for the events matched by where
+const searchIndexRow = `SELECT events.id, events.name, events.description, events.tags, events.location, COALESCE(organizers.name, '')
+	FROM events LEFT JOIN organizers ON organizers.id = events.organizer_id WHERE `
+
+// searchIndexTriggers keep events_fts in step with events and organizer names
+var searchIndexTriggers = map[string]string{
+	"events_fts_insert": `CREATE TRIGGER events_fts_insert AFTER INSERT ON events BEGIN
+		INSERT INTO events_fts(rowid, ` + searchIndexColumns + `) ` + searchIndexRow + `events.id = new.id;
+	END`,
+	"events_fts_update": `CREATE TRIGGER events_fts_update AFTER UPDATE OF name, description, tags, location, organizer_id ON events BEGIN
+		DELETE FROM events_fts WHERE rowid = old.id;
+		INSERT INTO events_fts(rowid, ` + searchIndexColumns + `) ` + searchIndexRow + `events.id = new.id;
+	END`,
+	"events_fts_delete": `CREATE TRIGGER events_fts_delete AFTER DELETE ON events BEGIN
+		DELETE FROM events_fts WHERE rowid = old.id;
+	END`,
+	"events_fts_organizer_update": `CREATE TRIGGER events_fts_organizer_update AFTER UPDATE OF name ON organizers BEGIN
+		DELETE FROM events_fts WHERE rowid IN (SELECT id FROM events WHERE organizer_id = new.id);
+		INSERT INTO events_fts(rowid, ` + searchIndexColumns + `) ` + searchIndexRow + `events.organizer_id = new.id;
+	END`,
+	"events_fts_organizer_delete": `CREATE TRIGGER events_fts_organizer_delete AFTER DELETE ON organizers BEGIN
+		DELETE FROM events_fts WHERE rowid IN (SELECT id FROM events WHERE organizer_id = old.id);
+		INSERT INTO events_fts(rowid, ` + searchIndexColumns + `) ` + searchIndexRow + `events.organizer_id = old.id;
+	END`,
+}
+
+// ensureSearchIndex creates the full-text index and its triggers when FTS5 is
+// available. When it is not, the triggers are dropped so writes to events keep
+// working; the index is rebuilt the next time FTS5 is available.
+func ensureSearchIndex(db *gorm.DB) error {
+	// An existing events_fts table says nothing about this build, so ask SQLite
+	var enabled bool
+	if err := db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled).Error; err != nil {
+		return err
+	}
+	FullTextSearch = enabled
+	if !enabled {
+		log.Println("SQLite was built without FTS5; search falls back to LIKE matching")
+		for name := range searchIndexTriggers {
+			if err := db.Exec("DROP TRIGGER IF EXISTS " + name).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err := db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS events_fts USING fts5(` + searchIndexColumns +
+		`, tokenize = 'porter unicode61 remove_diacritics 2', prefix = '2 3')`).Error
+	if err != nil {
+		return err
+	}
+
+	missing := false
+	for name, ddl := range searchIndexTriggers {
+		var count int64
+		if err := db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name = ?", name).Scan(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			missing = true
+			if err := db.Exec(ddl).Error; err != nil {
+				return err
+			}
+		}
+	}
+	if missing {
+		return RebuildSearchIndex(db)
+	}
+	return nil
+}
+
+// RebuildSearchIndex repopulates events_fts from the events table
+func RebuildSearchIndex(db *gorm.DB) error {
+	if !FullTextSearch {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM events_fts").Error; err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO events_fts(rowid, ` + searchIndexColumns + `) ` + searchIndexRow + `1 = 1`).Error
+	})
+}
@@ -0,0 +1,391 @@
+// Package dedup finds events listed by more than one source and merges them.
+//
+// The sources rarely agree on the details: one lists "Jazz Night at the Depot"
+// at "Depot Park, 874 SE 4th St, Gainesville, FL 32601", another "Jazz Night"
+// at "874 SE 4th St", and their start times may differ by the doors opening.
+// Events are compared on their normalized titles, their parsed start times
+// within a tolerance and the distance between their geocoded venues, falling
+// back to the addresses themselves when either is not geocoded.
+package dedup
+
+import (
+	"backend/data"
+	"backend/eventdate"
+	"backend/eventtags"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/texttheater/golang-levenshtein/levenshtein"
+	"gorm.io/gorm"
+)
+
+const (
+	// TimeTolerance is how far apart the start times of two listings of the
+	// same event may be
+	TimeTolerance = 2 * time.Hour
+
+	// DuplicateScore is the score from which two events are taken to be the
+	// same without review, and ReviewScore the one from which they are queued
+	// for review
+	DuplicateScore = 0.9
+	ReviewScore    = 0.65
+
+	// Venues nearer than sameVenueMeters are the same place, and ones further
+	// apart than otherVenueMeters different places
+	sameVenueMeters  = 150
+	otherVenueMeters = 1000
+
+	// minTitleSimilarity rules out events whose titles have too little in common,
+	// however close their time and place
+	minTitleSimilarity = 0.5
+
+	// candidateLimit caps the events a new one is compared with
+	candidateLimit = 50
+
+	earthRadiusMeters = 6371000.0
+)
+
+// Weights of the title, time and place similarities in a match's score
+const (
+	titleWeight = 0.6
+	timeWeight  = 0.2
+	placeWeight = 0.2
+)
+
+// Match is a stored event resembling another one, and how closely
+type Match struct {
+	Event    data.Event
+	Score    float64       // Weighted similarity from 0 to 1
+	Title    float64       // Similarity of the normalized titles, from 0 to 1
+	Apart    time.Duration // Between the start times; 0 when only the dates could be compared
+	Distance *float64      // Meters between the venues; nil when either is not geocoded
+}
+
+// Duplicate reports whether the match is close enough to merge without review
+func (m Match) Duplicate() bool {
+	return m.Score >= DuplicateScore
+}
+
+// titleStopWords are dropped from titles before comparing them
+var titleStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "at": true, "of": true, "in": true,
+	"on": true, "with": true, "presents": true, "featuring": true, "feat": true, "ft": true,
+}
+
+// addressAbbreviations shorten the words addresses spell out inconsistently
+var addressAbbreviations = map[string]string{
+	"street": "st", "avenue": "ave", "road": "rd", "boulevard": "blvd", "drive": "dr",
+	"lane": "ln", "place": "pl", "court": "ct", "highway": "hwy", "parkway": "pkwy",
+	"north": "n", "south": "s", "east": "e", "west": "w",
+	"northeast": "ne", "northwest": "nw", "southeast": "se", "southwest": "sw",
+	"florida": "fl", "suite": "ste",
+}
+
+// addressStopWords are dropped from addresses before comparing them
+var addressStopWords = map[string]bool{"united": true, "states": true, "usa": true, "us": true}
+
+// words lower-cases a string and splits it into its letters and digits,
+// spelling out ampersands
+func words(value string) []string {
+	value = strings.ReplaceAll(strings.ToLower(value), "&", " and ")
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// NormalizeTitle reduces an event title to the words that tell events apart,
+// e.g. "The Jazz Night @ Depot Park!" to "jazz night depot park"
+func NormalizeTitle(title string) string {
+	var kept []string
+	for _, word := range words(title) {
+		if !titleStopWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// NormalizeAddress reduces an address to a canonical spelling, e.g.
+// "874 S.E. 4th Street, Gainesville, Florida" to "874 se 4th st gainesville fl"
+func NormalizeAddress(address string) string {
+	var kept []string
+	parts := words(address)
+	for i := 0; i < len(parts); i++ {
+		word := parts[i]
+		// "S.E." splits into "s" and "e"
+		if (word == "n" || word == "s") && i+1 < len(parts) && (parts[i+1] == "e" || parts[i+1] == "w") {
+			word += parts[i+1]
+			i++
+		}
+		if abbreviation, ok := addressAbbreviations[word]; ok {
+			word = abbreviation
+		}
+		if !addressStopWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// similarity compares two normalized strings, from 0 to 1. It takes the better
+// of their edit distance, which forgives typos, and the overlap of their words,
+// which forgives words added by one source.
+func similarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := math.Max(float64(len(ra)), float64(len(rb)))
+	edit := 1 - float64(levenshtein.DistanceForStrings(ra, rb, levenshtein.DefaultOptions))/longest
+	return math.Max(edit, wordOverlap(a, b))
+}
+
+// wordOverlap is the Dice coefficient of the words of two strings
+func wordOverlap(a, b string) float64 {
+	wordsA, wordsB := wordSet(a), wordSet(b)
+	shared := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(wordsA)+len(wordsB))
+}
+
+func wordSet(value string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(value) {
+		set[word] = true
+	}
+	return set
+}
+
+// TitleSimilarity compares two event titles once normalized, from 0 to 1
+func TitleSimilarity(a, b string) float64 {
+	return similarity(NormalizeTitle(a), NormalizeTitle(b))
+}
+
+// distanceMeters is the great-circle distance between two points
+func distanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	const toRadians = math.Pi / 180
+	dLat := (lat2 - lat1) * toRadians
+	dLng := (lng2 - lng1) * toRadians
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRadians)*math.Cos(lat2*toRadians)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func geocoded(event *data.Event) bool {
+	return event.Latitude != 0 || event.Longitude != 0
+}
+
+// Compare scores how likely two events are to be the same one. It returns false
+// when their times or places rule it out, or their titles are too different.
+func Compare(a, b *data.Event) (Match, bool) {
+	match := Match{Event: *b, Title: TitleSimilarity(a.Name, b.Name)}
+	if match.Title < minTitleSimilarity {
+		return match, false
+	}
+
+	// Time: an all-day listing matches any time on the same day, and events
+	// whose dates could not be parsed only match the very same date
+	var timeScore float64
+	switch {
+	case a.StartsAt != nil && b.StartsAt != nil:
+		if a.AllDay || b.AllDay {
+			if !eventdate.StartOfDay(*a.StartsAt).Equal(eventdate.StartOfDay(*b.StartsAt)) {
+				return match, false
+			}
+		} else {
+			match.Apart = a.StartsAt.Sub(*b.StartsAt)
+			if match.Apart < 0 {
+				match.Apart = -match.Apart
+			}
+			if match.Apart > TimeTolerance {
+				return match, false
+			}
+		}
+		timeScore = 1 - float64(match.Apart)/float64(2*TimeTolerance)
+	case strings.TrimSpace(a.Date) != "" && strings.TrimSpace(a.Date) == strings.TrimSpace(b.Date):
+		timeScore = 1
+	default:
+		return match, false
+	}
+
+	// Place: the distance between geocoded venues, or else how alike the
+	// addresses are, with no say when either is missing
+	placeScore := 0.5
+	if geocoded(a) && geocoded(b) {
+		distance := distanceMeters(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+		match.Distance = &distance
+		if distance > otherVenueMeters {
+			return match, false
+		}
+		placeScore = math.Min(1, (otherVenueMeters-distance)/(otherVenueMeters-sameVenueMeters))
+	} else if a.Location != "" && b.Location != "" {
+		placeScore = similarity(NormalizeAddress(a.Location), NormalizeAddress(b.Location))
+	}
+
+	match.Score = titleWeight*match.Title + timeWeight*timeScore + placeWeight*placeScore
+	return match, match.Score >= ReviewScore
+}
+
+// Find returns the published event the given one most resembles, if any scores
+// at least ReviewScore. Events already known to be duplicates, cancelled ones
+// and other listings of the event's own source are not considered.
+func Find(tx *gorm.DB, event *data.Event) (Match, bool, error) {
+	query := tx.Model(&data.Event{}).
+		Where("active = ? AND cancelled = ? AND duplicate_of_id IS NULL", true, false).
+		Order("id").Limit(candidateLimit)
+	if event.ID != 0 {
+		query = query.Where("id != ?", event.ID)
+	}
+	if event.Source != "" && event.ExternalID != "" {
+		// A source's listings with IDs of their own are different events
+		query = query.Where("NOT (source = ? AND external_id != '')", event.Source)
+	}
+	if event.StartsAt != nil {
+		from, to := event.StartsAt.Add(-TimeTolerance), event.StartsAt.Add(TimeTolerance)
+		if event.AllDay {
+			from = eventdate.StartOfDay(*event.StartsAt)
+			to = from.AddDate(0, 0, 1)
+		}
+		// Timed events match all-day ones on the same day
+		day := eventdate.StartOfDay(*event.StartsAt)
+		query = query.Where("((starts_at BETWEEN ? AND ?) OR (all_day = ? AND starts_at >= ? AND starts_at < ?) OR (starts_at IS NULL AND date = ?))",
+			from.UTC(), to.UTC(), true, day.UTC(), day.AddDate(0, 0, 1).UTC(), event.Date)
+	} else {
+		query = query.Where("date = ?", event.Date)
+	}
+
+	var candidates []data.Event
+	if err := query.Find(&candidates).Error; err != nil {
+		return Match{}, false, err
+	}
+	var best Match
+	found := false
+	for i := range candidates {
+		if match, ok := Compare(event, &candidates[i]); ok && (!found || match.Score > best.Score) {
+			best, found = match, true
+		}
+	}
+	return best, found, nil
+}
+
+// Merge fills in an event from another listing of it, keeping the richest
+// value of each field: the longer description, any link, image, cost,
+// category, coordinates or parsed date the event lacks, and every tag of both. It
+// returns the columns it changed.
+func Merge(event *data.Event, other data.Event) []string {
+	var changed []string
+	fill := func(column string, field *string, value string) {
+		if *field == "" && value != "" {
+			*field = value
+			changed = append(changed, column)
+		}
+	}
+
+	if event.StartsAt == nil && other.StartsAt != nil {
+		event.Date, event.StartsAt, event.EndsAt, event.AllDay = other.Date, other.StartsAt, other.EndsAt, other.AllDay
+		changed = append(changed, "date", "starts_at", "ends_at", "all_day")
+	} else if event.EndsAt == nil && other.EndsAt != nil && other.StartsAt != nil && event.StartsAt.Equal(*other.StartsAt) {
+		event.EndsAt = other.EndsAt
+		changed = append(changed, "ends_at")
+	}
+	fill("location", &event.Location, other.Location)
+	if len(other.Description) > len(event.Description) {
+		event.Description = other.Description
+		changed = append(changed, "description")
+		if event.DescriptionHTML != other.DescriptionHTML {
+			event.DescriptionHTML = other.DescriptionHTML
+			changed = append(changed, "description_html")
+		}
+	}
+	fill("google_maps_link", &event.GoogleMapsLink, other.GoogleMapsLink)
+	if event.CostText == "" && other.CostText != "" {
+		event.CostText, event.Cost, event.MaxCost, event.Currency, event.Free = other.CostText, other.Cost, other.MaxCost, other.Currency, other.Free
+		changed = append(changed, "cost_text", "cost", "max_cost", "currency", "free")
+	}
+	if event.Category == "" && other.Category != "" {
+		event.Category, event.CategoryID = other.Category, other.CategoryID
+		changed = append(changed, "category", "category_id")
+	}
+	if tags := mergeTags(event.Tags, other.Tags); tags != event.Tags {
+		event.Tags = tags
+		changed = append(changed, "tags")
+	}
+	fill("image_url", &event.ImageURL, other.ImageURL)
+	fill("website", &event.Website, other.Website)
+	fill("tickets_url", &event.TicketsURL, other.TicketsURL)
+	fill("contact_details", &event.ContactDetails, other.ContactDetails)
+	if !geocoded(event) && geocoded(&other) {
+		event.Latitude, event.Longitude = other.Latitude, other.Longitude
+		changed = append(changed, "latitude", "longitude")
+	}
+	if event.OrganizerID == 0 && other.OrganizerID != 0 {
+		event.OrganizerID = other.OrganizerID
+		changed = append(changed, "organizer_id")
+	}
+	return changed
+}
+
+// mergeTags adds the tags of other missing from tags, matched as eventtags
+// matches them
+func mergeTags(tags, other string) string {
+	names := eventtags.Split(tags)
+	seen := make(map[string]bool)
+	for _, name := range names {
+		seen[eventtags.Key(name)] = true
+	}
+	added := false
+	for _, name := range eventtags.Split(other) {
+		if key := eventtags.Key(name); key != "" && !seen[key] {
+			seen[key] = true
+			names = append(names, name)
+			added = true
+		}
+	}
+	if !added {
+		return tags
+	}
+	return strings.Join(names, ", ")
+}
+
+// Absorb merges a duplicate into the event it duplicates and saves the columns
+// that changed, which it returns
+func Absorb(tx *gorm.DB, event *data.Event, duplicate data.Event) ([]string, error) {
+	changed := Merge(event, duplicate)
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	if err := tx.Model(event).Select(changed).Updates(event).Error; err != nil {
+		return nil, err
+	}
+	for _, column := range changed {
+		if column == "tags" {
+			if err := eventtags.Apply(tx, event); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return changed, nil
+}
+
+// Queue holds a match between a newly stored event and an earlier one for a
+// moderator to review
+func Queue(tx *gorm.DB, duplicateID uint, match Match) error {
+	return tx.Create(&data.DuplicateCandidate{
+		EventID:        match.Event.ID,
+		DuplicateID:    duplicateID,
+		Score:          match.Score,
+		TitleScore:     match.Title,
+		MinutesApart:   int(match.Apart / time.Minute),
+		DistanceMeters: match.Distance,
+		Status:         data.CandidatePending,
+	}).Error
+}
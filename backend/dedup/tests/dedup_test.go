@@ -0,0 +1,149 @@
+package dedup_tests
+
+import (
+	"backend/data"
+	"backend/database"
+	"backend/dedup"
+	"backend/eventdate"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupDedupDB(t *testing.T) *gorm.DB {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, database.Migrate(db))
+	return db
+}
+
+// scheduled is an event at the given time in Gainesville
+func scheduled(name, location string, day, hour, minute int, lat, lng float64) data.Event {
+	startsAt := time.Date(2030, 5, day, hour, minute, 0, 0, eventdate.Location)
+	return data.Event{Name: name, Location: location, Date: startsAt.Format("2006-01-02 15:04:05"), StartsAt: &startsAt, Latitude: lat, Longitude: lng}
+}
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := map[string]string{
+		"The Jazz Night @ Depot Park!":  "jazz night depot park",
+		"Wim Tapley & The Cannons":      "wim tapley cannons",
+		"  Tree Fest 2025 ":             "tree fest 2025",
+		"Parker McCollum – Live in GNV": "parker mccollum live gnv",
+	}
+	for title, want := range tests {
+		assert.Equal(t, want, dedup.NormalizeTitle(title), title)
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	assert.Equal(t, "874 se 4th st gainesville fl", dedup.NormalizeAddress("874 S.E. 4th Street, Gainesville, Florida"))
+	assert.Equal(t, dedup.NormalizeAddress("619 South Main Street Gainesville United States"), dedup.NormalizeAddress("619 S Main St, Gainesville, USA"))
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, dedup.TitleSimilarity("The Mountain Grass Unit", "Mountain Grass Unit"))
+	// A typo, and words added by one source
+	assert.Greater(t, dedup.TitleSimilarity("Farmer's Market", "Farmers Markett"), 0.8)
+	assert.InDelta(t, 0.8, dedup.TitleSimilarity("Jazz Night", "Jazz Night at the Depot"), 0.001)
+	assert.Less(t, dedup.TitleSimilarity("Jazz Night", "Poetry Slam"), 0.5)
+}
+
+func TestCompare(t *testing.T) {
+	event := scheduled("Jazz Night at the Depot", "874 SE 4th St, Gainesville, FL", 1, 19, 0, 29.6426, -82.3196)
+
+	// Listed twice, a block apart and with the doors opening earlier
+	other := scheduled("Jazz Night @ the Depot", "Depot Park", 1, 18, 30, 29.6430, -82.3200)
+	match, ok := dedup.Compare(&event, &other)
+	assert.True(t, ok)
+	assert.True(t, match.Duplicate(), match.Score)
+	assert.Equal(t, 30*time.Minute, match.Apart)
+	if assert.NotNil(t, match.Distance) {
+		assert.Less(t, *match.Distance, 100.0)
+	}
+
+	// Alike but not enough to merge unseen
+	other = scheduled("Jazz Night", "874 SE 4th St, Gainesville, FL", 1, 19, 0, 29.6426, -82.3196)
+	match, ok = dedup.Compare(&event, &other)
+	assert.True(t, ok)
+	assert.False(t, match.Duplicate(), match.Score)
+
+	// Too far apart in time or space, or under another title
+	for _, other := range []data.Event{
+		scheduled("Jazz Night at the Depot", "874 SE 4th St", 1, 22, 0, 29.6426, -82.3196),
+		scheduled("Jazz Night at the Depot", "Other side of town", 1, 19, 0, 29.6800, -82.3900),
+		scheduled("Poetry Slam", "874 SE 4th St", 1, 19, 0, 29.6426, -82.3196),
+	} {
+		_, ok := dedup.Compare(&event, &other)
+		assert.False(t, ok, other.Name)
+	}
+
+	// Without coordinates the addresses are compared, and all-day listings
+	// match any time on their day
+	other = scheduled("Jazz Night at the Depot", "874 S.E. 4th Street, Gainesville, Florida", 1, 0, 0, 0, 0)
+	other.AllDay = true
+	match, ok = dedup.Compare(&event, &other)
+	assert.True(t, ok)
+	assert.True(t, match.Duplicate(), match.Score)
+	assert.Nil(t, match.Distance)
+
+	// Unparsed dates only match the very same date
+	unparsed := data.Event{Name: "Book Fair", Date: "Every Saturday", Location: "Library"}
+	same := unparsed
+	_, ok = dedup.Compare(&unparsed, &same)
+	assert.True(t, ok)
+	same.Date = "Every Sunday"
+	_, ok = dedup.Compare(&unparsed, &same)
+	assert.False(t, ok)
+}
+
+func TestFind(t *testing.T) {
+	db := setupDedupDB(t)
+	stored := scheduled("Jazz Night at the Depot", "874 SE 4th St", 1, 19, 0, 29.6426, -82.3196)
+	stored.Source, stored.ExternalID = "gainesville_sun", "1"
+	db.Create(&stored)
+	sameSource := scheduled("Jazz Night at the Depot", "874 SE 4th St", 1, 19, 0, 29.6426, -82.3196)
+	sameSource.Source, sameSource.ExternalID = "visit_gainesville", "a"
+	db.Create(&sameSource)
+	otherDay := scheduled("Jazz Night at the Depot", "874 SE 4th St", 8, 19, 0, 29.6426, -82.3196)
+	db.Create(&otherDay)
+
+	// The other listing of the same source is a different event
+	event := scheduled("Jazz Night - The Depot", "874 SE 4th Street", 1, 19, 30, 29.6427, -82.3197)
+	event.Source, event.ExternalID = "visit_gainesville", "b"
+	match, found, err := dedup.Find(db, &event)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, stored.ID, match.Event.ID)
+
+	// Merged listings are not matched again
+	db.Model(&sameSource).Update("duplicate_of_id", stored.ID)
+	db.Model(&stored).Update("cancelled", true)
+	_, found, err = dedup.Find(db, &event)
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestMerge(t *testing.T) {
+	categoryID := uint(3)
+	event := data.Event{Name: "Jazz Night", Description: "Jazz.", Tags: "Jazz, Live Music", Website: "https://sun.example.com/1"}
+	other := scheduled("Jazz Night at the Depot", "874 SE 4th St", 1, 19, 0, 29.6426, -82.3196)
+	other.Description = "An evening of jazz at Depot Park."
+	other.Tags = "livemusic, Outdoors"
+	other.Website = "https://visit.example.com/a"
+	other.ImageURL = "https://visit.example.com/a.jpg"
+	other.Category, other.CategoryID = "Music", &categoryID
+
+	changed := dedup.Merge(&event, other)
+	assert.Equal(t, []string{"date", "starts_at", "ends_at", "all_day", "location", "description", "category", "category_id", "tags", "image_url", "latitude", "longitude"}, changed)
+	assert.Equal(t, "Jazz Night", event.Name)
+	assert.Equal(t, "https://sun.example.com/1", event.Website)
+	assert.Equal(t, "An evening of jazz at Depot Park.", event.Description)
+	assert.Equal(t, "Jazz, Live Music, Outdoors", event.Tags)
+	assert.Equal(t, &categoryID, event.CategoryID)
+	assert.NotNil(t, event.StartsAt)
+
+	// Nothing left to fill in
+	assert.Empty(t, dedup.Merge(&event, other))
+}
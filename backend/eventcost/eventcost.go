@@ -0,0 +1,141 @@
+// Package eventcost turns the free-text costs given by the sources into a
+// price range.
+package eventcost
+
+import (
+	"backend/data"
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrUnrecognized is returned for costs naming neither a price nor free entry
+var ErrUnrecognized = errors.New("unrecognized cost")
+
+// ErrNegative is returned for entered amounts below zero
+var ErrNegative = errors.New("negative cost")
+
+// Cost is the structured form of an event's cost
+type Cost struct {
+	Min, Max float64 // Lowest and highest price, equal for a single price and 0 when free
+	Currency string  // ISO 4217 code of the prices; empty when the event is only free
+	Free     bool    // The event can be attended for free
+}
+
+var (
+	// An amount, with its currency as a symbol or code before it or a code after it
+	amountPattern = regexp.MustCompile(`(?i)(\$|€|£|\b(?:usd|eur|gbp)\s*)?(\d{1,3}(?:,\d{3})+|\d+)(\.\d+)?(\s*(?:usd|eur|gbp)\b)?`)
+	// What joins the two ends of a range whose second end has no currency, as in "$10-25"
+	rangeSeparator = regexp.MustCompile(`(?i)^\s*(?:-|to)\s*$`)
+	// Free entry, for everyone or for some, such as members
+	freePattern = regexp.MustCompile(`(?i)\b(?:free|no (?:charge|cost)|complimentary)\b`)
+	// Things offered free alongside a priced entry, which do not make it free
+	incidentalFreePattern = regexp.MustCompile(`(?i)\bfree\s+(?:parking|wi-?fi|refreshments|snacks|food|drinks?|popcorn|t-?shirts?|gifts?|giveaways?)\b`)
+	// What may surround amounts without a currency for them to count as prices
+	bareRest = regexp.MustCompile(`(?i)^(?:[\s\-/,+]|\bto\b|\bor\b|\band\b)*$`)
+)
+
+// currencies maps symbols and codes to ISO 4217 codes
+var currencies = map[string]string{"$": "USD", "€": "EUR", "£": "GBP", "usd": "USD", "eur": "EUR", "gbp": "GBP"}
+
+// defaultCurrency is assumed for amounts without one, the sources being American
+const defaultCurrency = "USD"
+
+// Parse reads a cost such as "Free", "$10", "$10–$25", "$15 advance / $20
+// door" or "Free for members, $10 otherwise" into the range of prices it
+// names, which starts at 0 whenever some free entry is stated. Numbers without
+// a currency only count as prices when nothing but other numbers surrounds
+// them, as in "10" or "10-25", so ages, times and dates are not taken for
+// prices.
+func Parse(value string) (Cost, error) {
+	value = strings.NewReplacer("–", "-", "—", "-").Replace(strings.TrimSpace(value))
+	if value == "" {
+		return Cost{}, ErrUnrecognized
+	}
+
+	var amounts []float64
+	var currency string
+	var bare []float64
+	matches := amountPattern.FindAllStringSubmatchIndex(value, -1)
+	for i, m := range matches {
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(value[m[4]:m[5]], ",", "")+submatch(value, m, 3), 64)
+		if err != nil {
+			continue
+		}
+		code := strings.ToLower(strings.TrimSpace(submatch(value, m, 1) + submatch(value, m, 4)))
+		switch {
+		case code != "":
+			if currency == "" {
+				currency = currencies[code]
+			}
+			amounts = append(amounts, amount)
+		case i > 0 && matches[i-1][2] >= 0 && rangeSeparator.MatchString(value[matches[i-1][1]:m[0]]):
+			// The end of a range whose start had a currency
+			amounts = append(amounts, amount)
+		default:
+			bare = append(bare, amount)
+		}
+	}
+	if len(amounts) == 0 && len(bare) > 0 && bareRest.MatchString(amountPattern.ReplaceAllString(value, "")) {
+		amounts, currency = bare, defaultCurrency
+	}
+
+	free := freePattern.MatchString(incidentalFreePattern.ReplaceAllString(value, ""))
+	if len(amounts) == 0 {
+		if !free {
+			return Cost{}, ErrUnrecognized
+		}
+		return Cost{Free: true}, nil
+	}
+	if free {
+		amounts = append(amounts, 0)
+	}
+
+	cost := Cost{Min: math.Inf(1), Max: math.Inf(-1), Currency: currency}
+	for _, amount := range amounts {
+		cost.Min = math.Min(cost.Min, amount)
+		cost.Max = math.Max(cost.Max, amount)
+	}
+	cost.Free = cost.Min == 0
+	return cost, nil
+}
+
+// submatch returns the nth group of a match, or "" if it did not take part
+func submatch(value string, m []int, n int) string {
+	if m[2*n] < 0 {
+		return ""
+	}
+	return value[m[2*n]:m[2*n+1]]
+}
+
+// Price fills an event's Cost, MaxCost, Currency and Free from its CostText.
+// The fields are cleared when CostText cannot be parsed.
+func Price(event *data.Event) error {
+	cost, err := Parse(event.CostText)
+	if err != nil {
+		event.Cost, event.MaxCost, event.Currency, event.Free = 0, 0, "", false
+		return err
+	}
+	event.Cost, event.MaxCost, event.Currency, event.Free = cost.Min, cost.Max, cost.Currency, cost.Free
+	return nil
+}
+
+// PriceAmounts fills an event's Currency and Free from the Cost and MaxCost
+// entered for it, raising MaxCost to Cost when it is lower. Entered amounts
+// are taken to be in the default currency.
+func PriceAmounts(event *data.Event) error {
+	if event.Cost < 0 || event.MaxCost < 0 {
+		return ErrNegative
+	}
+	if event.MaxCost < event.Cost {
+		event.MaxCost = event.Cost
+	}
+	event.Free = event.Cost == 0
+	event.Currency = ""
+	if event.MaxCost > 0 {
+		event.Currency = defaultCurrency
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package eventcost_tests
+
+import (
+	"backend/data"
+	"backend/eventcost"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  eventcost.Cost
+	}{
+		{"Free", eventcost.Cost{Free: true}},
+		{"FREE with park admission", eventcost.Cost{Free: true}},
+		{"$10", eventcost.Cost{Min: 10, Max: 10, Currency: "USD"}},
+		{"$10–$25", eventcost.Cost{Min: 10, Max: 25, Currency: "USD"}},
+		{"$10-25", eventcost.Cost{Min: 10, Max: 25, Currency: "USD"}},
+		{"$15 advance / $20 door", eventcost.Cost{Min: 15, Max: 20, Currency: "USD"}},
+		{"Free - $20", eventcost.Cost{Min: 0, Max: 20, Currency: "USD", Free: true}},
+		{"Admission $0", eventcost.Cost{Currency: "USD", Free: true}},
+		{"$5/vehicle", eventcost.Cost{Min: 5, Max: 5, Currency: "USD"}},
+		{"$1,250.50", eventcost.Cost{Min: 1250.5, Max: 1250.5, Currency: "USD"}},
+		{"12 EUR", eventcost.Cost{Min: 12, Max: 12, Currency: "EUR"}},
+		// Bare numbers count when nothing else is said
+		{"10 - 25", eventcost.Cost{Min: 10, Max: 25, Currency: "USD"}},
+		// Free entry for some makes the event free to attend
+		{"Free for members, $10 for non-members", eventcost.Cost{Min: 0, Max: 10, Currency: "USD", Free: true}},
+		{"free admission; parking $5", eventcost.Cost{Min: 0, Max: 5, Currency: "USD", Free: true}},
+		// Free parking is not free entry
+		{"Free parking, $10 entry", eventcost.Cost{Min: 10, Max: 10, Currency: "USD"}},
+		{"$20, free refreshments", eventcost.Cost{Min: 20, Max: 20, Currency: "USD"}},
+	}
+	for _, tt := range tests {
+		cost, err := eventcost.Parse(tt.value)
+		assert.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, cost, tt.value)
+	}
+
+	// Ages, times and dates are not prices
+	for _, value := range []string{"", "Donations welcome", "Ages 5+", "7pm", "2-5 May"} {
+		_, err := eventcost.Parse(value)
+		assert.ErrorIs(t, err, eventcost.ErrUnrecognized, value)
+	}
+}
+
+func TestPrice(t *testing.T) {
+	event := data.Event{CostText: "$10 – $25"}
+	assert.NoError(t, eventcost.Price(&event))
+	assert.Equal(t, 10.0, event.Cost)
+	assert.Equal(t, 25.0, event.MaxCost)
+	assert.Equal(t, "USD", event.Currency)
+	assert.False(t, event.Free)
+
+	// The fields are cleared when the cost is unreadable
+	event.CostText = "See website"
+	assert.Error(t, eventcost.Price(&event))
+	assert.Zero(t, event.Cost)
+	assert.Zero(t, event.MaxCost)
+	assert.Empty(t, event.Currency)
+}
+
+func TestPriceAmounts(t *testing.T) {
+	// A free flag or currency entered with the amounts is not trusted
+	event := data.Event{Cost: 50, Free: true, Currency: "EUR"}
+	assert.NoError(t, eventcost.PriceAmounts(&event))
+	assert.Equal(t, 50.0, event.MaxCost)
+	assert.Equal(t, "USD", event.Currency)
+	assert.False(t, event.Free)
+
+	event = data.Event{MaxCost: 10}
+	assert.NoError(t, eventcost.PriceAmounts(&event))
+	assert.Equal(t, "USD", event.Currency)
+	assert.True(t, event.Free)
+
+	event = data.Event{Currency: "USD"}
+	assert.NoError(t, eventcost.PriceAmounts(&event))
+	assert.Empty(t, event.Currency)
+	assert.True(t, event.Free)
+
+	assert.ErrorIs(t, eventcost.PriceAmounts(&data.Event{Cost: -5}), eventcost.ErrNegative)
+}
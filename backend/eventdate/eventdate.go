@@ -0,0 +1,237 @@
+// Package eventdate turns the date strings stored by the scrapers and the API
+// into structured start and end times in Gainesville's timezone.
+package eventdate
+
+import (
+	"backend/data"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	_ "time/tzdata" // Embed the zone database so America/New_York loads on any host
+)
+
+// Location is the timezone all events are interpreted and displayed in
+var Location = mustLoadLocation("America/New_York")
+
+// ErrUnrecognized is returned for date strings in none of the known formats
+var ErrUnrecognized = errors.New("unrecognized date format")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// Range is the structured form of an event's date
+type Range struct {
+	Start  time.Time
+	End    *time.Time // Nil when the source gave no end time
+	AllDay bool       // The source gave a date without a time of day
+}
+
+var (
+	// "2025-04-29 20:00:00 - 2025-04-29 23:00:00", as stored for Visit Gainesville
+	dateTimeRangePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}(?::\d{2})?)\s+-\s+(\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}(?::\d{2})?)$`)
+	// "June 15, 10:00 AM - 12:00 PM" or "June 15, 2025, 10:00 AM", as stored by CreateEvent
+	monthDayPattern = regexp.MustCompile(`^([A-Za-z]+ \d{1,2})(?:,? (\d{4}))?(?:,\s*(.*))?$`)
+)
+
+// dateTimeLayouts are the single timestamps seen from the sources, most specific first
+var dateTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// clockLayouts are the times of day accepted alongside a date
+var clockLayouts = []string{"3:04PM", "3PM", "15:04", "15:04:05"}
+
+// Parse reads a stored event date. Dates without a year are placed in the year
+// that puts them closest to now.
+func Parse(value string, now time.Time) (Range, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Range{}, ErrUnrecognized
+	}
+
+	if m := dateTimeRangePattern.FindStringSubmatch(value); m != nil {
+		start, err := parseDateTime(m[1])
+		if err != nil {
+			return Range{}, err
+		}
+		end, err := parseDateTime(m[2])
+		if err != nil {
+			return Range{}, err
+		}
+		return Range{Start: start, End: &end}, nil
+	}
+
+	if start, err := parseDateTime(value); err == nil {
+		return Range{Start: start}, nil
+	}
+
+	if day, err := time.ParseInLocation("2006-01-02", value, Location); err == nil {
+		return Range{Start: day, AllDay: true}, nil
+	}
+
+	if m := monthDayPattern.FindStringSubmatch(value); m != nil {
+		day, err := time.ParseInLocation("January 2", m[1], Location)
+		if err != nil {
+			return Range{}, ErrUnrecognized
+		}
+		if m[2] != "" {
+			day, err = time.ParseInLocation("January 2 2006", m[1]+" "+m[2], Location)
+			if err != nil {
+				return Range{}, ErrUnrecognized
+			}
+		} else {
+			day = nearestYear(day, now)
+		}
+		return WithClock(day, m[3])
+	}
+
+	return Range{}, ErrUnrecognized
+}
+
+// WithClock combines a day with a time of day such as "7:00 PM" or a range like
+// "10:00 AM - 12:00 PM". An empty clock makes an all-day event.
+func WithClock(day time.Time, clock string) (Range, error) {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, Location)
+	clock = strings.TrimSpace(clock)
+	if clock == "" {
+		return Range{Start: day, AllDay: true}, nil
+	}
+
+	parts := strings.SplitN(strings.ReplaceAll(clock, "–", "-"), "-", 2)
+	start, err := parseClock(day, parts[0])
+	if err != nil {
+		return Range{}, err
+	}
+	r := Range{Start: start}
+	if len(parts) == 2 {
+		end, err := parseClock(day, parts[1])
+		if err != nil {
+			return Range{}, err
+		}
+		// A range ending past midnight finishes the next day
+		if end.Before(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+		r.End = &end
+	}
+	return r, nil
+}
+
+func parseDateTime(value string) (time.Time, error) {
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, Location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrUnrecognized
+}
+
+func parseClock(day time.Time, clock string) (time.Time, error) {
+	// Normalise "7 pm", "7:00 p.m." and friends to "7PM" / "7:00PM"
+	clock = strings.ToUpper(strings.TrimSpace(clock))
+	clock = strings.NewReplacer(".", "", " ", "").Replace(clock)
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, clock); err == nil {
+			return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), 0, Location), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: time %q", ErrUnrecognized, clock)
+}
+
+// nearestYear moves a yearless date into the year closest to now
+func nearestYear(day, now time.Time) time.Time {
+	now = now.In(Location)
+	best := day
+	for _, year := range []int{now.Year() - 1, now.Year(), now.Year() + 1} {
+		candidate := time.Date(year, day.Month(), day.Day(), 0, 0, 0, 0, Location)
+		if best.Year() == 0 || absDuration(candidate.Sub(now)) < absDuration(best.Sub(now)) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// Format renders a date range for people, e.g. "Sunday, June 15, 2025, 10:00 AM – 12:00 PM"
+func Format(start time.Time, end *time.Time, allDay bool) string {
+	start = start.In(Location)
+	const dayLayout = "Monday, January 2, 2006"
+	if allDay {
+		if end == nil || sameDay(start, end.In(Location)) {
+			return start.Format(dayLayout)
+		}
+		return start.Format(dayLayout) + " – " + end.In(Location).Format(dayLayout)
+	}
+
+	text := start.Format(dayLayout + ", 3:04 PM")
+	if end == nil {
+		return text
+	}
+	if sameDay(start, end.In(Location)) {
+		return text + " – " + end.In(Location).Format("3:04 PM")
+	}
+	return text + " – " + end.In(Location).Format(dayLayout+", 3:04 PM")
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// Schedule fills an event's StartsAt, EndsAt and AllDay from its Date, using
+// Time for the time of day when Date carries none. An unreadable Time leaves
+// the event all-day; the fields are cleared when Date cannot be parsed.
+func Schedule(event *data.Event, now time.Time) error {
+	r, err := Parse(event.Date, now)
+	if err == nil && r.AllDay && strings.TrimSpace(event.Time) != "" {
+		if timed, clockErr := WithClock(r.Start, event.Time); clockErr == nil {
+			r = timed
+		}
+	}
+	if err != nil {
+		event.StartsAt, event.EndsAt, event.AllDay = nil, nil, false
+		return err
+	}
+	event.StartsAt, event.EndsAt, event.AllDay = &r.Start, r.End, r.AllDay
+	return nil
+}
+
+// StartOfDay returns midnight in Gainesville on the day containing t
+func StartOfDay(t time.Time) time.Time {
+	t = t.In(Location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, Location)
+}
+
+// Today returns the bounds of the current day in Gainesville, end exclusive
+func Today(now time.Time) (time.Time, time.Time) {
+	start := StartOfDay(now)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// ThisWeekend returns the bounds of the current or next weekend in Gainesville,
+// from Friday 5 PM until midnight on Sunday night, end exclusive
+func ThisWeekend(now time.Time) (time.Time, time.Time) {
+	today := StartOfDay(now)
+	daysUntilFriday := (int(time.Friday) - int(today.Weekday()) + 7) % 7
+	if today.Weekday() == time.Saturday || today.Weekday() == time.Sunday {
+		daysUntilFriday -= 7
+	}
+	friday := today.AddDate(0, 0, daysUntilFriday)
+	return friday.Add(17 * time.Hour), friday.AddDate(0, 0, 3)
+}
@@ -0,0 +1,92 @@
+package eventdate_tests
+
+import (
+	"backend/data"
+	"backend/eventdate"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_KnownFormats(t *testing.T) {
+	now := time.Date(2025, 4, 20, 12, 0, 0, 0, eventdate.Location)
+	ny := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, eventdate.Location)
+	}
+
+	tests := []struct {
+		name   string
+		value  string
+		start  time.Time
+		end    *time.Time
+		allDay bool
+	}{
+		{"evvnt date", "2025-04-26", ny(2025, 4, 26, 0, 0), nil, true},
+		{"evvnt timestamp", "2025-04-26T19:30:00", ny(2025, 4, 26, 19, 30), nil, false},
+		{"visit gainesville range", "2025-04-29 20:00:00 - 2025-04-29 23:00:00", ny(2025, 4, 29, 20, 0), ptr(ny(2025, 4, 29, 23, 0)), false},
+		{"api with year", "June 15, 2025, 10:00 AM - 12:00 PM", ny(2025, 6, 15, 10, 0), ptr(ny(2025, 6, 15, 12, 0)), false},
+		{"api without year", "June 15, 7 p.m.", ny(2025, 6, 15, 19, 0), nil, false},
+		{"api without year wraps to last year", "December 28, 9:00 PM - 1:00 AM", ny(2024, 12, 28, 21, 0), ptr(ny(2024, 12, 29, 1, 0)), false},
+		{"api without time", "June 15, ", ny(2025, 6, 15, 0, 0), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := eventdate.Parse(tt.value, now)
+			assert.NoError(t, err)
+			assert.True(t, tt.start.Equal(r.Start), "start: got %v", r.Start)
+			if tt.end == nil {
+				assert.Nil(t, r.End)
+			} else if assert.NotNil(t, r.End) {
+				assert.True(t, tt.end.Equal(*r.End), "end: got %v", *r.End)
+			}
+			assert.Equal(t, tt.allDay, r.AllDay)
+		})
+	}
+
+	_, err := eventdate.Parse("sometime soon", now)
+	assert.ErrorIs(t, err, eventdate.ErrUnrecognized)
+}
+
+func TestSchedule_UsesTimeField(t *testing.T) {
+	now := time.Date(2025, 4, 20, 12, 0, 0, 0, eventdate.Location)
+
+	event := data.Event{Date: "2025-06-15", Time: "10:00 AM - 12:00 PM"}
+	assert.NoError(t, eventdate.Schedule(&event, now))
+	assert.Equal(t, "Sunday, June 15, 2025, 10:00 AM – 12:00 PM", eventdate.Format(*event.StartsAt, event.EndsAt, event.AllDay))
+
+	// An unreadable time keeps the day
+	event = data.Event{Date: "2025-06-15", Time: "TBD"}
+	assert.NoError(t, eventdate.Schedule(&event, now))
+	assert.True(t, event.AllDay)
+	assert.Equal(t, "Sunday, June 15, 2025", eventdate.Format(*event.StartsAt, event.EndsAt, event.AllDay))
+
+	event = data.Event{Date: "whenever"}
+	assert.Error(t, eventdate.Schedule(&event, now))
+	assert.Nil(t, event.StartsAt)
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestPresets_UseGainesvilleTime(t *testing.T) {
+	// 11 PM Tuesday in Gainesville is already Wednesday in UTC
+	tuesdayNight := time.Date(2025, 4, 23, 3, 0, 0, 0, time.UTC)
+	start, end := eventdate.Today(tuesdayNight)
+	assert.Equal(t, time.Date(2025, 4, 22, 0, 0, 0, 0, eventdate.Location), start)
+	assert.Equal(t, time.Date(2025, 4, 23, 0, 0, 0, 0, eventdate.Location), end)
+
+	friday5pm := time.Date(2025, 4, 25, 17, 0, 0, 0, eventdate.Location)
+	mondayMidnight := time.Date(2025, 4, 28, 0, 0, 0, 0, eventdate.Location)
+	for _, now := range []time.Time{
+		tuesdayNight,
+		time.Date(2025, 4, 25, 9, 0, 0, 0, eventdate.Location),  // Friday morning
+		time.Date(2025, 4, 27, 23, 0, 0, 0, eventdate.Location), // Sunday night
+	} {
+		start, end := eventdate.ThisWeekend(now)
+		assert.Equal(t, friday5pm, start, now.String())
+		assert.Equal(t, mondayMidnight, end, now.String())
+	}
+}
@@ -0,0 +1,185 @@
+// Package eventtags parses the tag strings stored by the scrapers and the API
+// into the shared tags table.
+//
+// The sources disagree on format: evvnt keywords arrive lower-cased with all
+// whitespace removed ("liveband,#books"), Visit Gainesville's as Title-Cased
+// words separated by ", " ("Live Band, Books"). Tags are matched on their slug
+// with the separators removed, so both spellings land on the same tag.
+package eventtags
+
+import (
+	"backend/data"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// synonyms point the aliases of common variants at the slug of the tag they mean
+var synonyms = map[string]string{
+	"kids":           "family",
+	"children":       "family",
+	"familyfriendly": "family",
+	"concerts":       "concert",
+	"festivals":      "festival",
+	"workshops":      "workshop",
+	"classes":        "class",
+	"outdoor":        "outdoors",
+	"art":            "arts",
+	"dog":            "dogs",
+	"pet":            "pets",
+	"beers":          "beer",
+	"craftbeer":      "beer",
+}
+
+// Split separates a comma-separated tag string into its entries
+func Split(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Slug turns a tag as written by a source into lower-case letters and digits
+// separated by single hyphens: "#Live Music" becomes "live-music"
+func Slug(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+		} else {
+			pendingHyphen = true
+		}
+	}
+	return b.String()
+}
+
+// Key is the alias a tag name is looked up by: its slug without separators,
+// mapped through the synonyms
+func Key(name string) string {
+	key, _ := canonical(Slug(name))
+	return key
+}
+
+// canonical returns the alias and preferred slug for slug
+func canonical(slug string) (string, string) {
+	key := strings.ReplaceAll(slug, "-", "")
+	if target, ok := synonyms[key]; ok {
+		return strings.ReplaceAll(target, "-", ""), target
+	}
+	return key, slug
+}
+
+// Find looks up the tag a name refers to, returning gorm.ErrRecordNotFound if
+// there is none
+func Find(tx *gorm.DB, name string) (data.Tag, error) {
+	var tag data.Tag
+	key := Key(name)
+	if key == "" {
+		return tag, gorm.ErrRecordNotFound
+	}
+	var tags []data.Tag
+	if err := tx.Joins("JOIN tag_synonyms ON tag_synonyms.tag_id = tags.id").
+		Where("tag_synonyms.alias = ?", key).
+		Limit(1).Find(&tags).Error; err != nil {
+		return tag, err
+	}
+	if len(tags) == 0 {
+		return tag, gorm.ErrRecordNotFound
+	}
+	return tags[0], nil
+}
+
+// Resolve returns the tags for the given names, creating those not seen before.
+// Names that refer to the same tag are returned once.
+func Resolve(tx *gorm.DB, names []string) ([]data.Tag, error) {
+	tags := []data.Tag{}
+	seen := make(map[uint]bool)
+	for _, name := range names {
+		tag, err := resolve(tx, name)
+		if err != nil {
+			return nil, err
+		}
+		if tag.ID == 0 || seen[tag.ID] {
+			continue
+		}
+		seen[tag.ID] = true
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func resolve(tx *gorm.DB, name string) (data.Tag, error) {
+	key, slug := canonical(Slug(name))
+	if key == "" {
+		return data.Tag{}, nil
+	}
+	display := displayName(name, slug)
+
+	tag, err := Find(tx, name)
+	if err == nil {
+		// Prefer the nicer spelling when a source gives one. The old slug keeps
+		// working as an alias.
+		if tag.Name != display && tag.Name == strings.ToLower(tag.Name) && display != strings.ToLower(display) {
+			tag.Name = display
+			if strings.Count(slug, "-") > strings.Count(tag.Slug, "-") {
+				tag.Slug = slug
+			}
+			err = tx.Model(&tag).Updates(map[string]interface{}{"name": tag.Name, "slug": tag.Slug}).Error
+		}
+		return tag, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return tag, err
+	}
+
+	tag = data.Tag{Slug: slug, Name: display}
+	if err := tx.Create(&tag).Error; err != nil {
+		return tag, err
+	}
+	return tag, tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&data.TagSynonym{Alias: key, TagID: tag.ID}).Error
+}
+
+// displayName is how a tag reads in listings: the name as the source wrote it,
+// or the slug in words when the name was mapped to another tag
+func displayName(name, slug string) string {
+	name = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(name), "#"))
+	if Slug(name) == slug {
+		return name
+	}
+	words := strings.Split(slug, "-")
+	for i, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToUpper(first)) + word[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// Apply replaces the tags of a saved event with those parsed from its Tags string
+func Apply(tx *gorm.DB, event *data.Event) error {
+	tags, err := Resolve(tx, Split(event.Tags))
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(event).Association("TagList").Replace(tags); err != nil {
+		return err
+	}
+	event.TagList = tags
+	return nil
+}
+
+// Detach removes the tags of the given events, a list of IDs or a subquery
+func Detach(tx *gorm.DB, eventIDs interface{}) error {
+	return tx.Exec("DELETE FROM event_tags WHERE event_id IN (?)", eventIDs).Error
+}
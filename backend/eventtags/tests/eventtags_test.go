@@ -0,0 +1,92 @@
+package eventtags_tests
+
+import (
+	"backend/data"
+	"backend/database"
+	"backend/eventtags"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTagDB(t *testing.T) *gorm.DB {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, database.Migrate(db))
+	return db
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Live Music":          "live-music",
+		"#books":              "books",
+		"friendsofthelibrary": "friendsofthelibrary",
+		"  Food & Drink ":     "food-drink",
+		"Café Nights":         "café-nights",
+		"--":                  "",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, eventtags.Slug(name), name)
+	}
+}
+
+func TestKey_MatchesBothSourceFormats(t *testing.T) {
+	assert.Equal(t, eventtags.Key("livemusic"), eventtags.Key("Live Music"))
+	assert.Equal(t, eventtags.Key("family"), eventtags.Key("Kids"))
+	assert.NotEqual(t, eventtags.Key("music"), eventtags.Key("livemusic"))
+}
+
+func TestResolve(t *testing.T) {
+	db := setupTagDB(t)
+
+	// evvnt first, then the same tags as Visit Gainesville writes them
+	tags, err := eventtags.Resolve(db, eventtags.Split("liveband,#books,kids"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"liveband", "books", "family"}, slugs(tags))
+	assert.Equal(t, "Family", tags[2].Name)
+
+	tags, err = eventtags.Resolve(db, eventtags.Split("Live Band, Books, Children, Family"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"live-band", "books", "family"}, slugs(tags))
+	assert.Equal(t, "Live Band", tags[0].Name)
+
+	var count int64
+	db.Model(&data.Tag{}).Count(&count)
+	assert.Equal(t, int64(3), count)
+
+	// The old slug still finds the renamed tag
+	tag, err := eventtags.Find(db, "liveband")
+	assert.NoError(t, err)
+	assert.Equal(t, "live-band", tag.Slug)
+
+	_, err = eventtags.Find(db, "nothing")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestApply_ReplacesEventTags(t *testing.T) {
+	db := setupTagDB(t)
+	event := data.Event{Name: "Beer Fest", Tags: "beer,festival,liveband"}
+	db.Create(&event)
+
+	assert.NoError(t, eventtags.Apply(db, &event))
+	assert.Equal(t, []string{"beer", "festival", "liveband"}, slugs(event.TagList))
+
+	event.Tags = "Craft Beer"
+	assert.NoError(t, eventtags.Apply(db, &event))
+	var stored data.Event
+	db.Preload("TagList").First(&stored, event.ID)
+	assert.Equal(t, []string{"beer"}, slugs(stored.TagList))
+
+	event.Tags = ""
+	assert.NoError(t, eventtags.Apply(db, &event))
+	assert.Zero(t, db.Model(&event).Association("TagList").Count())
+}
+
+func slugs(tags []data.Tag) []string {
+	result := []string{}
+	for _, tag := range tags {
+		result = append(result, tag.Slug)
+	}
+	return result
+}
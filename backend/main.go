@@ -3,12 +3,13 @@ package main
 
 import (
 	"backend/api"
+	"backend/data"
 	"backend/database"
 	"backend/scraper"
+	"context"
 	"log"
 	"net/http"
 	"os"
-	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -20,6 +21,19 @@ func main() {
 		panic("Failed to connect to database")
 	}
 
+	// Run a one-off command such as create-admin instead of the server
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Search relies on SQLite's full-text index, which needs the sqlite_fts5 build tag
+	if !database.FullTextSearch {
+		log.Fatal("SQLite was built without FTS5; build the server with -tags sqlite_fts5")
+	}
+
 	// Prepare the router
 	r := gin.Default()
 
@@ -28,40 +42,79 @@ func main() {
 		AllowOrigins:     []string{"*"}, // Allow all origins for deployment
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Content-Type", "Authorization", "Origin", "Connection", "Upgrade"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "X-Total-Count", "X-Next-Cursor"},
 		AllowCredentials: true,
 		MaxAge:           12 * 3600, // Cache preflight response for 12 hours
 	}
 
 	r.Use(cors.New(corsConfig))
 
+	// Routes that change state require a valid session token
+	authed := r.Group("/", api.RequireAuth())
+
+	// Auth APIs
+	r.POST("/refreshToken", api.RefreshToken)
+
 	// User APIs
 	r.POST("/addUser", api.AddUser)
 	r.GET("/user/:id", api.GetUserByID)
-	r.PUT("/editUser/:id", api.EditUserInfo)
-	r.DELETE("/users/:id", api.RemoveUser)
+	authed.PUT("/editUser/:id", api.AuthorizeUserParam("id"), api.EditUserInfo)
+	authed.DELETE("/users/:id", api.AuthorizeUserParam("id"), api.RemoveUser)
 	r.POST("/register", api.RegisterUser)
 	r.POST("/LoginUser", api.LoginUser)
-	r.POST("/logout/:id", api.LogoutUser)
+	authed.POST("/logout/:id", api.AuthorizeUserParam("id"), api.LogoutUser)
 
 	// Event APIs
-	r.POST("/CreateEvent", api.CreateEvent)
-	r.GET("/GetAllEvents", api.GetAllEvents)
-	r.GET("/GetEvent/:id", api.GetEventByID)
-	r.PUT("/EditEvent/:id", api.EditEvent)
-	r.DELETE("/DeleteEvent/:id", api.DeleteEvent)
-	r.POST("/mapUserToEvent", api.MapUserToEvent)
-	r.POST("/unmapUserFromEvent", api.UnmapUserFromEvent)
-	r.GET("/user/:id/GetUserRegisteredEvents", api.GetRegisteredEvents)
+	authed.POST("/CreateEvent", api.CreateEvent)
+	r.GET("/GetAllEvents", api.OptionalAuth(), api.GetAllEvents)
+	r.GET("/GetEvent/:id", api.OptionalAuth(), api.GetEventByID)
+	r.GET("/events/search", api.OptionalAuth(), api.SearchEvents)
+	r.GET("/events/nearby", api.OptionalAuth(), api.GetNearbyEvents)
+	r.GET("/events/in_bounds", api.OptionalAuth(), api.GetEventsInBounds)
+	r.GET("/tags", api.GetTags)
+	r.GET("/tags/:slug/events", api.OptionalAuth(), api.GetTagEvents)
+	r.GET("/categories", api.GetCategories)
+	r.GET("/categories/:slug/events", api.OptionalAuth(), api.GetCategoryEvents)
+	authed.PUT("/EditEvent/:id", api.AuthorizeEventParam("id"), api.EditEvent)
+	authed.DELETE("/DeleteEvent/:id", api.AuthorizeEventParam("id"), api.DeleteEvent)
+	authed.POST("/mapUserToEvent", api.MapUserToEvent)
+	authed.POST("/unmapUserFromEvent", api.UnmapUserFromEvent)
+	r.GET("/user/:id/GetUserRegisteredEvents", api.OptionalAuth(), api.GetRegisteredEvents)
+	authed.PUT("/events/:id/like", api.LikeEvent)
+	authed.DELETE("/events/:id/like", api.UnlikeEvent)
 	r.POST("/createOrganizer", api.CreateOrganizer)
-	r.DELETE("/deleteOrganizer/:id", api.DeleteOrganizer)
+	authed.DELETE("/deleteOrganizer/:id", api.AuthorizeOrganizerParam("id"), api.DeleteOrganizer)
 	r.POST("/loginOrganizer", api.LoginOrganizer)
-	r.POST("/events/:id/comments", api.AddCommentToEvent)
+	authed.POST("/logoutOrganizer", api.LogoutOrganizer)
+	authed.POST("/events/:id/comments", api.AddCommentToEvent)
 	r.GET("/events/:event_id/GetAllComments", api.GetAllComments)
+	authed.PUT("/comments/:id", api.AuthorizeCommentParam("id"), api.EditComment)
+	authed.DELETE("/comments/:id", api.AuthorizeCommentParam("id"), api.DeleteComment)
+	authed.PUT("/comments/:id/like", api.LikeComment)
+	authed.DELETE("/comments/:id/like", api.UnlikeComment)
 	r.GET("/event/:event_id/users", api.GetUsersByEvent)
 	r.GET("/ws", api.WebSocketHandler)
 	r.GET("/event/:event_id/weather", api.GetWeatherByEventID)
 
+	// Admin APIs; moderators can moderate, follow scrapes and review duplicates, only admins can change roles, merge or link organizers or start scrapes
+	admin := r.Group("/admin", api.RequireAuth(), api.RequireRole(data.RoleModerator, data.RoleAdmin))
+	admin.GET("/users", api.AdminListUsers)
+	admin.POST("/users/:id/ban", api.AdminBanUser)
+	admin.POST("/users/:id/unban", api.AdminUnbanUser)
+	admin.POST("/events/:id/unpublish", api.AdminSetEventActive(false))
+	admin.POST("/events/:id/publish", api.AdminSetEventActive(true))
+	admin.GET("/scrapes", api.AdminListScrapes)
+	admin.GET("/scrapes/health", api.AdminScrapeHealth)
+	admin.GET("/scrapes/:id", api.AdminGetScrape)
+	admin.GET("/duplicates", api.AdminListDuplicates)
+	admin.POST("/duplicates/:id/merge", api.AdminMergeDuplicate)
+	admin.POST("/duplicates/:id/distinct", api.AdminDismissDuplicate)
+	adminOnly := admin.Group("/", api.RequireRole(data.RoleAdmin))
+	adminOnly.PUT("/users/:id/role", api.AdminSetUserRole)
+	adminOnly.POST("/organizers/merge", api.AdminMergeOrganizers)
+	adminOnly.PUT("/organizers/:id/user", api.AdminLinkOrganizer)
+	adminOnly.POST("/scrapes", api.AdminTriggerScrape)
+
 	// SQLite version
 	r.GET("/sqlite-version", getSQLiteVersion)
 
@@ -70,15 +123,8 @@ func main() {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
-	// Start scraper in the background
-	go func() {
-		log.Println("Waiting before starting scraper...")
-		time.Sleep(10 * time.Second) // Add a delay to let the server start first
-		log.Println("Starting scraper...")
-		scraper.ScrapeGainesvilleSun()
-		scraper.ScrapeVisitGainesville()
-		log.Println("Scraping completed")
-	}()
+	// Scrape the event sources periodically in the background
+	scraper.DefaultScheduler.Start(context.Background())
 
 	// Determine port and start server (this will block until the server stops)
 	port := os.Getenv("PORT")
@@ -0,0 +1,150 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+// UserAgent identifies the tracker to the sites it scrapes, with where to
+// find out about it
+const UserAgent = "GNVEventTracker/1.0 (+https://github.com/SkSadaf/GNV-Event-Tracker)"
+
+// How hard sources may crawl. Each site gets at most Parallelism requests at
+// a time, each followed by Delay, plus up to half as much again at random,
+// before the next one. Requests that fail in a way that may pass are retried
+// up to MaxRetries times, waiting RetryBackoff and doubling each time, or as
+// long as the site's Retry-After asks, up to MaxRetryWait. The tests lower
+// them to run quickly.
+var (
+	Parallelism  = 2
+	Delay        = time.Second
+	MaxRetries   = 3
+	RetryBackoff = 2 * time.Second
+	MaxRetryWait = time.Minute
+)
+
+// siteHosts returns the hosts of the given URLs without "www.", once each
+func siteHosts(urls ...string) []string {
+	var hosts []string
+	seen := make(map[string]bool)
+	for _, value := range urls {
+		u, err := url.Parse(value)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		host := strings.TrimPrefix(u.Host, "www.")
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// newCollector returns an asynchronous collector for a source that visits
+// only the sites of the given URLs, tells them who is crawling, obeys their
+// robots.txt and keeps to the crawling limits. Its clones share the limits, so
+// a source's requests to a site are limited together.
+func newCollector(urls ...string) *colly.Collector {
+	collector := colly.NewCollector(
+		colly.AllowedDomains(allowedDomains(urls...)...),
+		colly.UserAgent(UserAgent),
+		colly.Async(true),
+	)
+	// colly ignores robots.txt unless told otherwise
+	collector.IgnoreRobotsTxt = false
+	// robots.txt is fetched without the collector's user agent
+	collector.WithTransport(userAgentTransport{http.DefaultTransport})
+
+	for _, host := range siteHosts(urls...) {
+		// A rule limits the requests to all the domains it matches together,
+		// so each site gets its own
+		collector.Limit(&colly.LimitRule{
+			DomainRegexp: `^(www\.)?` + regexp.QuoteMeta(host) + `$`,
+			Parallelism:  Parallelism,
+			Delay:        Delay,
+			RandomDelay:  Delay / 2,
+		})
+	}
+	return collector
+}
+
+// userAgentTransport sends UserAgent with requests that have no user agent of
+// their own, such as colly's robots.txt requests
+type userAgentTransport struct {
+	http.RoundTripper
+}
+
+func (t userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", UserAgent)
+	}
+	return t.RoundTripper.RoundTrip(req)
+}
+
+// attemptKey counts the retries of a request in its context
+const attemptKey = "scraper.attempt"
+
+// onFailure retries the collector's requests that fail in a way that may pass,
+// on a network error, 429 Too Many Requests or a server error, and calls
+// failed with those that cannot succeed or are still failing after MaxRetries.
+// Retries are abandoned once ctx is done. Sources register it in place of
+// OnError.
+func onFailure(ctx context.Context, collector *colly.Collector, failed func(r *colly.Response, err error)) {
+	collector.OnError(func(r *colly.Response, err error) {
+		attempt, _ := r.Request.Ctx.GetAny(attemptKey).(int)
+		if attempt >= MaxRetries || !retryable(r) {
+			failed(r, err)
+			return
+		}
+
+		timer := time.NewTimer(retryWait(r, attempt))
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			failed(r, err)
+		case <-timer.C:
+			r.Request.Ctx.Put(attemptKey, attempt+1)
+			if retryErr := r.Request.Retry(); retryErr != nil {
+				failed(r, retryErr)
+			}
+		}
+	})
+}
+
+// retryable reports whether a failed request may succeed if sent again
+func retryable(r *colly.Response) bool {
+	return r.StatusCode == 0 || r.StatusCode == http.StatusTooManyRequests || r.StatusCode >= 500
+}
+
+// retryWait returns how long to wait before retrying a request for the nth
+// time since its first attempt: RetryBackoff doubled n times, or longer if the
+// site's Retry-After asks, but no longer than MaxRetryWait
+func retryWait(r *colly.Response, n int) time.Duration {
+	wait := RetryBackoff << n
+	if r.Headers != nil {
+		if value := r.Headers.Get("Retry-After"); value != "" {
+			if seconds, err := strconv.Atoi(value); err == nil {
+				if after := time.Duration(seconds) * time.Second; after > wait {
+					wait = after
+				}
+			} else if at, err := http.ParseTime(value); err == nil {
+				if after := time.Until(at); after > wait {
+					wait = after
+				}
+			}
+		}
+	}
+	if wait > MaxRetryWait {
+		wait = MaxRetryWait
+	}
+	return wait
+}
@@ -0,0 +1,193 @@
+package scraper
+
+import (
+	"backend/data"
+	"backend/scraper/sanitize"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/gocolly/colly"
+)
+
+func init() {
+	Register(&GainesvilleSun{
+		EventsURL: "https://discovery.evvnt.com/api/publisher/458/home_page_events?hitsPerPage=30&page=%d&publisher_id=458",
+		MaxPages:  5,
+	})
+}
+
+// GainesvilleSun scrapes the Gainesville Sun's event calendar, served by the
+// evvnt discovery API
+type GainesvilleSun struct {
+	EventsURL string // Events API, with %d for the page number starting at 0
+	MaxPages  int    // Last page fetched
+}
+
+// gainesvilleSunEvent is one entry of the events API
+type gainesvilleSunEvent struct {
+	ID          json.Number `json:"id"`
+	Title       string      `json:"title"`
+	StartDate   string      `json:"start_date"`
+	Description string      `json:"description"`
+	Keywords    string      `json:"keywords"`
+	Category    string      `json:"category_name"`
+	Organizer   string      `json:"organiser_name"`
+	Venue       struct {
+		Name      string  `json:"name"`
+		Address1  string  `json:"address_1"`
+		Address2  string  `json:"address_2"`
+		Town      string  `json:"town"`
+		Country   string  `json:"country"`
+		PostCode  string  `json:"post_code"`
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	} `json:"venue"`
+	Links struct {
+		Tickets string `json:"Tickets,omitempty"`
+		Website string `json:"Website,omitempty"`
+	} `json:"links"`
+	Images  json.RawMessage `json:"images"`
+	Contact struct {
+		Email string `json:"email,omitempty"`
+		Tel   string `json:"tel,omitempty"`
+	} `json:"contact,omitempty"`
+	Prices []struct {
+		Name         string      `json:"name"`
+		Value        json.Number `json:"value"`
+		CurrencyCode string      `json:"currency_code"`
+	} `json:"prices,omitempty"`
+}
+
+// gainesvilleSunListing is an API event with the page it was read from
+type gainesvilleSunListing struct {
+	Event   gainesvilleSunEvent
+	PageURL string
+}
+
+// gainesvilleSunImage is an entry of an event's images, which the API sends as
+// either one object or an array
+type gainesvilleSunImage struct {
+	Original struct {
+		URL string `json:"url"`
+	} `json:"original"`
+}
+
+// Name implements Source
+func (s *GainesvilleSun) Name() string {
+	return data.SourceGainesvilleSun
+}
+
+// Fetch implements Source, returning gainesvilleSunListings
+func (s *GainesvilleSun) Fetch(ctx context.Context) ([]interface{}, error) {
+	collector := newCollector(s.EventsURL)
+
+	// Pages are read one at a time, as each tells whether there is a next
+	var page struct {
+		RawEvents []gainesvilleSunEvent `json:"rawEvents"`
+	}
+	var fetchErr, parseErr error
+	collector.OnResponse(func(r *colly.Response) {
+		parseErr = json.Unmarshal(r.Body, &page)
+	})
+	onFailure(ctx, collector, func(r *colly.Response, err error) {
+		fetchErr = err
+	})
+
+	var listings []interface{}
+	for n := 0; n <= s.MaxPages; n++ {
+		if err := ctx.Err(); err != nil {
+			return listings, err
+		}
+
+		page.RawEvents, fetchErr, parseErr = nil, nil, nil
+		apiURL := fmt.Sprintf(s.EventsURL, n)
+		err := collector.Visit(apiURL)
+		collector.Wait()
+		if err == nil {
+			err = fetchErr
+		}
+		if err != nil {
+			return listings, fmt.Errorf("fetching %s: %w", apiURL, err)
+		}
+		if parseErr != nil {
+			return listings, fmt.Errorf("parsing %s: %w", apiURL, parseErr)
+		}
+		PageFetched(ctx)
+		if len(page.RawEvents) == 0 {
+			return listings, nil
+		}
+		for _, event := range page.RawEvents {
+			listings = append(listings, gainesvilleSunListing{Event: event, PageURL: apiURL})
+		}
+	}
+	// Stopped at the page limit with events left, perhaps
+	MarkIncomplete(ctx)
+	return listings, nil
+}
+
+// Normalize implements Source
+func (s *GainesvilleSun) Normalize(listing interface{}) (RawEvent, error) {
+	l, ok := listing.(gainesvilleSunListing)
+	if !ok {
+		return RawEvent{}, fmt.Errorf("unexpected listing type %T", listing)
+	}
+	event := l.Event
+
+	location := CleanWhiteSpaces(fmt.Sprintf("%s %s %s %s %s",
+		event.Venue.Address1,
+		event.Venue.Address2,
+		event.Venue.Town,
+		event.Venue.Country,
+		event.Venue.PostCode,
+	))
+
+	var imageURL string
+	var image gainesvilleSunImage
+	var images []gainesvilleSunImage
+	if err := json.Unmarshal(event.Images, &image); err == nil {
+		imageURL = image.Original.URL
+	} else if err := json.Unmarshal(event.Images, &images); err == nil {
+		if len(images) > 0 {
+			imageURL = images[0].Original.URL
+		}
+	} else if len(event.Images) > 0 {
+		log.Printf("%s: error parsing images of %q: %v", s.Name(), event.Title, err)
+	}
+
+	// Each ticket type as "General Admission $25", for eventcost to read
+	var prices []string
+	for _, price := range event.Prices {
+		amount := price.Value.String()
+		if price.CurrencyCode == "" || strings.EqualFold(price.CurrencyCode, "USD") {
+			amount = "$" + amount
+		} else {
+			amount += " " + price.CurrencyCode
+		}
+		prices = append(prices, strings.TrimSpace(CleanWhiteSpaces(price.Name)+" "+amount))
+	}
+
+	return RawEvent{
+		ExternalID:     event.ID.String(),
+		SourceURL:      l.PageURL,
+		Name:           sanitize.Line(event.Title),
+		Date:           CleanWhiteSpaces(event.StartDate),
+		Location:       location,
+		Description:    sanitize.Text(event.Description),
+		Category:       event.Category,
+		Tags:           RemoveWhiteSpaces(event.Keywords),
+		Cost:           strings.Join(prices, " / "),
+		Latitude:       event.Venue.Latitude,
+		Longitude:      event.Venue.Longitude,
+		OrganizerName:  sanitize.Line(event.Organizer),
+		OrganizerEmail: CleanWhiteSpaces(event.Contact.Email),
+		OrganizerTel:   CleanWhiteSpaces(event.Contact.Tel),
+		GoogleMapsLink: "https://www.google.com/maps?q=" + fmt.Sprintf("address=%s", url.QueryEscape(location)),
+		ImageURL:       imageURL,
+		WebsiteURL:     event.Links.Website,
+		TicketsURL:     event.Links.Tickets,
+	}, nil
+}
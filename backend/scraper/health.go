@@ -0,0 +1,103 @@
+package scraper
+
+import (
+	"backend/data"
+	"backend/database"
+	"time"
+)
+
+// Health statuses of a source
+const (
+	HealthOK       = "ok"
+	HealthFailing  = "failing"   // The last run failed
+	HealthStale    = "stale"     // No successful run for over two intervals
+	HealthNeverRun = "never_run" // No run has finished yet
+	HealthDisabled = "disabled"  // Left out by SCRAPER_SOURCES or SCRAPER_DISABLED_SOURCES
+)
+
+// healthRuns is how many recent runs are looked at for consecutive failures
+const healthRuns = 50
+
+// SourceHealth sums up how scraping a source has been going
+type SourceHealth struct {
+	Source              string          `json:"source"`
+	Status              string          `json:"status"`
+	Enabled             bool            `json:"enabled"`
+	Running             bool            `json:"running"`
+	Interval            string          `json:"interval"`
+	LastRun             *data.ScrapeRun `json:"last_run"`        // The last finished run
+	LastSuccessAt       *time.Time      `json:"last_success_at"` // When the last run that did not fail ended
+	ConsecutiveFailures int             `json:"consecutive_failures"`
+	NextRunAt           *time.Time      `json:"next_run_at"`
+}
+
+// Health reports on every registered source
+func (s *Scheduler) Health() ([]SourceHealth, error) {
+	enabled := make(map[string]bool)
+	for _, source := range Enabled() {
+		enabled[source.Name()] = true
+	}
+
+	report := []SourceHealth{}
+	for _, source := range Sources() {
+		name := source.Name()
+		schedule := ScheduleFor(name)
+		health := SourceHealth{
+			Source:   name,
+			Enabled:  enabled[name],
+			Running:  s.Running(name),
+			Interval: schedule.Interval.String(),
+		}
+
+		var runs []data.ScrapeRun
+		if err := database.DB.Where("source = ? AND status != ?", name, data.ScrapeRunning).
+			Order("started_at DESC, id DESC").Limit(healthRuns).Find(&runs).Error; err != nil {
+			return nil, err
+		}
+		for i := range runs {
+			if runs[i].Status != data.ScrapeFailed {
+				health.LastSuccessAt = runs[i].EndedAt
+				break
+			}
+			health.ConsecutiveFailures++
+		}
+		if len(runs) > 0 {
+			health.LastRun = &runs[0]
+		}
+		if health.LastSuccessAt == nil && health.ConsecutiveFailures == healthRuns {
+			// Older runs may have succeeded
+			var succeeded []data.ScrapeRun
+			if err := database.DB.Where("source = ? AND status IN ?", name, []string{data.ScrapeOK, data.ScrapePartial}).
+				Order("started_at DESC, id DESC").Limit(1).Find(&succeeded).Error; err != nil {
+				return nil, err
+			}
+			if len(succeeded) > 0 {
+				health.LastSuccessAt = succeeded[0].EndedAt
+			}
+		}
+
+		state, err := State(name)
+		if err != nil {
+			return nil, err
+		}
+		if health.Enabled && !state.NextRunAt.IsZero() {
+			health.NextRunAt = &state.NextRunAt
+		}
+
+		staleAfter := 2*schedule.Interval + schedule.Jitter
+		switch {
+		case !health.Enabled:
+			health.Status = HealthDisabled
+		case health.LastRun == nil:
+			health.Status = HealthNeverRun
+		case health.LastRun.Status == data.ScrapeFailed:
+			health.Status = HealthFailing
+		case health.LastSuccessAt == nil || time.Since(*health.LastSuccessAt) > staleAfter:
+			health.Status = HealthStale
+		default:
+			health.Status = HealthOK
+		}
+		report = append(report, health)
+	}
+	return report, nil
+}
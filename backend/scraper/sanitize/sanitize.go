@@ -0,0 +1,323 @@
+// Package sanitize turns the HTML sources publish into clean text, or into a
+// small subset of HTML that is safe to render as is.
+package sanitize
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// shortcodePattern matches a WordPress shortcode tag such as [caption id="1"],
+// [/caption] or [gallery ids="1,2" /]
+var shortcodePattern = regexp.MustCompile(`\[(/?)([a-zA-Z][\w-]*)((?:\s[^\[\]]*)?)\]`)
+
+// shortcodes are the shortcodes commonly found in event descriptions, which
+// are stripped even without attributes
+var shortcodes = map[string]bool{
+	"audio": true, "button": true, "caption": true, "embed": true, "gallery": true,
+	"playlist": true, "video": true, "wp_caption": true,
+}
+
+// droppedShortcodes are stripped along with their content, a URL or markup
+// that only means something to WordPress
+var droppedShortcodes = map[string]bool{"embed": true, "audio": true, "video": true}
+
+// StripShortcodes removes WordPress shortcodes, keeping the text they enclose.
+// Brackets in prose, such as "[sic]", are left alone: a bracketed word only
+// counts as a shortcode if it has attributes, is closed later on, contains an
+// underscore as page-builder shortcodes do, or is a common WordPress one.
+func StripShortcodes(s string) string {
+	closed := map[string]bool{}
+	for _, m := range shortcodePattern.FindAllStringSubmatch(s, -1) {
+		if m[1] == "/" {
+			closed[strings.ToLower(m[2])] = true
+		}
+	}
+
+	for name := range closed {
+		if droppedShortcodes[name] {
+			dropped := regexp.MustCompile(`(?is)\[` + name + `\b[^\]]*\].*?\[/` + name + `\]`)
+			s = dropped.ReplaceAllString(s, " ")
+		}
+	}
+
+	return shortcodePattern.ReplaceAllStringFunc(s, func(tag string) string {
+		m := shortcodePattern.FindStringSubmatch(tag)
+		name := strings.ToLower(m[2])
+		if m[1] == "/" || strings.TrimSpace(m[3]) != "" || closed[name] || strings.Contains(name, "_") || shortcodes[name] {
+			return " "
+		}
+		return tag
+	})
+}
+
+// parse parses s as the content of a page's body
+func parse(s string) []*html.Node {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(StripShortcodes(s)), body)
+	if err != nil {
+		// The parser only fails on reader errors, which strings.Reader has none of
+		return []*html.Node{{Type: html.TextNode, Data: s}}
+	}
+	return nodes
+}
+
+// hidden elements are dropped along with their content
+var hidden = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true, atom.Iframe: true,
+	atom.Object: true, atom.Embed: true, atom.Svg: true, atom.Math: true, atom.Head: true,
+	atom.Title: true, atom.Form: true, atom.Button: true, atom.Select: true, atom.Textarea: true,
+}
+
+// paragraphs are separated from the text around them by a blank line, and
+// lines by a line break
+var (
+	paragraphs = map[atom.Atom]bool{
+		atom.P: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true,
+		atom.H6: true, atom.Ul: true, atom.Ol: true, atom.Blockquote: true, atom.Pre: true,
+		atom.Table: true, atom.Figure: true, atom.Hr: true, atom.Dl: true,
+	}
+	lines = map[atom.Atom]bool{
+		atom.Br: true, atom.Div: true, atom.Li: true, atom.Tr: true, atom.Dt: true, atom.Dd: true,
+		atom.Figcaption: true, atom.Section: true, atom.Article: true, atom.Header: true,
+		atom.Footer: true, atom.Address: true, atom.Caption: true,
+	}
+)
+
+// textWriter writes text with whitespace collapsed, line breaks only where the
+// markup has them and at most one blank line in a row
+type textWriter struct {
+	b       strings.Builder
+	space   bool // A space is due before the next word
+	pending int  // Line breaks due before the next word
+}
+
+func (w *textWriter) text(s string) {
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			w.space = true
+			continue
+		}
+		if w.b.Len() > 0 {
+			if w.pending > 0 {
+				w.b.WriteString(strings.Repeat("\n", w.pending))
+			} else if w.space {
+				w.b.WriteByte(' ')
+			}
+		}
+		w.pending, w.space = 0, false
+		w.b.WriteRune(r)
+	}
+}
+
+func (w *textWriter) lineBreak(n int) {
+	if n > w.pending {
+		w.pending = n
+	}
+}
+
+func (w *textWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+		if hidden[n.DataAtom] {
+			return
+		}
+	case html.DocumentNode:
+	default:
+		return
+	}
+
+	breaks := 0
+	if paragraphs[n.DataAtom] {
+		breaks = 2
+	} else if lines[n.DataAtom] {
+		breaks = 1
+	}
+	w.lineBreak(breaks)
+	switch n.DataAtom {
+	case atom.Li:
+		w.text("-")
+		w.space = true
+	case atom.Td, atom.Th:
+		w.space = true
+	case atom.Img:
+		// Images stand in for text only through their alt text
+		for _, attr := range n.Attr {
+			if attr.Key == "alt" {
+				w.text(" " + attr.Val + " ")
+			}
+		}
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		w.node(child)
+	}
+	w.lineBreak(breaks)
+}
+
+// Text converts HTML to plain text: tags and shortcodes are removed, entities
+// decoded and whitespace collapsed, with paragraphs separated by a blank line
+// and lines, including list items, by a line break. Plain text comes out as
+// it went in, only with its whitespace collapsed.
+func Text(s string) string {
+	var w textWriter
+	for _, n := range parse(s) {
+		w.node(n)
+	}
+	return w.b.String()
+}
+
+// Line converts HTML to text on a single line, for titles and names
+func Line(s string) string {
+	return strings.Join(strings.Fields(Text(s)), " ")
+}
+
+// allowed are the elements HTML keeps, with the attributes each may keep;
+// other elements are replaced with their content
+var allowed = map[atom.Atom][]string{
+	atom.P: nil, atom.Br: nil, atom.Strong: nil, atom.B: nil, atom.Em: nil, atom.I: nil,
+	atom.U: nil, atom.Ul: nil, atom.Ol: nil, atom.Li: nil, atom.Blockquote: nil,
+	atom.H2: nil, atom.H3: nil, atom.H4: nil, atom.H5: nil, atom.H6: nil, atom.A: {"href"},
+}
+
+// headings are demoted from h1, which belongs to the page showing the event
+var headings = map[atom.Atom]atom.Atom{atom.H1: atom.H2}
+
+// safeURL reports whether a link may be kept: only web and mail links are,
+// not javascript: or data: ones
+func safeURL(href string) bool {
+	href = strings.ToLower(strings.TrimSpace(href))
+	return strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") || strings.HasPrefix(href, "mailto:")
+}
+
+// htmlWriter writes the allowed elements of a tree, with whitespace collapsed
+type htmlWriter struct {
+	b      strings.Builder
+	space  bool // A space is due before the next word
+	inLine bool // The last thing written was text or an inline tag, after which spaces matter
+}
+
+func (w *htmlWriter) text(s string) {
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			w.space = true
+			continue
+		}
+		w.separate()
+		w.b.WriteString(html.EscapeString(string(r)))
+		w.inLine = true
+	}
+}
+
+// separate writes the space due, if it falls within a line of text
+func (w *htmlWriter) separate() {
+	if w.space && w.inLine {
+		w.b.WriteByte(' ')
+	}
+	w.space = false
+}
+
+// tag writes a start or end tag
+func (w *htmlWriter) tag(s string, a atom.Atom) {
+	inline := a == atom.Strong || a == atom.B || a == atom.Em || a == atom.I || a == atom.U || a == atom.A
+	if inline && !strings.HasPrefix(s, "</") {
+		w.separate()
+	}
+	if !inline {
+		w.space = false
+	}
+	w.b.WriteString(s)
+	w.inLine = inline
+}
+
+// empty reports whether an element has no text to show
+func empty(n *html.Node) bool {
+	if n.Type == html.TextNode {
+		return strings.TrimFunc(n.Data, unicode.IsSpace) == ""
+	}
+	if n.Type == html.ElementNode && (hidden[n.DataAtom] || n.DataAtom == atom.Img) {
+		return true
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if !empty(child) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *htmlWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+		if hidden[n.DataAtom] {
+			return
+		}
+	case html.DocumentNode:
+	default:
+		return
+	}
+
+	tag := n.DataAtom
+	if heading, ok := headings[tag]; ok {
+		tag = heading
+	}
+	attrs, keep := allowed[tag]
+	if keep && tag != atom.Br && empty(n) {
+		return
+	}
+	if tag == atom.A {
+		keep = false
+		for _, attr := range n.Attr {
+			if attr.Key == "href" && safeURL(attr.Val) {
+				keep = true
+			}
+		}
+	}
+	if !keep {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			w.node(child)
+		}
+		return
+	}
+
+	start := "<" + tag.String()
+	for _, attr := range n.Attr {
+		for _, name := range attrs {
+			if attr.Namespace == "" && attr.Key == name {
+				start += " " + name + `="` + html.EscapeString(strings.TrimSpace(attr.Val)) + `"`
+			}
+		}
+	}
+	if tag == atom.A {
+		start += ` rel="nofollow noopener noreferrer"`
+	}
+	w.tag(start+">", tag)
+	if tag == atom.Br {
+		return
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		w.node(child)
+	}
+	w.tag("</"+tag.String()+">", tag)
+}
+
+// HTML reduces HTML to paragraphs, line breaks, lists, quotes, headings,
+// emphasis and web or mail links, which are safe to render on the tracker's
+// pages. Other elements are replaced with their content, scripts, styles and
+// embeds are dropped with theirs, and only the href of links is kept.
+func HTML(s string) string {
+	var w htmlWriter
+	for _, n := range parse(s) {
+		w.node(n)
+	}
+	return w.b.String()
+}
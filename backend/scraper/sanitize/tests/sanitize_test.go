@@ -0,0 +1,57 @@
+package sanitize_tests
+
+import (
+	"backend/scraper/sanitize"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestText(t *testing.T) {
+	tests := map[string]string{
+		// As Visit Gainesville gives descriptions
+		"<p><strong>Join us</strong> at the &#8220;Harn&#8221;.</p> <p>Free&nbsp;parking.<br/>Doors at 7.</p>": "Join us at the “Harn”.\n\nFree parking.\nDoors at 7.",
+		"<ul><li>Music</li><li>Food <em>trucks</em></li></ul><p>Bring a chair</p>":                             "- Music\n- Food trucks\n\nBring a chair",
+		"<p>Before</p><script>track()</script><style>p{}</style><iframe src=\"x\">Video</iframe>":              "Before",
+		// Plain text only has its whitespace collapsed
+		"Ages < 12 & seniors\n  free":   "Ages < 12 & seniors free",
+		"Tom &amp; Jerry&#8217;s Night": "Tom & Jerry’s Night",
+		"":                              "",
+	}
+	for html, want := range tests {
+		assert.Equal(t, want, sanitize.Text(html), html)
+	}
+}
+
+func TestLine(t *testing.T) {
+	assert.Equal(t, "Parker McCollum – What Kinda Man Tour", sanitize.Line(" Parker McCollum &#8211;\n What Kinda Man Tour "))
+	assert.Equal(t, "Art Walk Downtown", sanitize.Line("<p>Art Walk</p><p>Downtown</p>"))
+}
+
+func TestStripShortcodes(t *testing.T) {
+	tests := map[string]string{
+		`[caption id="attachment_9" width="300"]<img src="a.jpg"> A caption[/caption]`: "A caption",
+		`Watch [embed]https://youtu.be/x[/embed] now`:                                  "Watch now",
+		`[vc_row][vc_column]Text[/vc_column][/vc_row]`:                                 "Text",
+		`[gallery]`: "",
+		// Brackets in prose are kept
+		"The [band] played on [sic]": "The [band] played on [sic]",
+	}
+	for s, want := range tests {
+		assert.Equal(t, want, sanitize.Line(sanitize.StripShortcodes(s)), s)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	tests := map[string]string{
+		"<p>Hello <strong>there</strong>, <span style=\"color:red\">friend</span>!</p>": "<p>Hello <strong>there</strong>, friend!</p>",
+		// Links keep only a web or mail href
+		`<a href="https://example.com/?a=1&amp;b=2" onclick="x()">Tickets</a> <a href="javascript:alert(1)">Win</a>`: `<a href="https://example.com/?a=1&amp;b=2" rel="nofollow noopener noreferrer">Tickets</a> Win`,
+		`<p onmouseover="x()">Hi</p><script>alert(1)</script><img src=x onerror=alert(1)>`:                           "<p>Hi</p>",
+		"<h1>Title</h1><p>&nbsp;</p><ul>\n<li>One</li>\n</ul>":                                                       "<h2>Title</h2><ul><li>One</li></ul>",
+		"Ages < 12 & up": "Ages &lt; 12 &amp; up",
+	}
+	for html, want := range tests {
+		assert.Equal(t, want, sanitize.HTML(html), html)
+	}
+}
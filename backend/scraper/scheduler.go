@@ -0,0 +1,306 @@
+package scraper
+
+import (
+	"backend/data"
+	"backend/database"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrRunInProgress is returned when a source is asked to run while it already is
+var ErrRunInProgress = errors.New("a scrape of this source is already running")
+
+// Schedule is how often a source is scraped
+type Schedule struct {
+	Interval time.Duration
+	Jitter   time.Duration // Up to this much is added to each interval, so sources drift apart
+}
+
+// DefaultInterval is how often sources are scraped unless configured otherwise
+const DefaultInterval = 6 * time.Hour
+
+// Next returns when a run after one at from is due
+func (s Schedule) Next(from time.Time) time.Time {
+	next := from.Add(s.Interval)
+	if s.Jitter > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(s.Jitter))))
+	}
+	return next
+}
+
+// ParseInterval reads an interval written as a Go duration ("90m") or as one of
+// the cron shorthands @hourly, @daily, @weekly and "@every <duration>"
+func ParseInterval(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "@hourly":
+		return time.Hour, nil
+	case "@daily":
+		return 24 * time.Hour, nil
+	case "@weekly":
+		return 7 * 24 * time.Hour, nil
+	}
+	interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(value, "@every ")))
+	if err != nil {
+		return 0, err
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("interval %q is not positive", value)
+	}
+	return interval, nil
+}
+
+// ScheduleFor returns the schedule of the named source. SCRAPER_INTERVAL and
+// SCRAPER_JITTER set it for every source, SCRAPER_INTERVAL_<NAME> and
+// SCRAPER_JITTER_<NAME> for one (SCRAPER_INTERVAL_GAINESVILLE_SUN=@daily). The
+// jitter defaults to a tenth of the interval.
+func ScheduleFor(name string) Schedule {
+	suffix := "_" + strings.ToUpper(name)
+	schedule := Schedule{Interval: DefaultInterval}
+	for _, key := range []string{"SCRAPER_INTERVAL", "SCRAPER_INTERVAL" + suffix} {
+		if value := os.Getenv(key); value != "" {
+			interval, err := ParseInterval(value)
+			if err != nil {
+				log.Printf("Ignoring %s: %v", key, err)
+				continue
+			}
+			schedule.Interval = interval
+		}
+	}
+
+	schedule.Jitter = schedule.Interval / 10
+	for _, key := range []string{"SCRAPER_JITTER", "SCRAPER_JITTER" + suffix} {
+		if value := os.Getenv(key); value != "" {
+			jitter, err := time.ParseDuration(value)
+			if err != nil || jitter < 0 {
+				log.Printf("Ignoring %s: not a duration: %q", key, value)
+				continue
+			}
+			schedule.Jitter = jitter
+		}
+	}
+	return schedule
+}
+
+// State returns what the scheduler remembers of the named source, a zero state
+// if the source never ran
+func State(name string) (data.ScrapeState, error) {
+	var states []data.ScrapeState
+	if err := database.DB.Where("source = ?", name).Limit(1).Find(&states).Error; err != nil {
+		return data.ScrapeState{Source: name}, err
+	}
+	if len(states) == 0 {
+		return data.ScrapeState{Source: name}, nil
+	}
+	return states[0], nil
+}
+
+// Scheduler runs sources on their schedules, never two runs of the same source
+// at once
+type Scheduler struct {
+	// StartDelay is how long to wait before the first run of a source that is due
+	// on startup, letting the server come up first
+	StartDelay time.Duration
+	// RetryDelay is how long to wait before checking a source's schedule again
+	// after a run, or after finding the source already running
+	RetryDelay time.Duration
+
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+// NewScheduler returns a scheduler with the default delays
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		StartDelay: 10 * time.Second,
+		RetryDelay: time.Minute,
+		running:    make(map[string]bool),
+	}
+}
+
+// DefaultScheduler is the scheduler the server starts
+var DefaultScheduler = NewScheduler()
+
+// Start runs every enabled source on its schedule until ctx is done. Sources
+// resume the schedule stored by earlier runs, so a restart does not scrape
+// sources that ran recently.
+func (s *Scheduler) Start(ctx context.Context) {
+	// Runs still marked as running were cut short by the last shutdown
+	if err := database.DB.Model(&data.ScrapeRun{}).Where("status = ?", data.ScrapeRunning).
+		Updates(map[string]interface{}{"status": data.ScrapeFailed, "error": "interrupted by a restart"}).Error; err != nil {
+		log.Printf("Error closing interrupted scrape runs: %v", err)
+	}
+
+	for _, source := range Enabled() {
+		schedule := ScheduleFor(source.Name())
+		log.Printf("Scraping %s every %s (jitter %s)", source.Name(), schedule.Interval, schedule.Jitter)
+		go s.loop(ctx, source)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, source Source) {
+	wait := s.StartDelay
+	for {
+		if !sleep(ctx, wait) {
+			return
+		}
+
+		// The next run may have moved while waiting, after a manual run for one,
+		// so the stored schedule is checked again after every wait
+		state, err := State(source.Name())
+		if err != nil {
+			log.Printf("%s: error loading scrape state: %v", source.Name(), err)
+		} else if until := time.Until(state.NextRunAt); until > 0 {
+			wait = until
+			continue
+		}
+
+		if _, err := s.Run(ctx, source); err != nil && !errors.Is(err, ErrRunInProgress) {
+			log.Printf("%s: scrape failed: %v", source.Name(), err)
+		}
+		wait = s.RetryDelay
+	}
+}
+
+// Triggers of a run
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// Run scrapes a source now and schedules its next run, returning
+// ErrRunInProgress if the source is already being scraped
+func (s *Scheduler) Run(ctx context.Context, source Source) (Result, error) {
+	run, err := s.begin(source, TriggerSchedule)
+	if err != nil {
+		return Result{}, err
+	}
+	return s.finish(ctx, source, run)
+}
+
+// Trigger starts a manual run of a source in the background and returns its
+// record, or ErrRunInProgress if the source is already being scraped
+func (s *Scheduler) Trigger(source Source) (data.ScrapeRun, error) {
+	run, err := s.begin(source, TriggerManual)
+	if err != nil {
+		return data.ScrapeRun{}, err
+	}
+	go s.finish(context.Background(), source, run)
+	return *run, nil
+}
+
+// begin marks a source as running and records the start of the run
+func (s *Scheduler) begin(source Source, trigger string) (*data.ScrapeRun, error) {
+	name := source.Name()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running[name] {
+		return nil, ErrRunInProgress
+	}
+
+	run := &data.ScrapeRun{Source: name, Trigger: trigger, Status: data.ScrapeRunning, StartedAt: time.Now()}
+	if err := database.DB.Create(run).Error; err != nil {
+		return nil, err
+	}
+	s.running[name] = true
+	return run, nil
+}
+
+// finish performs a run begun by begin, records its outcome and schedules the
+// source's next run
+func (s *Scheduler) finish(ctx context.Context, source Source, run *data.ScrapeRun) (Result, error) {
+	name := source.Name()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, name)
+		s.mu.Unlock()
+	}()
+
+	log.Printf("Scraping %s...", name)
+	result, err := Run(ctx, source)
+	log.Printf("%s: %s", name, result)
+
+	endedAt := time.Now()
+	run.EndedAt = &endedAt
+	run.Pages = result.Pages
+	run.Seen = result.Fetched
+	run.Inserted = result.Inserted
+	run.Updated = result.Updated
+	run.Unchanged = result.Unchanged
+	run.Duplicates = result.Duplicates
+	run.Failed = result.Failed
+	run.Cancelled = result.Cancelled
+	switch {
+	case err != nil:
+		run.Status = data.ScrapeFailed
+		run.Error = err.Error()
+	case result.Failed > 0 || len(result.Errors) > 0:
+		run.Status = data.ScrapePartial
+	default:
+		run.Status = data.ScrapeOK
+	}
+	if saveErr := saveRun(run, result.Errors); saveErr != nil {
+		log.Printf("%s: error saving scrape run: %v", name, saveErr)
+	}
+
+	state := data.ScrapeState{
+		Source:     name,
+		LastRunAt:  run.StartedAt,
+		LastEndAt:  endedAt,
+		LastStatus: run.Status,
+		LastError:  run.Error,
+		Inserted:   result.Inserted,
+		NextRunAt:  ScheduleFor(name).Next(endedAt),
+	}
+	if saveErr := database.DB.Save(&state).Error; saveErr != nil {
+		log.Printf("%s: error saving scrape state: %v", name, saveErr)
+	}
+	return result, err
+}
+
+// saveRun stores the outcome of a run along with its errors
+func saveRun(run *data.ScrapeRun, problems []data.ScrapeError) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Errors").Save(run).Error; err != nil {
+			return err
+		}
+		if len(problems) == 0 {
+			return nil
+		}
+		for i := range problems {
+			problems[i].RunID = run.ID
+		}
+		return tx.CreateInBatches(problems, 100).Error
+	})
+}
+
+// Running reports whether the named source is being scraped
+func (s *Scheduler) Running(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running[name]
+}
+
+// sleep waits for d, returning false if ctx is done first
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -1,23 +1,22 @@
 package scraper
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
-	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"backend/data"
 	"backend/database"
+	"backend/dedup"
+	"backend/eventcost"
+	"backend/eventdate"
+	"backend/eventtags"
+	"backend/taxonomy"
 
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
-
-	"github.com/gocolly/colly"
 	"github.com/muesli/gominatim"
-	"github.com/texttheater/golang-levenshtein/levenshtein"
 )
 
 type Event struct {
@@ -60,65 +59,266 @@ func RemoveWhiteSpaces(str string) string {
 	return str
 }
 
-func CheckForDuplicateEvents(eventTitle string, eventDate string, eventLocation string) bool {
+// Outcome is what storing a scraped event did
+type Outcome int
+
+const (
+	Inserted  Outcome = iota
+	Updated           // The event was stored before and some of its fields changed
+	Unchanged         // The event was stored before as it is
+	Duplicate         // Another event, usually from another source, already describes it
+)
+
+// UpsertEvent stores a scraped event from the named source. An event the source
+// gave before, found by its external ID, is updated in place and the columns
+// that changed are returned. A new one is compared with the events already
+// stored: if it duplicates one, it is stored unpublished and merged into that
+// one, and if it only may, it is inserted and the pair queued for review.
+func UpsertEvent(source string, raw RawEvent) (Outcome, []string, error) {
+	existing, found, err := findScrapedEvent(source, raw)
+	if err != nil {
+		return 0, nil, err
+	}
+	if found {
+		changed, err := updateEvent(source, &existing, raw)
+		if err != nil || len(changed) == 0 {
+			return Unchanged, nil, err
+		}
+		return Updated, changed, nil
+	}
+
+	event := scrapedEvent(source, raw, time.Now())
+	// Venues are compared by distance, so new events are geocoded first
+	if event.Latitude == 0 && event.Longitude == 0 {
+		if err := geocode(&event); err != nil {
+			log.Println("Error populating latitude/longitude:", err)
+		}
+	}
+	match, matched, err := dedup.Find(database.DB, &event)
+	if err != nil {
+		return 0, nil, err
+	}
+	if matched && match.Duplicate() {
+		event.DuplicateOfID = &match.Event.ID
+	}
+	if err := insertEvent(raw, &event); err != nil {
+		return 0, nil, err
+	}
+
+	switch {
+	case !matched:
+		return Inserted, nil, nil
+	case match.Duplicate():
+		log.Printf("DUPLICATE FOUND: %q merged into event %d %q (score %.2f)", raw.Name, match.Event.ID, match.Event.Name, match.Score)
+		_, err := dedup.Absorb(database.DB, &match.Event, event)
+		return Duplicate, nil, err
+	default:
+		log.Printf("POSSIBLE DUPLICATE: %q may be event %d %q (score %.2f), queued for review", raw.Name, match.Event.ID, match.Event.Name, match.Score)
+		return Inserted, nil, dedup.Queue(database.DB, event.ID, match)
+	}
+}
 
+// findScrapedEvent looks up the stored copy of a scraped event by its external
+// ID, falling back to its name, date and location for events stored before
+// external IDs were, which are then adopted by the source
+func findScrapedEvent(source string, raw RawEvent) (data.Event, bool, error) {
 	var events []data.Event
+	if raw.ExternalID != "" {
+		if err := database.DB.Where("source = ? AND external_id = ?", source, raw.ExternalID).
+			Order("id").Limit(1).Find(&events).Error; err != nil {
+			return data.Event{}, false, err
+		}
+		if len(events) > 0 {
+			return events[0], true, nil
+		}
+	}
+
+	if err := database.DB.Where("name = ? AND date = ? AND location = ?", raw.Name, raw.Date, raw.Location).
+		Where("source IN ? AND external_id = ?", []string{"", source}, "").
+		Order("id").Limit(1).Find(&events).Error; err != nil {
+		return data.Event{}, false, err
+	}
+	if len(events) > 0 {
+		return events[0], true, nil
+	}
+	return data.Event{}, false, nil
+}
 
-	// Pre-filter by event date and city before checking duplicates
-	query := database.DB.Where("name LIKE ?", eventTitle).
-		Where("date LIKE ?", eventDate).
-		Where("location LIKE ?", eventLocation).
-		Limit(10) // Avoid fetching too many events
+// scrapedEvent builds the event a source's copy describes, with its date and
+// cost parsed and its category mapped
+func scrapedEvent(source string, raw RawEvent, now time.Time) data.Event {
+	event := data.Event{
+		Source:          source,
+		ExternalID:      raw.ExternalID,
+		SourceURL:       raw.SourceURL,
+		Name:            raw.Name,
+		Date:            raw.Date,
+		Location:        raw.Location,
+		Description:     raw.Description,
+		DescriptionHTML: raw.DescriptionHTML,
+		GoogleMapsLink:  raw.GoogleMapsLink,
+		Category:        raw.Category,
+		Tags:            raw.Tags,
+		Latitude:        raw.Latitude,
+		Longitude:       raw.Longitude,
+		ImageURL:        raw.ImageURL,
+		Website:         raw.WebsiteURL,
+		TicketsURL:      raw.TicketsURL,
+		CostText:        raw.Cost,
+	}
 
-	if err := query.Find(&events).Error; err != nil {
-		log.Println("Error fetching events from database:", err)
-		return false
+	if err := eventdate.Schedule(&event, now); err != nil {
+		log.Printf("Could not parse date %q for event %q: %v", raw.Date, raw.Name, err)
 	}
 
-	// Levenshtein distance for similarity check
-	threshold := 3 // Tune this threshold based on real-world testing
+	if err := eventcost.Price(&event); err != nil && raw.Cost != "" {
+		log.Printf("Could not parse cost %q for event %q: %v", raw.Cost, raw.Name, err)
+	}
 
-	for _, event := range events {
+	if err := taxonomy.Apply(database.DB, source, &event); err != nil {
+		log.Printf("Could not categorize event %q: %v", raw.Name, err)
+	}
+	return event
+}
 
-		if event.Name == eventTitle {
-			log.Println("DUPLICATE FOUND: EVENT NOT INSERTED - Title:", eventTitle)
-			return true
+// updateEvent brings a stored event in line with the source's copy, saving
+// the columns that changed, which it returns, and when the event was seen.
+// Listings of the event merged into it still fill in what the source's copy
+// lacks, and a merged listing is merged into its event again.
+func updateEvent(source string, event *data.Event, raw RawEvent) ([]string, error) {
+	now := time.Now()
+	next := scrapedEvent(source, raw, now)
+	var duplicates []data.Event
+	if err := database.DB.Where("duplicate_of_id = ?", event.ID).Order("id").Find(&duplicates).Error; err != nil {
+		return nil, err
+	}
+	for _, duplicate := range duplicates {
+		dedup.Merge(&next, duplicate)
+	}
+
+	var changed []string
+	set := func(column string, field *string, value string) {
+		if *field != value {
+			*field = value
+			changed = append(changed, column)
 		}
+	}
+	set("source", &event.Source, next.Source)
+	set("external_id", &event.ExternalID, next.ExternalID)
+	set("name", &event.Name, next.Name)
+	set("date", &event.Date, next.Date)
+	set("location", &event.Location, next.Location)
+	set("description", &event.Description, next.Description)
+	set("description_html", &event.DescriptionHTML, next.DescriptionHTML)
+	set("google_maps_link", &event.GoogleMapsLink, next.GoogleMapsLink)
+	set("category", &event.Category, next.Category)
+	set("tags", &event.Tags, next.Tags)
+	set("image_url", &event.ImageURL, next.ImageURL)
+	set("website", &event.Website, next.Website)
+	set("tickets_url", &event.TicketsURL, next.TicketsURL)
+	set("source_url", &event.SourceURL, next.SourceURL)
+	set("cost_text", &event.CostText, next.CostText)
+	hasCoordinates := next.Latitude != 0 || next.Longitude != 0
+	if hasCoordinates && (event.Latitude != next.Latitude || event.Longitude != next.Longitude) {
+		event.Latitude, event.Longitude = next.Latitude, next.Longitude
+		changed = append(changed, "latitude", "longitude")
+	}
+	if event.Cancelled {
+		// Back on the source after all
+		event.Cancelled, event.CancelledAt = false, nil
+		changed = append(changed, "cancelled", "cancelled_at")
+	}
+
+	// Columns derived from the changed ones, and when the event was seen
+	event.LastSeenAt = &now
+	columns := append([]string{"last_seen_at"}, changed...)
+	if event.FirstSeenAt == nil {
+		event.FirstSeenAt = &now
+		columns = append(columns, "first_seen_at")
+	}
+	if contains(changed, "date") {
+		event.StartsAt, event.EndsAt, event.AllDay = next.StartsAt, next.EndsAt, next.AllDay
+		columns = append(columns, "starts_at", "ends_at", "all_day")
+	}
+	if contains(changed, "category") {
+		event.CategoryID = next.CategoryID
+		columns = append(columns, "category_id")
+	}
+	if contains(changed, "cost_text") {
+		event.Cost, event.MaxCost, event.Currency, event.Free = next.Cost, next.MaxCost, next.Currency, next.Free
+		columns = append(columns, "cost", "max_cost", "currency", "free")
+	}
 
-		distance := levenshtein.DistanceForStrings([]rune(strings.ToLower(eventTitle)), []rune(strings.ToLower(eventTitle)), levenshtein.DefaultOptions)
+	if err := database.DB.Model(event).Select(columns).Updates(event).Error; err != nil {
+		return nil, err
+	}
 
-		if distance <= threshold {
-			log.Println("DUPLICATE FOUND: EVENT NOT INSERTED - Title:", eventTitle)
-			return true
+	if contains(changed, "tags") {
+		if err := eventtags.Apply(database.DB, event); err != nil {
+			log.Println("Error saving event tags:", err)
+		}
+	}
+	if contains(changed, "location") && !hasCoordinates {
+		if err := PopulateLatLng(event); err != nil {
+			log.Println("Error populating latitude/longitude:", err)
+		}
+	}
+
+	if event.DuplicateOfID != nil && len(changed) > 0 {
+		var merged []data.Event
+		if err := database.DB.Where("id = ?", *event.DuplicateOfID).Limit(1).Find(&merged).Error; err != nil {
+			return nil, err
+		}
+		if len(merged) > 0 {
+			if _, err := dedup.Absorb(database.DB, &merged[0], *event); err != nil {
+				return nil, err
+			}
 		}
 	}
+	return changed, nil
+}
 
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
 	return false
 }
 
-func InsertEventIntoDB(name, date, location, googleMapsLink, description string, category string, organizerName string, organizerEmail string, organizerTel string, tags string, imageURL string, websiteURL string, ticketsURL string) error {
-	// Check if the event already exists in the database
-	var existingEvent data.Event
-	if err := database.DB.Where("name = ? AND date = ? AND location = ?", name, date, location).First(&existingEvent).Error; err == nil {
-		// Event already exists, return without inserting
-		return nil
+// CancelMissing marks the upcoming events of a source whose external IDs are
+// not among those given as cancelled, returning how many it marked. Only call
+// it with every ID of a complete fetch.
+func CancelMissing(source string, externalIDs []string, now time.Time) (int, error) {
+	query := database.DB.Model(&data.Event{}).
+		Where("source = ? AND external_id != '' AND cancelled = ?", source, false).
+		Where("COALESCE(ends_at, starts_at) >= ?", now.UTC())
+	if len(externalIDs) > 0 {
+		query = query.Where("external_id NOT IN ?", externalIDs)
 	}
+	result := query.Updates(map[string]interface{}{"cancelled": true, "cancelled_at": now.UTC()})
+	return int(result.RowsAffected), result.Error
+}
 
+// insertEvent stores a new scraped event, creating its organizer if needed.
+// Events merged into another are stored unpublished.
+func insertEvent(raw RawEvent, event *data.Event) error {
 	// Check if organizerName is not null or empty
 	var organizerID uint
-	if organizerName != "" {
+	if raw.OrganizerName != "" {
 		// Attempt to find the organizer by name directly in the database
 		var organizer data.Organizer
-		err := database.DB.Where("name = ? AND email = ?", organizerName, organizerEmail).First(&organizer).Error
+		err := database.DB.Where("name = ? AND email = ?", raw.OrganizerName, raw.OrganizerEmail).First(&organizer).Error
 		if err == nil {
 			// Organizer found, set organizerID
 			organizerID = organizer.ID
 		} else {
 			// Organizer not found, create a new organizer
 			newOrganizer := data.Organizer{
-				Name:           organizerName,
-				Email:          organizerEmail,
-				ContactDetails: organizerTel,
+				Name:           raw.OrganizerName,
+				Email:          raw.OrganizerEmail,
+				ContactDetails: raw.OrganizerTel,
 			}
 			if err := database.DB.Create(&newOrganizer).Error; err != nil {
 				return fmt.Errorf("failed to create new organizer: %v", err)
@@ -131,18 +331,9 @@ func InsertEventIntoDB(name, date, location, googleMapsLink, description string,
 	}
 
 	// Insert the event into the database
-	event := data.Event{
-		Name:           name,
-		Date:           date,
-		Location:       location,
-		Description:    description,
-		GoogleMapsLink: googleMapsLink,
-		Category:       category,
-		Tags:           tags,
-		ImageURL:       imageURL,
-		Website:        websiteURL,
-		TicketsURL:     ticketsURL,
-	}
+	now := time.Now()
+	event.FirstSeenAt = &now
+	event.LastSeenAt = &now
 
 	if organizerID != 0 {
 		event.OrganizerID = organizerID
@@ -156,13 +347,18 @@ func InsertEventIntoDB(name, date, location, googleMapsLink, description string,
 		}
 	}
 
-	if err := database.DB.Create(&event).Error; err != nil {
+	if err := database.DB.Create(event).Error; err != nil {
 		return err
 	}
+	if event.DuplicateOfID != nil {
+		// Active defaults to true, so false is only stored by an update
+		if err := database.DB.Model(event).Update("active", false).Error; err != nil {
+			return err
+		}
+	}
 
-	// Populate latitude and longitude for the inserted event
-	if err := PopulateLatLng(&event); err != nil {
-		log.Println("Error populating latitude/longitude:", err)
+	if err := eventtags.Apply(database.DB, event); err != nil {
+		log.Println("Error saving event tags:", err)
 	}
 
 	return nil
@@ -184,10 +380,47 @@ func removeNameFromLocation(location string) string {
 
 // PopulateLatLng updates the latitude and longitude for events in the database
 func PopulateLatLng(event *data.Event) error {
+	if err := geocode(event); err != nil {
+		return err
+	}
+
+	// Only the coordinates are written, never the rest of the event or its linked records
+	if err := database.DB.Model(event).Select("latitude", "longitude").Updates(event).Error; err != nil {
+		log.Printf("Failed to update event ID %d: %v\n", event.ID, err)
+		return err
+	}
+	fmt.Printf("Updated event ID %d with lat/lng: (%f, %f)\n", event.ID, event.Latitude, event.Longitude)
+
+	return nil
+}
+
+// Geocoder resolves a location to its latitude and longitude. It queries
+// Nominatim unless replaced, as the tests do to run offline.
+var Geocoder func(location string) (float64, float64, error) = nominatimGeocode
+
+// geocode sets the latitude and longitude of an event from its location
+// without saving them
+func geocode(event *data.Event) error {
+	lat, lng, err := Geocoder(event.Location)
+	if err != nil {
+		log.Printf("No geocoding results found for event ID %d, location: %s\n", event.ID, event.Location)
+		return err
+	}
+
+	// Update event with lat/lng
+	event.Latitude = lat
+	event.Longitude = lng
+
+	return nil
+}
+
+// nominatimGeocode looks a location up on OpenStreetMap's Nominatim, retrying
+// without the venue name or country if the whole location is not found
+func nominatimGeocode(location string) (float64, float64, error) {
 	// Set Gominatim server
 	gominatim.SetServer("https://nominatim.openstreetmap.org/")
 
-	originalLocation := event.Location
+	originalLocation := location
 	query := gominatim.SearchQuery{Q: originalLocation}
 
 	// Helper function to get lat/lng from query
@@ -202,7 +435,7 @@ func PopulateLatLng(event *data.Event) error {
 	// Determine the first character of location
 	firstChar := strings.TrimSpace(originalLocation)
 	if firstChar == "" {
-		return fmt.Errorf("location string is empty")
+		return 0, 0, fmt.Errorf("location string is empty")
 	}
 	firstRune := []rune(firstChar)[0]
 
@@ -236,31 +469,19 @@ func PopulateLatLng(event *data.Event) error {
 	}
 
 	if err != nil || result == nil {
-		log.Printf("No geocoding results found for event ID %d, location: %s\n", event.ID, originalLocation)
-		return fmt.Errorf("no geocoding results found for location: %s", originalLocation)
+		return 0, 0, fmt.Errorf("no geocoding results found for location: %s", originalLocation)
 	}
 
 	// Convert strings to floats
 	lat, err := strconv.ParseFloat(result.Lat, 64)
 	if err != nil {
-		return fmt.Errorf("error parsing latitude: %v", err)
+		return 0, 0, fmt.Errorf("error parsing latitude: %v", err)
 	}
 	lng, err := strconv.ParseFloat(result.Lon, 64)
 	if err != nil {
-		return fmt.Errorf("error parsing longitude: %v", err)
-	}
-
-	// Update event with lat/lng
-	event.Latitude = lat
-	event.Longitude = lng
-
-	if err := database.DB.Save(event).Error; err != nil {
-		log.Printf("Failed to update event ID %d: %v\n", event.ID, err)
-		return err
+		return 0, 0, fmt.Errorf("error parsing longitude: %v", err)
 	}
-	fmt.Printf("Updated event ID %d with lat/lng: (%f, %f)\n", event.ID, lat, lng)
-
-	return nil
+	return lat, lng, nil
 }
 
 func truncateAtCountry(location string) string {
@@ -273,331 +494,6 @@ func truncateAtCountry(location string) string {
 	return ""
 }
 
-func ScrapeVisitGainesville() {
-	baseURL := "https://www.visitgainesville.com/wp-json/wp/v2/tribe_events?order=asc&page=%d&per_page=12&orderby=date"
-	organizerAPI1 := "https://www.visitgainesville.com/wp-json/wp/v2/tribe_organizer?order=asc&page=1&per_page=100&orderby=date"
-	organizerAPI2 := "https://www.visitgainesville.com/wp-json/wp/v2/tribe_organizer?order=asc&page=2&per_page=100&orderby=date"
-	page := 1
-	const maxPages = 5
-
-	// Create a collector for the main API requests
-	apiCollector := colly.NewCollector(
-		colly.AllowedDomains("www.visitgainesville.com", "visitgainesville.com"),
-		colly.UserAgent("Mozilla/5.0"),
-	)
-
-	// Create a separate collector for visiting individual event pages
-	eventPageCollector := colly.NewCollector(
-		colly.AllowedDomains("www.visitgainesville.com", "visitgainesville.com"),
-		colly.UserAgent("Mozilla/5.0"),
-	)
-
-	// Create a collector for the organizer API
-	organizerCollector := colly.NewCollector(
-		colly.AllowedDomains("www.visitgainesville.com", "visitgainesville.com"),
-		colly.UserAgent("Mozilla/5.0"),
-	)
-
-	// Map to store event details while waiting for address scraping
-	eventDetails := make(map[string]struct {
-		Name        string
-		Description string
-		StartDate   string
-		EndDate     string
-		Cost        string
-		ImageURL    string
-		URL         string
-		Category    string
-		Tags        string
-		Organizer   string
-	})
-
-	// Map to cache organizer data
-	organizerCache := make(map[string]string)
-
-	// Handle individual event pages to scrape address and Google Maps link
-	eventPageCollector.OnHTML("body", func(e *colly.HTMLElement) {
-		eventURL := e.Request.URL.String()
-		address := e.DOM.Find(".tribe-events-venue-details .tribe-venue").Text()
-		cleanedAddress := CleanWhiteSpaces(address)
-
-		escapedAddress := url.QueryEscape(cleanedAddress)
-		googleMapsLink := "https://www.google.com/maps?q=" + escapedAddress
-
-		// Retrieve the event details from the map
-		if details, found := eventDetails[eventURL]; found {
-			eventDate := fmt.Sprintf("%s - %s", details.StartDate, details.EndDate)
-
-			// Check for duplicates before inserting into the database
-			if !CheckForDuplicateEvents(details.Name, eventDate, cleanedAddress) {
-				err := InsertEventIntoDB(details.Name, eventDate, cleanedAddress, googleMapsLink, details.Description, details.Category, details.Organizer, "example@ex.com", "0000000000", details.Tags, details.ImageURL, details.URL, "")
-				if err != nil {
-					log.Println("Error inserting event into database:", err, "Event:", details.Name)
-				}
-
-				fmt.Printf("Event: %s\nDate: %s\nLocation: %s\nDescription: %s\nCost: %s\nURL: %s\nImage: %s\nGoogle Maps Link: %s\nOrganizer: %s\n\n",
-					details.Name, eventDate, cleanedAddress, details.Description, details.Cost, details.URL, details.ImageURL, googleMapsLink, details.Organizer)
-			}
-		}
-	})
-
-	// Handle API responses to get event data
-	apiCollector.OnResponse(func(r *colly.Response) {
-		var events []struct {
-			Title struct {
-				Rendered string `json:"rendered"`
-			} `json:"title"`
-			Content struct {
-				Rendered string `json:"rendered"`
-			} `json:"content"`
-			MetaFields struct {
-				EventStartDate   string `json:"_EventStartDate"`
-				EventEndDate     string `json:"_EventEndDate"`
-				EventCost        string `json:"_EventCost"`
-				EventOrganizerID string `json:"_EventOrganizerID,omitempty"`
-			} `json:"meta_fields"`
-			Link      string   `json:"link"`
-			ThumbURL  string   `json:"thumb_url"`
-			ClassList []string `json:"class_list"`
-		}
-
-		if err := json.Unmarshal(r.Body, &events); err != nil {
-			log.Println("JSON parse error:", err)
-			return
-		}
-
-		// Process each event
-		for _, event := range events {
-			cleanedEventName := CleanWhiteSpaces(event.Title.Rendered)
-			cleanedEventDescription := CleanWhiteSpaces(event.Content.Rendered)
-			cleanedEventStartDate := CleanWhiteSpaces(event.MetaFields.EventStartDate)
-			cleanedEventEndDate := CleanWhiteSpaces(event.MetaFields.EventEndDate)
-			cleanedEventCost := CleanWhiteSpaces(event.MetaFields.EventCost)
-			cleanedImageURL := CleanWhiteSpaces(event.ThumbURL)
-			eventURL := CleanWhiteSpaces(event.Link)
-			eventOrganizerID := CleanWhiteSpaces(event.MetaFields.EventOrganizerID)
-
-			// Parse class_list for categories and tags
-			var categories, tags []string
-			for _, class := range event.ClassList {
-				if strings.HasPrefix(class, "cat_") {
-					category := strings.ReplaceAll(class[4:], "-", " ")
-					if category != "gainesville" && category != "downtown gainesville" && category != "whats good" {
-						categories = append(categories, cases.Title(language.English).String(category))
-					}
-				}
-				if strings.HasPrefix(class, "tag-") {
-					tag := strings.ReplaceAll(class[4:], "-", " ")
-					tags = append(tags, cases.Title(language.English).String(tag))
-				}
-			}
-			categoryString := strings.Join(categories, ", ")
-			tagString := strings.Join(tags, ", ")
-
-			// Fetch organizer data
-			var organizerName string
-			if cachedName, found := organizerCache[eventOrganizerID]; found {
-				organizerName = cachedName
-			} else {
-				organizerCollector.OnResponse(func(r *colly.Response) {
-					var organizers []struct {
-						ID    string `json:"id"`
-						Title struct {
-							Rendered string `json:"rendered"`
-						} `json:"title"`
-					}
-
-					if err := json.Unmarshal(r.Body, &organizers); err != nil {
-						log.Println("JSON parse error in organizer API:", err)
-						return
-					}
-
-					for _, organizer := range organizers {
-						if organizer.ID == eventOrganizerID {
-							organizerName = CleanWhiteSpaces(organizer.Title.Rendered)
-							organizerCache[eventOrganizerID] = organizerName
-							break
-						}
-					}
-				})
-
-				// Visit the organizer API
-				organizerCollector.Visit(organizerAPI1)
-				organizerCollector.Visit(organizerAPI2)
-			}
-
-			// Store event details in the map
-			eventDetails[eventURL] = struct {
-				Name        string
-				Description string
-				StartDate   string
-				EndDate     string
-				Cost        string
-				ImageURL    string
-				URL         string
-				Category    string
-				Tags        string
-				Organizer   string
-			}{
-				Name:        cleanedEventName,
-				Description: cleanedEventDescription,
-				StartDate:   cleanedEventStartDate,
-				EndDate:     cleanedEventEndDate,
-				Cost:        cleanedEventCost,
-				ImageURL:    cleanedImageURL,
-				URL:         eventURL,
-				Category:    categoryString,
-				Tags:        tagString,
-				Organizer:   organizerName,
-			}
-
-			// Visit the event page to scrape the address and Google Maps link
-			eventPageCollector.Visit(eventURL)
-		}
-
-		// Continue to the next page
-		if len(events) > 0 && page < maxPages {
-			page++
-			apiURL := fmt.Sprintf(baseURL, page)
-			fmt.Println("Fetching next page:", apiURL)
-			apiCollector.Visit(apiURL)
-		} else {
-			fmt.Println("No more events or reached max pages. Stopping.")
-		}
-	})
-
-	// Start scraping from page 1
-	apiURL := fmt.Sprintf(baseURL, page)
-	fmt.Println("Fetching:", apiURL)
-	apiCollector.Visit(apiURL)
-}
-
-func ScrapeGainesvilleSun() {
-	baseURL := "https://discovery.evvnt.com/api/publisher/458/home_page_events?hitsPerPage=30&page=%d&publisher_id=458"
-	var page int = 0
-	const maxPages int = 5
-
-	// Create a single collector for API requests
-	collector := colly.NewCollector(
-		colly.AllowedDomains("discovery.evvnt.com"),
-		colly.UserAgent("Mozilla/5.0"),
-	)
-
-	collector.OnResponse(func(r *colly.Response) {
-		var events struct {
-			RawEvents []struct {
-				Title       string `json:"title"`
-				StartDate   string `json:"start_date"`
-				Description string `json:"description"`
-				Keywords    string `json:"keywords"`
-				Category    string `json:"category_name"`
-				Organizer   string `json:"organiser_name"`
-				Venue       struct {
-					Name      string  `json:"name"`
-					Address1  string  `json:"address_1"`
-					Address2  string  `json:"address_2"`
-					Town      string  `json:"town"`
-					Country   string  `json:"country"`
-					PostCode  string  `json:"post_code"`
-					Latitude  float64 `json:"latitude"`
-					Longitude float64 `json:"longitude"`
-				} `json:"venue"`
-				Links struct {
-					Tickets string `json:"Tickets,omitempty"`
-					Website string `json:"Website,omitempty"`
-				} `json:"links"`
-				Images  json.RawMessage `json:"images"`
-				Contact struct {
-					Email string `json:"email,omitempty"`
-					Tel   string `json:"tel,omitempty"`
-				} `json:"contact,omitempty"`
-			} `json:"rawEvents"`
-		}
-
-		if err := json.Unmarshal(r.Body, &events); err != nil {
-			log.Println("JSON parse error:", err)
-			return
-		}
-
-		// Process each event
-		for _, event := range events.RawEvents {
-			cleanedEventName := CleanWhiteSpaces(event.Title)
-			cleanedEventDate := CleanWhiteSpaces(event.StartDate)
-			eventLocation := fmt.Sprintf("%s %s %s %s %s",
-				event.Venue.Address1,
-				event.Venue.Address2,
-				event.Venue.Town,
-				event.Venue.Country,
-				event.Venue.PostCode,
-			)
-			cleanedEventLocation := CleanWhiteSpaces(eventLocation)
-			cleanedEventDescription := CleanWhiteSpaces(event.Description)
-			cleanedEventTags := RemoveWhiteSpaces(event.Keywords)
-			cleanedOrganizerName := CleanWhiteSpaces(event.Organizer)
-			cleanedOrganizerEmail := CleanWhiteSpaces(event.Contact.Email)
-			cleanedOrganizerTel := CleanWhiteSpaces(event.Contact.Tel)
-			category := event.Category
-
-			escapedAddress := url.QueryEscape(cleanedEventLocation)
-			mapsQuery := fmt.Sprintf("address=%s", escapedAddress)
-			googleMapsLink := "https://www.google.com/maps?q=" + mapsQuery
-			websiteURL := event.Links.Website
-			ticketsURL := event.Links.Tickets
-
-			// Handle the `images` field dynamically
-			var cleanedImageURL string
-			var singleImage struct {
-				Original struct {
-					URL string `json:"url"`
-				} `json:"original"`
-			}
-			var multipleImages []struct {
-				Original struct {
-					URL string `json:"url"`
-				} `json:"original"`
-			}
-
-			if err := json.Unmarshal(event.Images, &singleImage); err == nil {
-				// Handle the case where `images` is a single object
-				cleanedImageURL = singleImage.Original.URL
-			} else if err := json.Unmarshal(event.Images, &multipleImages); err == nil {
-				// Handle the case where `images` is an array
-				if len(multipleImages) > 0 {
-					cleanedImageURL = multipleImages[0].Original.URL
-				}
-			} else {
-				// Log an error if neither case works
-				log.Println("Error parsing images field:", err)
-			}
-			// Check for duplicates before inserting into the database
-			if !CheckForDuplicateEvents(cleanedEventName, cleanedEventDate, cleanedEventLocation) {
-				err := InsertEventIntoDB(cleanedEventName, cleanedEventDate, cleanedEventLocation, googleMapsLink, cleanedEventDescription, category, cleanedOrganizerName, cleanedOrganizerEmail, cleanedOrganizerTel, cleanedEventTags, cleanedImageURL, websiteURL, ticketsURL)
-				if err != nil {
-					log.Println("Error inserting event into database:", err, "Event:", cleanedEventName)
-				}
-
-				fmt.Printf("Event: %s\nDate: %s\nLocation: %s\nDescription: %s\nGoogle Maps Link: %s\n\n",
-					cleanedEventName, cleanedEventDate, cleanedEventLocation, cleanedEventDescription, googleMapsLink)
-			}
-		}
-
-		// Continue to the next page
-		if len(events.RawEvents) > 0 && page < maxPages {
-			page++
-			apiURL := fmt.Sprintf(baseURL, page)
-			fmt.Println("Fetching next page:", apiURL)
-			collector.Visit(apiURL)
-		} else {
-			fmt.Println("No more events or reached max pages. Stopping.")
-		}
-	})
-
-	// Start scraping from page 0
-	apiURL := fmt.Sprintf(baseURL, page)
-	fmt.Println("Fetching:", apiURL)
-	collector.Visit(apiURL)
-}
-
 // The following code is commented out as the EventBrite API deprecated its search by location functionality.
 
 // const eventBriteToken = ""
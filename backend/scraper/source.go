@@ -0,0 +1,255 @@
+package scraper
+
+import (
+	"backend/data"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RawEvent is an event as a source describes it, normalized to the fields the
+// tracker stores. Strings are cleaned, and HTML converted to text with package
+// sanitize, but otherwise as the source gives them.
+type RawEvent struct {
+	ExternalID  string // The source's own ID for the event, stable across scrapes
+	SourceURL   string // Page of the source the event was read from
+	Name        string
+	Date        string // In any format eventdate.Parse understands
+	Location    string
+	Description string
+	Category    string // Comma-separated, mapped onto the taxonomy when stored
+	Tags        string // Comma-separated
+	Cost        string
+
+	DescriptionHTML string // Description as sanitized HTML, when the source gives HTML
+
+	Latitude, Longitude float64 // Zero when the source has no coordinates; geocoded then
+
+	OrganizerName  string
+	OrganizerEmail string
+	OrganizerTel   string
+
+	GoogleMapsLink string
+	ImageURL       string
+	WebsiteURL     string
+	TicketsURL     string
+}
+
+// Source is somewhere events are scraped from
+type Source interface {
+	// Name identifies the source in configuration, logs and category mappings
+	Name() string
+
+	// Fetch retrieves the source's current listings, in whatever shape the
+	// source's Normalize accepts. It may return the listings fetched before an
+	// error along with the error.
+	Fetch(ctx context.Context) ([]interface{}, error)
+
+	// Normalize turns one fetched listing into a RawEvent
+	Normalize(listing interface{}) (RawEvent, error)
+}
+
+// registry holds the known sources in the order they were registered
+var registry []Source
+
+// Register adds a source to the registry, usually from an init function in the
+// source's own file. Names must be unique.
+func Register(source Source) {
+	for _, existing := range registry {
+		if existing.Name() == source.Name() {
+			panic("scraper: source registered twice: " + source.Name())
+		}
+	}
+	registry = append(registry, source)
+}
+
+// Sources returns every registered source
+func Sources() []Source {
+	return append([]Source(nil), registry...)
+}
+
+// Lookup returns the registered source with the given name
+func Lookup(name string) (Source, bool) {
+	for _, source := range registry {
+		if source.Name() == name {
+			return source, true
+		}
+	}
+	return nil, false
+}
+
+// Enabled returns the sources to scrape. By default that is every registered
+// source; SCRAPER_SOURCES limits it to a comma-separated list of names and
+// SCRAPER_DISABLED_SOURCES leaves the listed ones out.
+func Enabled() []Source {
+	only := nameSet(os.Getenv("SCRAPER_SOURCES"))
+	disabled := nameSet(os.Getenv("SCRAPER_DISABLED_SOURCES"))
+	for name := range only {
+		if _, ok := Lookup(name); !ok {
+			log.Printf("SCRAPER_SOURCES names unknown source %q", name)
+		}
+	}
+
+	var sources []Source
+	for _, source := range registry {
+		if (len(only) > 0 && !only[source.Name()]) || disabled[source.Name()] {
+			continue
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+// allowedDomains returns the hosts of the given URLs, with and without "www.",
+// so a source's collector visits only the sites it is configured with
+func allowedDomains(urls ...string) []string {
+	var hosts []string
+	for _, host := range siteHosts(urls...) {
+		hosts = append(hosts, host, "www."+host)
+	}
+	return hosts
+}
+
+func nameSet(value string) map[string]bool {
+	names := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// maxRunErrors caps the errors kept for one run; a source whose shape changed
+// fails every listing the same way
+const maxRunErrors = 100
+
+// Result counts what happened to the listings of one scrape
+type Result struct {
+	Pages      int
+	Fetched    int
+	Inserted   int
+	Updated    int
+	Unchanged  int
+	Duplicates int
+	Failed     int
+	Cancelled  int                // Upcoming events gone from the source
+	Errors     []data.ScrapeError // At most maxRunErrors of them
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%d pages, %d fetched, %d inserted, %d updated, %d unchanged, %d duplicates, %d failed, %d cancelled",
+		r.Pages, r.Fetched, r.Inserted, r.Updated, r.Unchanged, r.Duplicates, r.Failed, r.Cancelled)
+}
+
+// runStats collects what a source reports while it fetches
+type runStats struct {
+	mu         sync.Mutex
+	source     string
+	pages      int
+	incomplete bool
+	errors     []data.ScrapeError
+}
+
+type runStatsKey struct{}
+
+func (s *runStats) addError(listing string, err error) {
+	log.Printf("%s: %s: %v", s.source, listing, err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.errors) < maxRunErrors {
+		s.errors = append(s.errors, data.ScrapeError{Listing: listing, Message: err.Error()})
+	}
+}
+
+// PageFetched counts a page towards the run ctx belongs to. Sources call it for
+// every page they fetch.
+func PageFetched(ctx context.Context) {
+	if stats, ok := ctx.Value(runStatsKey{}).(*runStats); ok {
+		stats.mu.Lock()
+		stats.pages++
+		stats.mu.Unlock()
+	}
+}
+
+// ReportError records a problem that does not stop the fetch, such as a listing
+// that had to be skipped, with the run ctx belongs to
+func ReportError(ctx context.Context, listing string, err error) {
+	if stats, ok := ctx.Value(runStatsKey{}).(*runStats); ok {
+		stats.addError(listing, err)
+		return
+	}
+	log.Printf("%s: %v", listing, err)
+}
+
+// MarkIncomplete tells the run ctx belongs to that the fetch stopped before the
+// end of the source's listings, at its page limit say. Events missing from an
+// incomplete fetch are not taken to be cancelled.
+func MarkIncomplete(ctx context.Context) {
+	if stats, ok := ctx.Value(runStatsKey{}).(*runStats); ok {
+		stats.mu.Lock()
+		stats.incomplete = true
+		stats.mu.Unlock()
+	}
+}
+
+// Run scrapes one source, inserting its new events and updating those it gave
+// before. Listings fetched before a fetch error are still stored, and the error
+// is returned. After a complete, clean fetch, upcoming events the source no
+// longer lists are marked cancelled.
+func Run(ctx context.Context, source Source) (Result, error) {
+	stats := &runStats{source: source.Name()}
+	listings, fetchErr := source.Fetch(context.WithValue(ctx, runStatsKey{}, stats))
+	result := Result{Fetched: len(listings)}
+
+	var seen []string
+	for _, listing := range listings {
+		event, err := source.Normalize(listing)
+		if err != nil {
+			stats.addError("listing", err)
+			result.Failed++
+			continue
+		}
+		if event.ExternalID != "" {
+			seen = append(seen, event.ExternalID)
+		}
+
+		outcome, changed, err := UpsertEvent(source.Name(), event)
+		if err != nil {
+			stats.addError(event.Name, fmt.Errorf("storing event: %w", err))
+			result.Failed++
+			continue
+		}
+		switch outcome {
+		case Inserted:
+			result.Inserted++
+		case Updated:
+			log.Printf("%s: updated %q: %s", source.Name(), event.Name, strings.Join(changed, ", "))
+			result.Updated++
+		case Unchanged:
+			result.Unchanged++
+		case Duplicate:
+			result.Duplicates++
+		}
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	result.Pages = stats.pages
+	result.Errors = stats.errors
+
+	// Anything that went wrong may be why an event is missing
+	complete := fetchErr == nil && !stats.incomplete && result.Failed == 0 && len(stats.errors) == 0 && len(seen) > 0
+	if complete {
+		cancelled, err := CancelMissing(source.Name(), seen, time.Now())
+		if err != nil {
+			log.Printf("%s: error cancelling missing events: %v", source.Name(), err)
+		}
+		result.Cancelled = cancelled
+	}
+	return result, fetchErr
+}
@@ -0,0 +1,154 @@
+package scraper_tests
+
+import (
+	"backend/data"
+	"backend/database"
+	"backend/scraper"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeSource counts its fetches, each of which waits for release if set
+type fakeSource struct {
+	name    string
+	fetches int32
+	release chan struct{}
+}
+
+func (s *fakeSource) Name() string { return s.name }
+
+func (s *fakeSource) Fetch(ctx context.Context) ([]interface{}, error) {
+	atomic.AddInt32(&s.fetches, 1)
+	if s.release != nil {
+		<-s.release
+	}
+	return nil, nil
+}
+
+func (s *fakeSource) Normalize(listing interface{}) (scraper.RawEvent, error) {
+	return scraper.RawEvent{}, nil
+}
+
+// registerFake registers a fake source once, resetting it on later calls
+func registerFake(name string) *fakeSource {
+	if source, ok := scraper.Lookup(name); ok {
+		fake := source.(*fakeSource)
+		atomic.StoreInt32(&fake.fetches, 0)
+		return fake
+	}
+	fake := &fakeSource{name: name}
+	scraper.Register(fake)
+	return fake
+}
+
+func setupScraperDB(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	// Every connection to :memory: is a database of its own
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, database.Migrate(db))
+	database.DB = db
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := map[string]time.Duration{
+		"90m":          90 * time.Minute,
+		"@hourly":      time.Hour,
+		"@daily":       24 * time.Hour,
+		"@weekly":      7 * 24 * time.Hour,
+		"@every 2h30m": 150 * time.Minute,
+	}
+	for value, want := range tests {
+		interval, err := scraper.ParseInterval(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, want, interval, value)
+	}
+
+	for _, value := range []string{"", "often", "-1h", "0s", "@monthly"} {
+		_, err := scraper.ParseInterval(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestScheduleFor(t *testing.T) {
+	schedule := scraper.ScheduleFor("test_source")
+	assert.Equal(t, scraper.DefaultInterval, schedule.Interval)
+	assert.Equal(t, scraper.DefaultInterval/10, schedule.Jitter)
+
+	t.Setenv("SCRAPER_INTERVAL", "@daily")
+	t.Setenv("SCRAPER_INTERVAL_TEST_SOURCE", "2h")
+	t.Setenv("SCRAPER_JITTER", "bad")
+	assert.Equal(t, scraper.Schedule{Interval: 2 * time.Hour, Jitter: 12 * time.Minute}, scraper.ScheduleFor("test_source"))
+	assert.Equal(t, scraper.Schedule{Interval: 24 * time.Hour, Jitter: 144 * time.Minute}, scraper.ScheduleFor("other_source"))
+
+	t.Setenv("SCRAPER_JITTER_TEST_SOURCE", "0s")
+	assert.Equal(t, time.Duration(0), scraper.ScheduleFor("test_source").Jitter)
+
+	// The jitter only ever delays a run
+	schedule = scraper.Schedule{Interval: time.Hour, Jitter: time.Minute}
+	from := time.Now()
+	for i := 0; i < 20; i++ {
+		next := schedule.Next(from)
+		assert.False(t, next.Before(from.Add(time.Hour)))
+		assert.True(t, next.Before(from.Add(time.Hour+time.Minute)))
+	}
+}
+
+func TestSchedulerRun(t *testing.T) {
+	setupScraperDB(t)
+	t.Setenv("SCRAPER_INTERVAL", "1h")
+	scheduler := scraper.NewScheduler()
+	source := &fakeSource{name: "run_source", release: make(chan struct{})}
+
+	done := make(chan error)
+	go func() {
+		_, err := scheduler.Run(context.Background(), source)
+		done <- err
+	}()
+	assert.Eventually(t, func() bool { return scheduler.Running(source.name) }, time.Second, time.Millisecond)
+
+	// Runs of the same source never overlap
+	_, err := scheduler.Run(context.Background(), source)
+	assert.ErrorIs(t, err, scraper.ErrRunInProgress)
+
+	close(source.release)
+	assert.NoError(t, <-done)
+	assert.False(t, scheduler.Running(source.name))
+	assert.EqualValues(t, 1, source.fetches)
+
+	state, err := scraper.State(source.name)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", state.LastStatus)
+	assert.WithinDuration(t, time.Now(), state.LastEndAt, time.Second)
+	assert.WithinDuration(t, state.LastEndAt.Add(time.Hour), state.NextRunAt, 6*time.Minute+time.Second)
+}
+
+func TestSchedulerStart(t *testing.T) {
+	setupScraperDB(t)
+	due := registerFake("due_source")
+	recent := registerFake("recent_source")
+	t.Setenv("SCRAPER_SOURCES", "due_source,recent_source")
+
+	// A source that ran recently keeps its stored schedule across restarts
+	database.DB.Create(&data.ScrapeState{Source: recent.name, LastRunAt: time.Now(), NextRunAt: time.Now().Add(time.Hour)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	scheduler := scraper.NewScheduler()
+	scheduler.StartDelay = 10 * time.Millisecond
+	scheduler.Start(ctx)
+
+	assert.Eventually(t, func() bool {
+		state, _ := scraper.State(due.name)
+		return state.LastStatus == "ok"
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&due.fetches))
+	assert.EqualValues(t, 0, atomic.LoadInt32(&recent.fetches))
+}
@@ -0,0 +1,308 @@
+package scraper_tests
+
+import (
+	"backend/data"
+	"backend/database"
+	"backend/scraper"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fixtureServer replays the responses recorded under testdata, with the
+// server's own URL in place of {{server}}. Pages that were not recorded are
+// missing. Its robots.txt only lets the tracker in, requests that do not
+// identify as the tracker fail the test, and the first request for the Art
+// Walk's page fails with a 503, to be retried.
+func fixtureServer(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	serve := func(w http.ResponseWriter, name string) {
+		body, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			http.NotFound(w, nil)
+			return
+		}
+		if strings.HasSuffix(name, ".json") {
+			w.Header().Set("Content-Type", "application/json")
+		} else {
+			w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		}
+		w.Write([]byte(strings.ReplaceAll(string(body), "{{server}}", server.URL)))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		serve(w, "robots.txt")
+	})
+	mux.HandleFunc("/api/publisher/458/home_page_events", func(w http.ResponseWriter, r *http.Request) {
+		serve(w, "evvnt/home_page_events_"+r.URL.Query().Get("page")+".json")
+	})
+	mux.HandleFunc("/wp-json/wp/v2/tribe_events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-WP-Total", "4")
+		w.Header().Set("X-WP-TotalPages", "2")
+		serve(w, "visit_gainesville/tribe_events_"+r.URL.Query().Get("page")+".json")
+	})
+	mux.HandleFunc("/wp-json/wp/v2/tribe_organizer", func(w http.ResponseWriter, r *http.Request) {
+		serve(w, "visit_gainesville/tribe_organizer_"+r.URL.Query().Get("page")+".json")
+	})
+	var unavailable sync.Once
+	mux.HandleFunc("/event/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/event/art-walk/" {
+			failed := false
+			unavailable.Do(func() { failed = true })
+			if failed {
+				w.Header().Set("Retry-After", "0")
+				http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+				return
+			}
+		}
+		serve(w, "visit_gainesville/"+strings.Trim(strings.TrimPrefix(r.URL.Path, "/event/"), "/")+".html")
+	})
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if agent := r.Header.Get("User-Agent"); agent != scraper.UserAgent {
+			t.Errorf("request for %s with user agent %q", r.URL, agent)
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// venues are the coordinates the offline geocoder knows
+var venues = map[string][2]float64{
+	"Bo Diddley Plaza":     {29.6516, -82.3248},
+	"Downtown Gainesville": {29.6520, -82.3230},
+}
+
+func offlineGeocoder(t *testing.T) {
+	geocoder := scraper.Geocoder
+	scraper.Geocoder = func(location string) (float64, float64, error) {
+		if venue, ok := venues[location]; ok {
+			return venue[0], venue[1], nil
+		}
+		return 0, 0, fmt.Errorf("no geocoding results found for location: %s", location)
+	}
+	t.Cleanup(func() { scraper.Geocoder = geocoder })
+}
+
+// fastCrawl lifts the crawling delays for the test
+func fastCrawl(t *testing.T) {
+	delay, backoff := scraper.Delay, scraper.RetryBackoff
+	scraper.Delay, scraper.RetryBackoff = 0, 0
+	t.Cleanup(func() { scraper.Delay, scraper.RetryBackoff = delay, backoff })
+}
+
+// storedEvent is what a test expects of an event in the database
+type storedEvent struct {
+	Name            string
+	Date            string
+	Location        string
+	Description     string
+	DescriptionHTML string
+	Category        string // Canonical category slug
+	Tags            []string
+	Organizer       string
+	ImageURL        string
+	Latitude        float64
+	CostText        string
+	Cost, MaxCost   float64
+	Free            bool
+}
+
+func TestSources(t *testing.T) {
+	fastCrawl(t)
+	server := fixtureServer(t)
+	tests := []struct {
+		name       string
+		source     scraper.Source
+		pages      int
+		errors     []string // Listings reported as errors
+		events     []storedEvent
+		organizers []string
+		tags       []string
+	}{
+		{
+			name: "gainesville_sun",
+			source: &scraper.GainesvilleSun{
+				EventsURL: server.URL + "/api/publisher/458/home_page_events?hitsPerPage=30&page=%d&publisher_id=458",
+				MaxPages:  5,
+			},
+			pages: 3,
+			events: []storedEvent{
+				{
+					Name:     "Buchholz High School Drama Players Presents Hadestown: Teen Edition",
+					Date:     "2030-04-24",
+					Location: "5510 Northwest 27th Avenue Gainesville United States 32606",
+					// Plain text, with its whitespace collapsed
+					Description: "The Buchholz High School Drama Players present Hadestown: Teen Edition, a folk opera.",
+					Category:    "theatre",
+					Tags:        []string{"buchholz", "hadestown", "musical"},
+					Organizer:   "Buchholz High School",
+					// Images as a single object
+					ImageURL: "https://cdn.prod.discovery.evvnt.com/uploads/event_image/2744117/event_image/HadestownTeen_ARTWORK_SAMPLE.jpg",
+					Latitude: 29.6796,
+				},
+				{
+					Name:        "Parker McCollum: What Kinda Man Tour 2025",
+					Date:        "2030-04-26",
+					Location:    "250 Gale Lemerand Dr Gainesville United States ",
+					Description: "Parker McCollum brings the What Kinda Man Tour to the O'Connell Center.",
+					Category:    "music",
+					// Tags are stored as first spelled, in evvnt's case without spaces
+					Tags:      []string{"concert", "livemusic"},
+					Organizer: "Stephen C. O'Connell Center",
+					// Images as an array, of which the first is used
+					ImageURL: "https://cdn.prod.discovery.evvnt.com/uploads/event_image/2779133/event_image/unnamed.jpg",
+					Latitude: 29.6493,
+					// Read from the ticket types
+					CostText: "General Admission $45.50 / VIP $150",
+					Cost:     45.5,
+					MaxCost:  150,
+				},
+				{
+					Name:        "Friends of the Library Book Sale",
+					Date:        "2030-05-03",
+					Location:    "430-B NE 2nd Ave Gainesville United States 32601",
+					Description: "Thousands of books, records and DVDs.",
+					Category:    "community",
+					Tags:        []string{"books", "friendsofthelibrary"},
+					Organizer:   "Friends of the Library",
+					Latitude:    29.6540,
+					CostText:    "Admission $0",
+					Free:        true,
+				},
+			},
+			organizers: []string{"Buchholz High School", "Friends of the Library", "Stephen C. O'Connell Center"},
+			tags:       []string{"books", "buchholz", "concert", "friendsofthelibrary", "hadestown", "livemusic", "musical"},
+		},
+		{
+			name: "visit_gainesville",
+			source: &scraper.VisitGainesville{
+				EventsURL: server.URL + "/wp-json/wp/v2/tribe_events?order=asc&page=%d&per_page=12&orderby=date",
+				OrganizerURLs: []string{
+					server.URL + "/wp-json/wp/v2/tribe_organizer?order=asc&page=1&per_page=100&orderby=date",
+					server.URL + "/wp-json/wp/v2/tribe_organizer?order=asc&page=2&per_page=100&orderby=date",
+				},
+				MaxPages: 5,
+			},
+			// Both organizer pages, both event pages and the event pages found
+			pages:  7,
+			errors: []string{server.URL + "/event/gallery-opening/"},
+			events: []storedEvent{
+				{
+					Name:            "Downtown Farmers Market",
+					Date:            "2030-05-08 16:00:00 - 2030-05-08 19:00:00",
+					Location:        "Bo Diddley Plaza",
+					Description:     "Fresh produce, baked goods and crafts every Wednesday.",
+					DescriptionHTML: "Fresh produce, baked goods and crafts every Wednesday.",
+					Category:        "food-drink",
+					Tags:            []string{"family", "farmers-market"},
+					Organizer:       "Gainesville Farmers Market",
+					ImageURL:        "https://www.visitgainesville.com/wp-content/uploads/2025/01/market.jpg",
+					Latitude:        29.6516,
+					CostText:        "Free",
+					Free:            true,
+				},
+				{
+					// Entities decoded, tags, shortcodes and scripts removed
+					Name:            "Free Fridays – Concert Series",
+					Date:            "2030-05-10 20:00:00 - 2030-05-10 22:00:00",
+					Location:        "Bo Diddley Plaza",
+					Description:     "Live music on the plaza.\n\nBring a chair",
+					DescriptionHTML: "<p>Live music on the <strong>plaza</strong>.</p><p>Bring a chair</p>",
+					Category:        "music",
+					Tags:            []string{"live-music"},
+					Organizer:       "Bo Diddley Plaza",
+					Latitude:        29.6516,
+				},
+				{
+					Name:            "Art Walk",
+					Date:            "2030-05-30 19:00:00 - 2030-05-30 22:00:00",
+					Location:        "Downtown Gainesville",
+					Description:     "Galleries stay open late.",
+					DescriptionHTML: "Galleries stay open late.",
+					Category:        "arts",
+					Tags:            []string{"arts"},
+					Latitude:        29.6520,
+					CostText:        "$10 – $25",
+					Cost:            10,
+					MaxCost:         25,
+				},
+			},
+			organizers: []string{"Bo Diddley Plaza", "Gainesville Farmers Market"},
+			tags:       []string{"arts", "family", "farmers-market", "live-music"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setupScraperDB(t)
+			offlineGeocoder(t)
+
+			result, err := scraper.Run(context.Background(), test.source)
+			assert.NoError(t, err)
+			assert.Equal(t, test.pages, result.Pages)
+			assert.Equal(t, len(test.events), result.Inserted)
+			var listings []string
+			for _, runErr := range result.Errors {
+				listings = append(listings, runErr.Listing)
+			}
+			assert.Equal(t, test.errors, listings)
+
+			var events []data.Event
+			database.DB.Preload("Organizer").Preload("TagList").Preload("CategoryRef").Order("id").Find(&events)
+			var stored []storedEvent
+			for _, event := range events {
+				assert.Equal(t, test.source.Name(), event.Source, event.Name)
+				assert.NotEmpty(t, event.ExternalID, event.Name)
+				assert.NotNil(t, event.StartsAt, event.Name)
+				got := storedEvent{
+					Name:            event.Name,
+					Date:            event.Date,
+					Location:        event.Location,
+					Description:     event.Description,
+					DescriptionHTML: event.DescriptionHTML,
+					Organizer:       event.Organizer.Name,
+					ImageURL:        event.ImageURL,
+					Latitude:        event.Latitude,
+					CostText:        event.CostText,
+					Cost:            event.Cost,
+					MaxCost:         event.MaxCost,
+					Free:            event.Free,
+				}
+				if event.CategoryRef != nil {
+					got.Category = event.CategoryRef.Slug
+				}
+				for _, tag := range event.TagList {
+					got.Tags = append(got.Tags, tag.Slug)
+				}
+				sort.Strings(got.Tags)
+				stored = append(stored, got)
+			}
+			assert.Equal(t, test.events, stored)
+
+			var organizers, tags []string
+			database.DB.Model(&data.Organizer{}).Order("name").Pluck("name", &organizers)
+			database.DB.Model(&data.Tag{}).
+				Where("id IN (?)", database.DB.Table("event_tags").Select("tag_id")).
+				Order("slug").Pluck("slug", &tags)
+			assert.Equal(t, test.organizers, organizers)
+			assert.Equal(t, test.tags, tags)
+
+			// Scraping the same responses again changes nothing
+			result, err = scraper.Run(context.Background(), test.source)
+			assert.NoError(t, err)
+			assert.Equal(t, len(test.events), result.Unchanged)
+			assert.Zero(t, result.Inserted+result.Updated+result.Duplicates+result.Cancelled)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package scraper_tests
+
+import (
+	"backend/data"
+	"backend/database"
+	"backend/scraper"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// staticSource returns its events as they are, marking the fetch incomplete if
+// asked to
+type staticSource struct {
+	events     []scraper.RawEvent
+	incomplete bool
+}
+
+func (s *staticSource) Name() string { return "static_source" }
+
+func (s *staticSource) Fetch(ctx context.Context) ([]interface{}, error) {
+	if s.incomplete {
+		scraper.MarkIncomplete(ctx)
+	}
+	var listings []interface{}
+	for _, event := range s.events {
+		listings = append(listings, event)
+	}
+	return listings, nil
+}
+
+func (s *staticSource) Normalize(listing interface{}) (scraper.RawEvent, error) {
+	return listing.(scraper.RawEvent), nil
+}
+
+// rawEvent is a scraped event with coordinates, so storing it needs no geocoding
+func rawEvent(id, name, date string) scraper.RawEvent {
+	return scraper.RawEvent{
+		ExternalID:  id,
+		SourceURL:   "https://static.example.com/" + id,
+		Name:        name,
+		Date:        date,
+		Location:    "1 Main St, Gainesville, FL",
+		Description: "Original description",
+		Category:    "Music",
+		Tags:        "Live Music",
+		Latitude:    29.65,
+		Longitude:   -82.32,
+	}
+}
+
+func findEvent(t *testing.T, name string) data.Event {
+	var event data.Event
+	assert.NoError(t, database.DB.Preload("TagList").Where("name = ?", name).First(&event).Error)
+	return event
+}
+
+func TestUpsertEvent(t *testing.T) {
+	setupScraperDB(t)
+	raw := rawEvent("101", "Jazz Night", "2030-05-01 19:00:00 - 2030-05-01 22:00:00")
+
+	outcome, _, err := scraper.UpsertEvent("static_source", raw)
+	assert.NoError(t, err)
+	assert.Equal(t, scraper.Inserted, outcome)
+	event := findEvent(t, "Jazz Night")
+	assert.Equal(t, "static_source", event.Source)
+	assert.Equal(t, "101", event.ExternalID)
+	assert.Equal(t, "https://static.example.com/101", event.SourceURL)
+	assert.NotNil(t, event.FirstSeenAt)
+
+	// Seeing an event again only records when it was seen
+	time.Sleep(time.Millisecond)
+	outcome, changed, err := scraper.UpsertEvent("static_source", raw)
+	assert.NoError(t, err)
+	assert.Equal(t, scraper.Unchanged, outcome)
+	assert.Empty(t, changed)
+	seenAgain := findEvent(t, "Jazz Night")
+	assert.True(t, event.FirstSeenAt.Equal(*seenAgain.FirstSeenAt))
+	assert.True(t, seenAgain.LastSeenAt.After(*event.LastSeenAt))
+
+	// Only the fields that changed are reported, and derived ones follow
+	raw.Description = "New description"
+	raw.TicketsURL = "https://tickets.example.com/101"
+	raw.Date = "2030-05-02 19:00:00 - 2030-05-02 22:00:00"
+	raw.Tags = "Jazz"
+	outcome, changed, err = scraper.UpsertEvent("static_source", raw)
+	assert.NoError(t, err)
+	assert.Equal(t, scraper.Updated, outcome)
+	assert.Equal(t, []string{"date", "description", "tags", "tickets_url"}, changed)
+
+	event = findEvent(t, "Jazz Night")
+	assert.Equal(t, "New description", event.Description)
+	assert.Equal(t, "https://tickets.example.com/101", event.TicketsURL)
+	assert.Equal(t, 2, event.StartsAt.In(time.UTC).Day())
+	if assert.Len(t, event.TagList, 1) {
+		assert.Equal(t, "jazz", event.TagList[0].Slug)
+	}
+
+	// Renamed at the source, still the same event
+	raw.Name = "Jazz Night at the Depot"
+	outcome, changed, _ = scraper.UpsertEvent("static_source", raw)
+	assert.Equal(t, scraper.Updated, outcome)
+	assert.Equal(t, []string{"name"}, changed)
+	var count int64
+	database.DB.Model(&data.Event{}).Count(&count)
+	assert.EqualValues(t, 1, count)
+}
+
+func TestUpsertEventAdoptsLegacyEvents(t *testing.T) {
+	setupScraperDB(t)
+	raw := rawEvent("202", "Farmers Market", "2030-06-01 08:00:00 - 2030-06-01 12:00:00")
+	legacy := data.Event{Name: raw.Name, Date: raw.Date, Location: raw.Location, Description: raw.Description, Category: raw.Category, Tags: raw.Tags, Latitude: raw.Latitude, Longitude: raw.Longitude}
+	database.DB.Create(&legacy)
+
+	outcome, changed, err := scraper.UpsertEvent("static_source", raw)
+	assert.NoError(t, err)
+	assert.Equal(t, scraper.Updated, outcome)
+	assert.Equal(t, []string{"source", "external_id", "source_url"}, changed)
+	assert.Equal(t, legacy.ID, findEvent(t, raw.Name).ID)
+
+	// Events of other sources are left alone
+	other := rawEvent("303", "Book Fair", "2030-07-01 10:00:00 - 2030-07-01 16:00:00")
+	database.DB.Create(&data.Event{Name: other.Name, Date: other.Date, Location: other.Location, Source: "other_source", ExternalID: "x"})
+	outcome, _, _ = scraper.UpsertEvent("static_source", other)
+	assert.Equal(t, scraper.Duplicate, outcome)
+}
+
+func TestRunCancelsMissingEvents(t *testing.T) {
+	setupScraperDB(t)
+	source := &staticSource{events: []scraper.RawEvent{
+		rawEvent("1", "Concert", "2030-05-01 19:00:00 - 2030-05-01 22:00:00"),
+		rawEvent("2", "Play", "2030-05-02 19:00:00 - 2030-05-02 22:00:00"),
+		rawEvent("3", "Past Show", "2020-05-02 19:00:00 - 2020-05-02 22:00:00"),
+	}}
+	result, err := scraper.Run(context.Background(), source)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Inserted)
+
+	// Events gone from an incomplete fetch may just be on a later page
+	source.events = source.events[:1]
+	source.incomplete = true
+	result, _ = scraper.Run(context.Background(), source)
+	assert.Equal(t, 1, result.Unchanged)
+	assert.Equal(t, 0, result.Cancelled)
+	assert.False(t, findEvent(t, "Play").Cancelled)
+
+	// Past events drop off the source without being cancelled
+	source.incomplete = false
+	result, _ = scraper.Run(context.Background(), source)
+	assert.Equal(t, 1, result.Cancelled)
+	play := findEvent(t, "Play")
+	assert.True(t, play.Cancelled)
+	assert.NotNil(t, play.CancelledAt)
+	assert.False(t, findEvent(t, "Past Show").Cancelled)
+
+	// And are restored if they come back
+	source.events = append(source.events, rawEvent("2", "Play", "2030-05-02 19:00:00 - 2030-05-02 22:00:00"))
+	result, _ = scraper.Run(context.Background(), source)
+	assert.Equal(t, 1, result.Updated)
+	assert.False(t, findEvent(t, "Play").Cancelled)
+}
+
+func TestUpsertEventMergesDuplicates(t *testing.T) {
+	setupScraperDB(t)
+	listed := rawEvent("1", "Jazz Night at the Depot", "2030-05-01 19:00:00 - 2030-05-01 22:00:00")
+	listed.Description = "Jazz."
+	scraper.UpsertEvent("other_source", listed)
+
+	// Another source's listing fills in what the first one lacks
+	raw := rawEvent("404", "Jazz Night @ the Depot", "2030-05-01 18:30:00 - 2030-05-01 22:00:00")
+	raw.Location = "874 SE 4th Street"
+	raw.Latitude, raw.Longitude = 29.6505, -82.3205
+	raw.ImageURL = "https://static.example.com/404.jpg"
+	raw.Tags = "Outdoors"
+	outcome, _, err := scraper.UpsertEvent("static_source", raw)
+	assert.NoError(t, err)
+	assert.Equal(t, scraper.Duplicate, outcome)
+
+	event := findEvent(t, listed.Name)
+	assert.Equal(t, "other_source", event.Source)
+	assert.Equal(t, "Original description", event.Description)
+	assert.Equal(t, raw.ImageURL, event.ImageURL)
+	assert.Equal(t, "Live Music, Outdoors", event.Tags)
+	assert.Len(t, event.TagList, 2)
+	duplicate := findEvent(t, raw.Name)
+	assert.False(t, duplicate.Active)
+	if assert.NotNil(t, duplicate.DuplicateOfID) {
+		assert.Equal(t, event.ID, *duplicate.DuplicateOfID)
+	}
+
+	// Both sources keep their listings, and the merged fields survive either
+	// being scraped again
+	outcome, _, _ = scraper.UpsertEvent("static_source", raw)
+	assert.Equal(t, scraper.Unchanged, outcome)
+	outcome, _, _ = scraper.UpsertEvent("other_source", listed)
+	assert.Equal(t, scraper.Unchanged, outcome)
+	raw.TicketsURL = "https://tickets.example.com/404"
+	outcome, changed, _ := scraper.UpsertEvent("static_source", raw)
+	assert.Equal(t, scraper.Updated, outcome)
+	assert.Equal(t, []string{"tickets_url"}, changed)
+	assert.Equal(t, raw.TicketsURL, findEvent(t, listed.Name).TicketsURL)
+
+	// A listing that only may be the same event is inserted and queued
+	maybe := rawEvent("405", "Jazz Night", "2030-05-01 19:00:00 - 2030-05-01 22:00:00")
+	outcome, _, _ = scraper.UpsertEvent("static_source", maybe)
+	assert.Equal(t, scraper.Inserted, outcome)
+	var candidates []data.DuplicateCandidate
+	database.DB.Find(&candidates)
+	if assert.Len(t, candidates, 1) {
+		assert.Equal(t, event.ID, candidates[0].EventID)
+		assert.Equal(t, findEvent(t, maybe.Name).ID, candidates[0].DuplicateID)
+		assert.Equal(t, data.CandidatePending, candidates[0].Status)
+	}
+}
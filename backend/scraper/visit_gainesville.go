@@ -0,0 +1,271 @@
+package scraper
+
+import (
+	"backend/data"
+	"backend/scraper/sanitize"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gocolly/colly"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+func init() {
+	Register(&VisitGainesville{
+		EventsURL: "https://www.visitgainesville.com/wp-json/wp/v2/tribe_events?order=asc&page=%d&per_page=12&orderby=date",
+		OrganizerURLs: []string{
+			"https://www.visitgainesville.com/wp-json/wp/v2/tribe_organizer?order=asc&page=1&per_page=100&orderby=date",
+			"https://www.visitgainesville.com/wp-json/wp/v2/tribe_organizer?order=asc&page=2&per_page=100&orderby=date",
+		},
+		MaxPages: 5,
+	})
+}
+
+// VisitGainesville scrapes visitgainesville.com, a WordPress site whose events
+// API lacks venue addresses; those are read from each event's page.
+type VisitGainesville struct {
+	EventsURL     string   // Events API, with %d for the page number starting at 1
+	OrganizerURLs []string // Pages of the organizer API
+	MaxPages      int
+}
+
+// visitGainesvilleEvent is one entry of the events API
+type visitGainesvilleEvent struct {
+	Title struct {
+		Rendered string `json:"rendered"`
+	} `json:"title"`
+	Content struct {
+		Rendered string `json:"rendered"`
+	} `json:"content"`
+	MetaFields struct {
+		EventStartDate   string `json:"_EventStartDate"`
+		EventEndDate     string `json:"_EventEndDate"`
+		EventCost        string `json:"_EventCost"`
+		EventOrganizerID string `json:"_EventOrganizerID,omitempty"`
+	} `json:"meta_fields"`
+	Link      string   `json:"link"`
+	ThumbURL  string   `json:"thumb_url"`
+	ClassList []string `json:"class_list"`
+}
+
+// visitGainesvilleListing is an API event with what was fetched about it elsewhere
+type visitGainesvilleListing struct {
+	Event     visitGainesvilleEvent
+	Address   string // From the event's page
+	Organizer string // From the organizer API
+}
+
+// Name implements Source
+func (s *VisitGainesville) Name() string {
+	return data.SourceVisitGainesville
+}
+
+// visitGainesvillePage is what was read from an event's page
+type visitGainesvillePage struct {
+	fetched bool
+	address string
+	err     error
+}
+
+// pageKey holds an event's visitGainesvillePage in its request's context
+const pageKey = "scraper.page"
+
+// Fetch implements Source, returning visitGainesvilleListings. The organizers
+// are fetched once, before the events; the events API is read one page at a
+// time while the pages of the events it lists are fetched concurrently.
+func (s *VisitGainesville) Fetch(ctx context.Context) ([]interface{}, error) {
+	collector := newCollector(append([]string{s.EventsURL}, s.OrganizerURLs...)...)
+
+	organizers, err := s.fetchOrganizers(ctx, collector.Clone())
+	if err != nil {
+		// Events are still worth having without their organizers
+		ReportError(ctx, "organizers", err)
+	}
+
+	// The events API, one page at a time
+	apiCollector := collector.Clone()
+	var page []visitGainesvilleEvent
+	var fetchErr, parseErr error
+	var totalPages int // From WordPress's X-WP-TotalPages header; 0 if missing
+	apiCollector.OnResponse(func(r *colly.Response) {
+		parseErr = json.Unmarshal(r.Body, &page)
+		totalPages, _ = strconv.Atoi(r.Headers.Get("X-WP-TotalPages"))
+	})
+	onFailure(ctx, apiCollector, func(r *colly.Response, err error) {
+		fetchErr = err
+	})
+
+	// Each event's page, for the venue address
+	eventPageCollector := collector.Clone()
+	eventPageCollector.OnResponse(func(r *colly.Response) {
+		r.Ctx.GetAny(pageKey).(*visitGainesvillePage).fetched = true
+		PageFetched(ctx)
+	})
+	eventPageCollector.OnHTML("body", func(e *colly.HTMLElement) {
+		e.Request.Ctx.GetAny(pageKey).(*visitGainesvillePage).address = CleanWhiteSpaces(e.DOM.Find(".tribe-events-venue-details .tribe-venue").Text())
+	})
+	onFailure(ctx, eventPageCollector, func(r *colly.Response, err error) {
+		r.Request.Ctx.GetAny(pageKey).(*visitGainesvillePage).err = err
+	})
+
+	var events []visitGainesvilleEvent
+	var eventPages []*visitGainesvillePage
+	// collect waits for the event pages queued so far and pairs them with
+	// their events, skipping those whose page could not be fetched
+	collect := func() []interface{} {
+		eventPageCollector.Wait()
+		var listings []interface{}
+		for i, event := range events {
+			eventURL := CleanWhiteSpaces(event.Link)
+			if !eventPages[i].fetched {
+				ReportError(ctx, eventURL, fmt.Errorf("skipped: %w", eventPages[i].err))
+				continue
+			}
+			listings = append(listings, visitGainesvilleListing{
+				Event:     event,
+				Address:   eventPages[i].address,
+				Organizer: organizers[CleanWhiteSpaces(event.MetaFields.EventOrganizerID)],
+			})
+		}
+		return listings
+	}
+
+	complete := false
+	for n := 1; n <= s.MaxPages && !complete; n++ {
+		if err := ctx.Err(); err != nil {
+			return collect(), err
+		}
+
+		page, fetchErr, parseErr, totalPages = nil, nil, nil, 0
+		apiURL := fmt.Sprintf(s.EventsURL, n)
+		err := apiCollector.Visit(apiURL)
+		apiCollector.Wait()
+		if err == nil {
+			err = fetchErr
+		}
+		if err != nil {
+			return collect(), fmt.Errorf("fetching %s: %w", apiURL, err)
+		}
+		if parseErr != nil {
+			return collect(), fmt.Errorf("parsing %s: %w", apiURL, parseErr)
+		}
+		PageFetched(ctx)
+		if len(page) == 0 {
+			break
+		}
+		// WordPress answers pages past the last with an error, so stop at the last
+		complete = totalPages > 0 && n >= totalPages
+
+		for _, event := range page {
+			if err := ctx.Err(); err != nil {
+				return collect(), err
+			}
+			eventPage := &visitGainesvillePage{}
+			requestCtx := colly.NewContext()
+			requestCtx.Put(pageKey, eventPage)
+			// Fails here if the page may not be visited; fetch errors come later
+			eventPage.err = eventPageCollector.Request("GET", CleanWhiteSpaces(event.Link), nil, requestCtx, nil)
+			events = append(events, event)
+			eventPages = append(eventPages, eventPage)
+		}
+	}
+	if !complete && len(page) > 0 {
+		MarkIncomplete(ctx)
+	}
+	return collect(), nil
+}
+
+// fetchOrganizers maps organizer IDs to names, fetching the organizer API's
+// pages concurrently
+func (s *VisitGainesville) fetchOrganizers(ctx context.Context, collector *colly.Collector) (map[string]string, error) {
+	organizers := make(map[string]string)
+	var mu sync.Mutex
+	var firstErr error
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	collector.OnResponse(func(r *colly.Response) {
+		var page []struct {
+			ID    json.Number `json:"id"`
+			Title struct {
+				Rendered string `json:"rendered"`
+			} `json:"title"`
+		}
+		if err := json.Unmarshal(r.Body, &page); err != nil {
+			fail(fmt.Errorf("parsing %s: %w", r.Request.URL, err))
+			return
+		}
+		PageFetched(ctx)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, organizer := range page {
+			organizers[organizer.ID.String()] = sanitize.Line(organizer.Title.Rendered)
+		}
+	})
+	onFailure(ctx, collector, func(r *colly.Response, err error) {
+		fail(fmt.Errorf("fetching %s: %w", r.Request.URL, err))
+	})
+
+	for _, organizerURL := range s.OrganizerURLs {
+		if err := collector.Visit(organizerURL); err != nil {
+			fail(fmt.Errorf("fetching %s: %w", organizerURL, err))
+		}
+	}
+	collector.Wait()
+	return organizers, firstErr
+}
+
+// Normalize implements Source
+func (s *VisitGainesville) Normalize(listing interface{}) (RawEvent, error) {
+	l, ok := listing.(visitGainesvilleListing)
+	if !ok {
+		return RawEvent{}, fmt.Errorf("unexpected listing type %T", listing)
+	}
+	event := l.Event
+
+	// Categories and tags come as CSS classes on the event. Classes for the
+	// site's own sections are ignored by the taxonomy's mapping rules.
+	var categories, tags []string
+	for _, class := range event.ClassList {
+		if strings.HasPrefix(class, "cat_") {
+			category := strings.ReplaceAll(class[4:], "-", " ")
+			categories = append(categories, cases.Title(language.English).String(category))
+		}
+		if strings.HasPrefix(class, "tag-") {
+			tag := strings.ReplaceAll(class[4:], "-", " ")
+			tags = append(tags, cases.Title(language.English).String(tag))
+		}
+	}
+
+	eventURL := CleanWhiteSpaces(event.Link)
+	return RawEvent{
+		ExternalID:     eventURL,
+		SourceURL:      eventURL,
+		Name:           sanitize.Line(event.Title.Rendered),
+		Date:           fmt.Sprintf("%s - %s", CleanWhiteSpaces(event.MetaFields.EventStartDate), CleanWhiteSpaces(event.MetaFields.EventEndDate)),
+		Location:       l.Address,
+		Description:    sanitize.Text(event.Content.Rendered),
+		Category:       strings.Join(categories, ", "),
+		Tags:           strings.Join(tags, ", "),
+		Cost:           sanitize.Line(event.MetaFields.EventCost),
+		OrganizerName:  l.Organizer,
+		OrganizerEmail: "example@ex.com",
+		OrganizerTel:   "0000000000",
+		GoogleMapsLink: "https://www.google.com/maps?q=" + url.QueryEscape(l.Address),
+		ImageURL:       CleanWhiteSpaces(event.ThumbURL),
+		WebsiteURL:     eventURL,
+
+		DescriptionHTML: sanitize.HTML(event.Content.Rendered),
+	}, nil
+}
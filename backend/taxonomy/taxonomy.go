@@ -0,0 +1,220 @@
+// Package taxonomy maps the categories each source gives its events onto one
+// canonical, two-level category tree.
+//
+// Sources name categories their own way: evvnt sends a category_name such as
+// "Sports / Leisure", Visit Gainesville a set of cat_ classes such as
+// "Museums Galleries", and CreateEvent whatever the organizer typed. Values are
+// slugged and looked up in the category_mappings table, first for the event's
+// source and then for any source. Values mapped to the uncategorized bucket say
+// nothing of the event and are skipped; events none of whose values map land
+// in that bucket.
+package taxonomy
+
+import (
+	"backend/data"
+	"backend/eventtags"
+	"errors"
+	"log"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// category is one node of the built-in taxonomy
+type category struct {
+	slug, name string
+	children   []category
+}
+
+// tree is the built-in taxonomy, created on startup
+var tree = []category{
+	{"music", "Music", []category{
+		{"live-music", "Live Music", nil},
+		{"jazz-blues", "Jazz & Blues", nil},
+		{"rock-pop", "Rock & Pop", nil},
+		{"country-folk", "Country & Folk", nil},
+		{"classical", "Classical", nil},
+	}},
+	{"arts", "Arts & Culture", []category{
+		{"theatre", "Theatre", nil},
+		{"museums-galleries", "Museums & Galleries", nil},
+		{"books-literature", "Books & Literature", nil},
+	}},
+	{"food-drink", "Food & Drink", []category{
+		{"farmers-markets", "Farmers Markets", nil},
+		{"breweries-wineries", "Breweries & Wineries", nil},
+	}},
+	{"sports", "Sports & Recreation", []category{
+		{"running", "Running", nil},
+		{"team-sports", "Team Sports", nil},
+		{"individual-sports", "Individual Sports", nil},
+	}},
+	{"outdoors", "Outdoors & Nature", []category{
+		{"gardening", "Gardening", nil},
+	}},
+	{"festivals", "Festivals", nil},
+	{"family", "Kids & Family", []category{
+		{"camps", "Camps", nil},
+	}},
+	{"education", "Classes & Workshops", []category{
+		{"webinars", "Webinars", nil},
+	}},
+	{"community", "Community", []category{
+		{"support-groups", "Support Groups", nil},
+		{"pets", "Pets", nil},
+	}},
+	{"health", "Health & Wellness", nil},
+	{"shopping", "Markets & Shopping", nil},
+	{data.UncategorizedSlug, "Uncategorized", nil},
+}
+
+// ignored is the target of rules for values that carry no category, such as
+// site sections every listing is filed under
+const ignored = data.UncategorizedSlug
+
+// mappings are the built-in rules from slugged source values to category slugs.
+// A value matching a category slug maps to it without a rule.
+var mappings = map[string]map[string]string{
+	data.SourceAny: {
+		"music":         "music",
+		"blues":         "jazz-blues",
+		"jazz":          "jazz-blues",
+		"rock":          "rock-pop",
+		"pop":           "rock-pop",
+		"metal":         "rock-pop",
+		"punk":          "rock-pop",
+		"country-music": "country-folk",
+		"folk-music":    "country-folk",
+		"festival":      "festivals",
+		"baseball":      "team-sports",
+		"soccer":        "team-sports",
+		"tennis":        "individual-sports",
+		"swimming":      "individual-sports",
+		"golf":          "individual-sports",
+		"beer":          "breweries-wineries",
+		"cooking":       "food-drink",
+		"writing":       "books-literature",
+		"art":           "arts",
+		"kids":          "family",
+		"health":        "health",
+	},
+	data.SourceVisitGainesville: {
+		"brewerywinery":         "breweries-wineries",
+		"museums-galleries":     "museums-galleries",
+		"outdoors-nature":       "outdoors",
+		"workshops-educational": "education",
+		"markets-shopping":      "shopping",
+		"gainesville":           ignored,
+		"downtown-gainesville":  ignored,
+		"whats-good":            ignored,
+	},
+	data.SourceGainesvilleSun: {
+		"sports-leisure":          "sports",
+		"sports-games":            "team-sports",
+		"books-literature":        "books-literature",
+		"education-training":      "education",
+		"kids-family":             "family",
+		"local-community":         "community",
+		"food-drink":              "food-drink",
+		"dogs-cats":               "pets",
+		"gardening-horticultural": "gardening",
+		"summer-camp":             "camps",
+	},
+}
+
+// Seed creates the built-in categories and mapping rules that are missing,
+// leaving existing rows alone
+func Seed(tx *gorm.DB) error {
+	ids := make(map[string]uint)
+	var create func(nodes []category, parentID *uint) error
+	create = func(nodes []category, parentID *uint) error {
+		for _, node := range nodes {
+			row := data.Category{Slug: node.slug, Name: node.name, ParentID: parentID}
+			if err := tx.Where(data.Category{Slug: node.slug}).Attrs(row).FirstOrCreate(&row).Error; err != nil {
+				return err
+			}
+			ids[node.slug] = row.ID
+			if err := create(node.children, &row.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := create(tree, nil); err != nil {
+		return err
+	}
+
+	var rows []data.CategoryMapping
+	for source, rules := range mappings {
+		for value, slug := range rules {
+			rows = append(rows, data.CategoryMapping{Source: source, Value: value, CategoryID: ids[slug]})
+		}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(rows, 100).Error
+}
+
+// Categorize returns the canonical category for a comma-separated category
+// string from source, or "" for any source. The first value with a mapping
+// other than an ignored one wins; the ID of the uncategorized bucket is
+// returned when none has one.
+func Categorize(tx *gorm.DB, source, value string) (uint, error) {
+	var uncategorized data.Category
+	if err := tx.Where("slug = ?", data.UncategorizedSlug).Take(&uncategorized).Error; err != nil {
+		return 0, err
+	}
+
+	for _, name := range eventtags.Split(value) {
+		id, err := lookup(tx, source, eventtags.Slug(name))
+		if err == nil && id != uncategorized.ID {
+			return id, nil
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, err
+		}
+	}
+
+	if value != "" {
+		log.Printf("No category mapping for %q (source %q)", value, source)
+	}
+	return uncategorized.ID, nil
+}
+
+// lookup finds the category a slugged value maps to
+func lookup(tx *gorm.DB, source, value string) (uint, error) {
+	if value == "" {
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	query := tx.Model(&data.CategoryMapping{}).Where("value = ?", value)
+	if source != "" {
+		// The source's own rule wins over a rule for any source
+		query = query.Where("source IN ?", []string{source, data.SourceAny}).
+			Order(clause.OrderBy{Expression: clause.Expr{SQL: "source = ? DESC", Vars: []interface{}{source}}})
+	}
+	var rules []data.CategoryMapping
+	if err := query.Limit(1).Find(&rules).Error; err != nil {
+		return 0, err
+	}
+	if len(rules) > 0 {
+		return rules[0].CategoryID, nil
+	}
+
+	var categories []data.Category
+	if err := tx.Where("slug = ? AND slug != ?", value, data.UncategorizedSlug).Limit(1).Find(&categories).Error; err != nil {
+		return 0, err
+	}
+	if len(categories) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return categories[0].ID, nil
+}
+
+// Apply sets the canonical category of an event from its Category string
+func Apply(tx *gorm.DB, source string, event *data.Event) error {
+	id, err := Categorize(tx, source, event.Category)
+	if err != nil {
+		return err
+	}
+	event.CategoryID = &id
+	return nil
+}
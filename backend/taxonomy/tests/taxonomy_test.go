@@ -0,0 +1,95 @@
+package taxonomy_tests
+
+import (
+	"backend/data"
+	"backend/database"
+	"backend/taxonomy"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTaxonomyDB(t *testing.T) *gorm.DB {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, database.Migrate(db))
+	return db
+}
+
+func categorySlug(t *testing.T, db *gorm.DB, source, value string) string {
+	id, err := taxonomy.Categorize(db, source, value)
+	assert.NoError(t, err)
+	var category data.Category
+	db.First(&category, id)
+	return category.Slug
+}
+
+func TestCategorize(t *testing.T) {
+	db := setupTaxonomyDB(t)
+
+	tests := []struct {
+		name, source, value, want string
+	}{
+		{"evvnt category", data.SourceGainesvilleSun, "Sports / Leisure", "sports"},
+		{"visit gainesville class", data.SourceVisitGainesville, "Museums Galleries", "museums-galleries"},
+		{"rule for any source", data.SourceGainesvilleSun, "Country Music", "country-folk"},
+		{"category slug without a rule", data.SourceUser, "Live Music", "live-music"},
+		{"category name typed by a user", data.SourceUser, "festivals", "festivals"},
+		{"first mapped value wins", data.SourceVisitGainesville, "High Springs, Museums Galleries, Arts", "museums-galleries"},
+		{"rule of another source", data.SourceVisitGainesville, "Sports / Leisure", data.UncategorizedSlug},
+		{"any source when unknown", "", "Sports / Leisure", "sports"},
+		{"unmapped", data.SourceUser, "Astrology", data.UncategorizedSlug},
+		{"empty", data.SourceUser, "", data.UncategorizedSlug},
+		{"uncategorized is not a target", data.SourceUser, "Uncategorized, Jazz", "jazz-blues"},
+		{"ignored site sections are skipped", data.SourceVisitGainesville, "Gainesville, Downtown Gainesville, Whats Good, Jazz", "jazz-blues"},
+		{"only ignored values", data.SourceVisitGainesville, "Whats Good", data.UncategorizedSlug},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, categorySlug(t, db, tt.source, tt.value))
+		})
+	}
+}
+
+func TestCategorize_SourceRuleWins(t *testing.T) {
+	db := setupTaxonomyDB(t)
+	var festivals data.Category
+	db.Where("slug = ?", "festivals").First(&festivals)
+	db.Create(&data.CategoryMapping{Source: data.SourceGainesvilleSun, Value: "jazz", CategoryID: festivals.ID})
+
+	assert.Equal(t, "festivals", categorySlug(t, db, data.SourceGainesvilleSun, "Jazz"))
+	assert.Equal(t, "jazz-blues", categorySlug(t, db, data.SourceVisitGainesville, "Jazz"))
+}
+
+func TestCategorize_IgnoredValuesAreSkipped(t *testing.T) {
+	db := setupTaxonomyDB(t)
+	var uncategorized data.Category
+	db.Where("slug = ?", data.UncategorizedSlug).First(&uncategorized)
+	db.Create(&data.CategoryMapping{Source: data.SourceGainesvilleSun, Value: "music", CategoryID: uncategorized.ID})
+
+	assert.Equal(t, "jazz-blues", categorySlug(t, db, data.SourceGainesvilleSun, "Music, Jazz"))
+	assert.Equal(t, "music", categorySlug(t, db, data.SourceVisitGainesville, "Music, Jazz"))
+}
+
+func TestSeed_IsIdempotent(t *testing.T) {
+	db := setupTaxonomyDB(t)
+	var before, after int64
+	db.Model(&data.Category{}).Count(&before)
+
+	// Renamed rows are kept as they are
+	db.Model(&data.Category{}).Where("slug = ?", "music").Update("name", "Tunes")
+	assert.NoError(t, taxonomy.Seed(db))
+
+	db.Model(&data.Category{}).Count(&after)
+	assert.Equal(t, before, after)
+	var music data.Category
+	db.Where("slug = ?", "music").First(&music)
+	assert.Equal(t, "Tunes", music.Name)
+
+	var jazz data.Category
+	db.Where("slug = ?", "jazz-blues").First(&jazz)
+	if assert.NotNil(t, jazz.ParentID) {
+		assert.Equal(t, music.ID, *jazz.ParentID)
+	}
+}